@@ -0,0 +1,327 @@
+package cmd
+
+import (
+	"github.com/wakatime/wakatime-cli/cmd/completion"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// nolint:gochecknoglobals
+var (
+	// commonFlags are the config and logging flags of all subcommands.
+	commonFlags = []string{"config", "internal-config", "log-file", "log-to-stdout", "output", "verbose"}
+	// apiFlags are the flags of subcommands calling the wakatime api.
+	apiFlags = []string{
+		"api-url",
+		"hostname",
+		"key",
+		"no-ssl-verify",
+		"plugin",
+		"proxy",
+		"ssl-certs-file",
+		"timeout",
+		"trace-endpoint",
+		"trace-file",
+	}
+	// offlineFlags are the flags of subcommands using the offline queue.
+	offlineFlags = []string{"disable-offline", "offline-queue-file"}
+	// heartbeatFlags are the flags describing a heartbeat.
+	heartbeatFlags = []string{
+		"alternate-language",
+		"alternate-project",
+		"category",
+		"cursorpos",
+		"daemon-address",
+		"deadline",
+		"detect-project-folder",
+		"entity",
+		"entity-type",
+		"exclude",
+		"exclude-unknown-project",
+		"extra-heartbeats",
+		"hide-branch-names",
+		"hide-file-names",
+		"hide-project-folder",
+		"hide-project-names",
+		"include",
+		"include-only-with-project-file",
+		"language",
+		"lineno",
+		"lines-in-file",
+		"local-file",
+		"no-daemon",
+		"project",
+		"project-folder",
+		"sync-offline-activity",
+		"time",
+		"write",
+	}
+)
+
+// subcommand describes a subcommand, which is mapped to the legacy top-level
+// flags. Its flags are copied from the legacy flags of the same name, so
+// the legacy command handling runs unchanged.
+type subcommand struct {
+	Use   string
+	Short string
+	Args  cobra.PositionalArgs
+	// Flags are the names of the legacy flags of the subcommand.
+	Flags [][]string
+	// Renamed are flags with a scoped name, bound to a legacy flag.
+	Renamed []renamedFlag
+	// ValidArgs completes the first positional argument.
+	ValidArgs completeFunc
+	// Map sets the legacy flag selecting the command, from positional
+	// arguments and scoped flags.
+	Map func(v *viper.Viper, args []string)
+}
+
+// renamedFlag is a legacy flag with a scoped name and usage.
+type renamedFlag struct {
+	Legacy string
+	Name   string
+	Usage  string
+}
+
+// addSubcommands adds the subcommands to the root command.
+func addSubcommands(root *cobra.Command, run runFunc) {
+	heartbeatCmd := newSubcommand(subcommand{
+		Use:   "heartbeat",
+		Short: "Sends a heartbeat for an entity, like a file, to the WakaTime API.",
+		Args:  cobra.NoArgs,
+		Flags: [][]string{commonFlags, apiFlags, offlineFlags, heartbeatFlags},
+		Map:   func(*viper.Viper, []string) {},
+	}, run)
+	_ = heartbeatCmd.MarkFlagRequired("entity")
+
+	todayCmd := newSubcommand(subcommand{
+		Use:   "today",
+		Short: "Prints dashboard time for Today.",
+		Args:  cobra.NoArgs,
+		Flags: [][]string{commonFlags, apiFlags},
+		Renamed: []renamedFlag{{
+			Legacy: "today-hide-categories",
+			Name:   "hide-categories",
+			Usage:  "Prints the total time only, without categories.",
+		}},
+		Map: func(v *viper.Viper, _ []string) {
+			v.Set("today", true)
+		},
+	}, run)
+
+	goalCmd := newSubcommand(subcommand{
+		Use:       "goal <id>",
+		Short:     "Prints time for the given goal id Today.",
+		Args:      cobra.ExactArgs(1),
+		Flags:     [][]string{commonFlags, apiFlags},
+		ValidArgs: completeGoals,
+		Map: func(v *viper.Viper, args []string) {
+			v.Set("today-goal", args[0])
+		},
+	}, run)
+
+	configCmd := &cobra.Command{
+		Use:   "config",
+		Short: "Reads and writes config keys.",
+	}
+
+	sectionFlag := renamedFlag{
+		Legacy: "config-section",
+		Name:   "section",
+		Usage:  "Optional config section of the key. Defaults to [settings].",
+	}
+
+	configCmd.AddCommand(
+		newSubcommand(subcommand{
+			Use:       "get <key>",
+			Short:     "Prints value for the given config key.",
+			Args:      cobra.ExactArgs(1),
+			Flags:     [][]string{commonFlags},
+			Renamed:   []renamedFlag{sectionFlag},
+			ValidArgs: completion.ConfigKeys,
+			Map: func(v *viper.Viper, args []string) {
+				v.Set("config-read", args[0])
+			},
+		}, run),
+		newSubcommand(subcommand{
+			Use:       "set <key> <value>",
+			Short:     "Writes value to the given config key.",
+			Args:      cobra.ExactArgs(2),
+			Flags:     [][]string{commonFlags},
+			Renamed:   []renamedFlag{sectionFlag},
+			ValidArgs: completion.ConfigKeys,
+			Map: func(v *viper.Viper, args []string) {
+				v.Set("config-write", map[string]string{args[0]: args[1]})
+			},
+		}, run),
+	)
+
+	offlineCmd := &cobra.Command{
+		Use:   "offline",
+		Short: "Inspects and syncs the offline queue.",
+	}
+
+	offlineCmd.AddCommand(
+		newSubcommand(subcommand{
+			Use:   "count",
+			Short: "Prints the number of heartbeats in the offline queue.",
+			Args:  cobra.NoArgs,
+			Flags: [][]string{commonFlags, {"offline-queue-file"}},
+			Map: func(v *viper.Viper, _ []string) {
+				v.Set("offline-count", true)
+			},
+		}, run),
+		newSubcommand(subcommand{
+			Use:   "list",
+			Short: "Prints the heartbeats in the offline queue as json.",
+			Args:  cobra.NoArgs,
+			Flags: [][]string{commonFlags, {"offline-queue-file"}},
+			Map: func(v *viper.Viper, _ []string) {
+				v.Set("offline-list", true)
+			},
+		}, run),
+		newSubcommand(subcommand{
+			Use:   "sync",
+			Short: "Sends heartbeats from the offline queue to the WakaTime API.",
+			Args:  cobra.NoArgs,
+			Flags: [][]string{commonFlags, apiFlags, {"deadline", "offline-queue-file"}},
+			Renamed: []renamedFlag{{
+				Legacy: "sync-offline-activity",
+				Name:   "max",
+				Usage:  "Maximum number of heartbeats to send. Can be \"none\" or a positive integer. Defaults to 1000.",
+			}},
+			Map: func(v *viper.Viper, _ []string) {
+				// selects the command, even if --max was not passed
+				v.Set("sync-offline-activity", v.GetString("sync-offline-activity"))
+			},
+		}, run),
+	)
+
+	projectmapCmd := &cobra.Command{
+		Use:   "projectmap",
+		Short: "Tests the [projectmap] config section.",
+	}
+
+	projectmapCmd.AddCommand(
+		newSubcommand(subcommand{
+			Use:   "test <path>",
+			Short: "Prints which [projectmap] rule matches the given path and the resulting project name.",
+			Args:  cobra.ExactArgs(1),
+			Flags: [][]string{commonFlags},
+			Map: func(v *viper.Viper, args []string) {
+				v.Set("projectmap-test", args[0])
+			},
+		}, run),
+	)
+
+	logCmd := newSubcommand(subcommand{
+		Use:   "log",
+		Short: "Logs time spent away from the editor, like meetings, for a project and category.",
+		Args:  cobra.NoArgs,
+		Flags: [][]string{
+			commonFlags,
+			apiFlags,
+			offlineFlags,
+			{"category", "description", "duration", "end", "project", "start"},
+		},
+		Map: func(v *viper.Viper, _ []string) {
+			v.Set("log-time", true)
+		},
+	}, run)
+	_ = logCmd.MarkFlagRequired("start")
+
+	versionCmd := newSubcommand(subcommand{
+		Use:   "version",
+		Short: "Prints the wakatime-cli version. Prints build details with --verbose.",
+		Args:  cobra.NoArgs,
+		Flags: [][]string{commonFlags},
+		Map: func(v *viper.Viper, _ []string) {
+			v.Set("version", true)
+		},
+	}, run)
+
+	root.AddCommand(heartbeatCmd, todayCmd, goalCmd, configCmd, offlineCmd, projectmapCmd, logCmd,
+		versionCmd, newCompletionCmd())
+}
+
+// newSubcommand creates a command from a subcommand description. The command
+// has its own viper instance, binding the scoped flags to the legacy keys.
+func newSubcommand(sub subcommand, run runFunc) *cobra.Command {
+	v := newViper()
+
+	cmd := &cobra.Command{
+		Use:   sub.Use,
+		Short: sub.Short,
+		Args:  sub.Args,
+		Run: func(cmd *cobra.Command, args []string) {
+			sub.Map(v, args)
+			run(cmd, v)
+		},
+	}
+
+	legacy := legacyFlags()
+	flags := cmd.Flags()
+
+	for _, names := range sub.Flags {
+		for _, name := range names {
+			f := legacy.Lookup(name)
+			if f == nil {
+				log.Fatalf("legacy flag %q of subcommand %q not found", name, sub.Use)
+				continue
+			}
+
+			flags.AddFlag(f)
+		}
+	}
+
+	for _, renamed := range sub.Renamed {
+		legacyFlag := legacy.Lookup(renamed.Legacy)
+		if legacyFlag == nil {
+			log.Fatalf("legacy flag %q of subcommand %q not found", renamed.Legacy, sub.Use)
+			continue
+		}
+
+		f := *legacyFlag
+		f.Name = renamed.Name
+		f.Usage = renamed.Usage
+
+		flags.AddFlag(&f)
+	}
+
+	if err := v.BindPFlags(flags); err != nil {
+		log.Fatalf("failed to bind cobra flags to viper: %s", err)
+	}
+
+	for _, renamed := range sub.Renamed {
+		if err := v.BindPFlag(renamed.Legacy, flags.Lookup(renamed.Name)); err != nil {
+			log.Fatalf("failed to bind cobra flag to viper: %s", err)
+		}
+	}
+
+	registerCompletions(cmd, v)
+
+	if sub.ValidArgs != nil {
+		complete := completeWith(v, sub.ValidArgs)
+
+		cmd.ValidArgsFunction = func(cmd *cobra.Command, args []string, toComplete string) (
+			[]string, cobra.ShellCompDirective) {
+			if len(args) > 0 {
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+
+			return complete(cmd, args, toComplete)
+		}
+	}
+
+	return cmd
+}
+
+// legacyFlags returns a new set of the legacy top-level flags.
+func legacyFlags() *pflag.FlagSet {
+	_, flags := newFlagViper()
+
+	return flags
+}
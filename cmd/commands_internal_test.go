@@ -0,0 +1,250 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubcommands_LegacyFlagsExist(t *testing.T) {
+	logger := logrus.StandardLogger()
+
+	exitFunc := logger.ExitFunc
+	defer func() { logger.ExitFunc = exitFunc }()
+
+	output := logger.Out
+	defer logger.SetOutput(output)
+
+	var buf bytes.Buffer
+
+	logger.SetOutput(&buf)
+
+	logger.ExitFunc = func(int) {
+		t.Errorf("unexpected fatal error: %s", buf.String())
+	}
+
+	addSubcommands(&cobra.Command{}, func(*cobra.Command, *viper.Viper) {})
+}
+
+func TestSubcommands(t *testing.T) {
+	tests := map[string]struct {
+		Legacy     []string
+		Subcommand []string
+		Expected   map[string]interface{}
+	}{
+		"heartbeat": {
+			Legacy:     []string{"--entity", "/tmp/main.go", "--write", "--lineno", "42", "--key", "secret"},
+			Subcommand: []string{"heartbeat", "--entity", "/tmp/main.go", "--write", "--lineno", "42", "--key", "secret"},
+			Expected: map[string]interface{}{
+				"entity": "/tmp/main.go",
+				"key":    "secret",
+				"lineno": 42,
+				"write":  true,
+			},
+		},
+		"today": {
+			Legacy:     []string{"--today", "--today-hide-categories"},
+			Subcommand: []string{"today", "--hide-categories"},
+			Expected: map[string]interface{}{
+				"today":                 true,
+				"today-hide-categories": true,
+			},
+		},
+		"goal": {
+			Legacy:     []string{"--today-goal", "goal-id"},
+			Subcommand: []string{"goal", "goal-id"},
+			Expected: map[string]interface{}{
+				"today-goal": "goal-id",
+			},
+		},
+		"config get": {
+			Legacy:     []string{"--config-read", "api_key", "--config-section", "internal"},
+			Subcommand: []string{"config", "get", "api_key", "--section", "internal"},
+			Expected: map[string]interface{}{
+				"config-read":    "api_key",
+				"config-section": "internal",
+			},
+		},
+		"config set": {
+			Legacy:     []string{"--config-write", "debug=true"},
+			Subcommand: []string{"config", "set", "debug", "true"},
+			Expected: map[string]interface{}{
+				"config-write": map[string]string{"debug": "true"},
+			},
+		},
+		"offline count": {
+			Legacy:     []string{"--offline-count", "--offline-queue-file", "/tmp/offline.bdb"},
+			Subcommand: []string{"offline", "count", "--offline-queue-file", "/tmp/offline.bdb"},
+			Expected: map[string]interface{}{
+				"offline-count":      true,
+				"offline-queue-file": "/tmp/offline.bdb",
+			},
+		},
+		"offline list": {
+			Legacy:     []string{"--offline-list"},
+			Subcommand: []string{"offline", "list"},
+			Expected: map[string]interface{}{
+				"offline-list": true,
+			},
+		},
+		"offline sync": {
+			Legacy:     []string{"--sync-offline-activity", "1000"},
+			Subcommand: []string{"offline", "sync"},
+			Expected: map[string]interface{}{
+				"sync-offline-activity": "1000",
+			},
+		},
+		"offline sync max": {
+			Legacy:     []string{"--sync-offline-activity", "none"},
+			Subcommand: []string{"offline", "sync", "--max", "none"},
+			Expected: map[string]interface{}{
+				"sync-offline-activity": "none",
+			},
+		},
+		"projectmap test": {
+			Legacy:     []string{"--projectmap-test", "/tmp/main.go"},
+			Subcommand: []string{"projectmap", "test", "/tmp/main.go"},
+			Expected: map[string]interface{}{
+				"projectmap-test": "/tmp/main.go",
+			},
+		},
+		"log": {
+			Legacy:     []string{"--log-time", "--project", "wakatime-cli", "--start", "10:00", "--duration", "1h"},
+			Subcommand: []string{"log", "--project", "wakatime-cli", "--start", "10:00", "--duration", "1h"},
+			Expected: map[string]interface{}{
+				"duration": "1h",
+				"log-time": true,
+				"project":  "wakatime-cli",
+				"start":    "10:00",
+			},
+		},
+		"version": {
+			Legacy:     []string{"--version", "--verbose"},
+			Subcommand: []string{"version", "--verbose"},
+			Expected: map[string]interface{}{
+				"verbose": true,
+				"version": true,
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			for _, args := range [][]string{test.Legacy, test.Subcommand} {
+				v := executeRootCMD(t, args)
+
+				for key, expected := range test.Expected {
+					assert.True(t, v.IsSet(key), "%q is not set for args %q", key, args)
+
+					switch value := expected.(type) {
+					case bool:
+						assert.Equal(t, value, v.GetBool(key), args)
+					case int:
+						assert.Equal(t, value, v.GetInt(key), args)
+					case map[string]string:
+						assert.Equal(t, value, v.GetStringMapString(key), args)
+					default:
+						assert.Equal(t, value, v.GetString(key), args)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestSubcommands_ScopedFlags(t *testing.T) {
+	tests := map[string][]string{
+		"heartbeat without entity":  {"heartbeat", "--write"},
+		"today with heartbeat flag": {"today", "--entity", "/tmp/main.go"},
+		"goal without id":           {"goal"},
+		"config set without value":  {"config", "set", "debug"},
+		"offline count with key":    {"offline", "count", "--key", "secret"},
+		"log without start":         {"log", "--project", "wakatime-cli"},
+		"log with entity":           {"log", "--start", "10:00", "--entity", "/tmp/main.go"},
+		"version with today flag":   {"version", "--today-hide-categories"},
+	}
+
+	for name, args := range tests {
+		t.Run(name, func(t *testing.T) {
+			var called bool
+
+			cmd := newRootCMD(func(*cobra.Command, *viper.Viper) {
+				called = true
+			})
+			cmd.SetArgs(args)
+			cmd.SetOut(io.Discard)
+			cmd.SetErr(io.Discard)
+
+			err := cmd.Execute()
+			require.Error(t, err)
+
+			assert.False(t, called)
+		})
+	}
+}
+
+func TestSubcommands_Completion(t *testing.T) {
+	tests := map[string]struct {
+		Args     []string
+		Expected string
+	}{
+		"category": {
+			Args:     []string{"--category", ""},
+			Expected: "code reviewing",
+		},
+		"entity type": {
+			Args:     []string{"heartbeat", "--entity-type", ""},
+			Expected: "app",
+		},
+		"language": {
+			Args:     []string{"heartbeat", "--language", "G"},
+			Expected: "Go",
+		},
+		"config key": {
+			Args:     []string{"config", "get", ""},
+			Expected: "api_key",
+		},
+		"shell": {
+			Args:     []string{"completion", ""},
+			Expected: "powershell",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			cmd := newRootCMD(func(*cobra.Command, *viper.Viper) {})
+			cmd.SetArgs(append([]string{cobra.ShellCompRequestCmd}, test.Args...))
+			cmd.SetOut(&buf)
+
+			err := cmd.Execute()
+			require.NoError(t, err)
+
+			assert.Contains(t, strings.Split(buf.String(), "\n"), test.Expected)
+		})
+	}
+}
+
+func executeRootCMD(t *testing.T, args []string) *viper.Viper {
+	var v *viper.Viper
+
+	cmd := newRootCMD(func(_ *cobra.Command, parsed *viper.Viper) {
+		v = parsed
+	})
+	cmd.SetArgs(args)
+
+	err := cmd.Execute()
+	require.NoError(t, err)
+
+	require.NotNil(t, v, "run not called for args %q", args)
+
+	return v
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"time"
+
+	"github.com/wakatime/wakatime-cli/cmd/completion"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// completeFunc returns the values to complete. The config files are parsed
+// into v beforehand.
+type completeFunc func(v *viper.Viper) ([]string, error)
+
+// flagCompletions returns the completion of flag values by flag name.
+func flagCompletions() map[string]completeFunc {
+	return map[string]completeFunc{
+		"alternate-language": completion.Languages,
+		"category":           completion.Categories,
+		"config-read":        completion.ConfigKeys,
+		"entity-type":        completion.EntityTypes,
+		"language":           completion.Languages,
+		"today-goal":         completeGoals,
+	}
+}
+
+// newCompletionCmd creates the completion subcommand, printing the completion
+// script of a shell.
+func newCompletionCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "completion <shell>",
+		Short: "Prints the completion script for bash, fish, powershell or zsh.",
+		Long: `Prints the completion script for bash, fish, powershell or zsh. For example, to load completion in bash:
+
+  source <(wakatime-cli completion bash)`,
+		Args:                  cobra.ExactValidArgs(1),
+		ValidArgs:             completion.Shells,
+		DisableFlagsInUseLine: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return completion.Script(cmd.Root(), args[0], cmd.OutOrStdout())
+		},
+	}
+}
+
+// registerCompletions registers the completion of the values of cmd's flags.
+func registerCompletions(cmd *cobra.Command, v *viper.Viper) {
+	for name, fn := range flagCompletions() {
+		if cmd.Flags().Lookup(name) == nil {
+			continue
+		}
+
+		_ = cmd.RegisterFlagCompletionFunc(name, completeWith(v, fn))
+	}
+}
+
+// completeWith adapts fn to a cobra completion function. Files are never
+// completed.
+func completeWith(v *viper.Viper, fn completeFunc) func(*cobra.Command, []string, string) (
+	[]string, cobra.ShellCompDirective) {
+	return func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+		if err := parseConfigFiles(v); err != nil {
+			cobra.CompErrorln(err.Error())
+
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		values, err := fn(v)
+		if err != nil {
+			cobra.CompErrorln(err.Error())
+
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
+
+		return values, cobra.ShellCompDirectiveNoFileComp
+	}
+}
+
+func completeGoals(v *viper.Viper) ([]string, error) {
+	return completion.Goals(v, time.Now())
+}
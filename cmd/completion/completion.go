@@ -0,0 +1,271 @@
+package completion
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	cmdapi "github.com/wakatime/wakatime-cli/cmd/api"
+	"github.com/wakatime/wakatime-cli/cmd/params"
+	"github.com/wakatime/wakatime-cli/pkg/goal"
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/ini"
+	"github.com/wakatime/wakatime-cli/pkg/vipertools"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	// goalsSection is the internal config section caching goal titles by id.
+	goalsSection = "goals"
+	// goalsCacheTTL is the time cached goals are completed, before fetching
+	// them again.
+	goalsCacheTTL = time.Hour
+	// goalsTimeout limits fetching goals, to keep completion responsive.
+	goalsTimeout = 5 * time.Second
+)
+
+// Shells are the shells completion scripts are available for.
+// nolint:gochecknoglobals
+var Shells = []string{"bash", "fish", "powershell", "zsh"}
+
+// settingsKeys are the documented keys of the [settings] config section.
+// nolint:gochecknoglobals
+var settingsKeys = []string{
+	"api_key",
+	"api_url",
+	"daemon_address",
+	"daemon_disabled",
+	"debug",
+	"diagnostics_dir",
+	"disable_remote_diagnostics",
+	"exclude",
+	"exclude_unknown_project",
+	"hide_branch_names",
+	"hide_file_names",
+	"hide_project_folder",
+	"hide_project_names",
+	"hostname",
+	"include",
+	"include_only_with_project_file",
+	"log_file",
+	"log_format",
+	"log_level",
+	"log_max_backups",
+	"log_max_size",
+	"no_ssl_verify",
+	"offline",
+	"proxy",
+	"repo_config",
+	"ssl_certs_file",
+	"status_bar_coding_activity",
+	"status_bar_enabled",
+	"status_bar_hide_categories",
+	"timeout",
+	"trace_endpoint",
+	"trace_file",
+	"watch_debounce",
+	"watch_dirs",
+}
+
+// Script writes the completion script of shell for the root command to w.
+func Script(root *cobra.Command, shell string, w io.Writer) error {
+	switch shell {
+	case "bash":
+		return root.GenBashCompletionV2(w, true)
+	case "fish":
+		return root.GenFishCompletion(w, true)
+	case "powershell":
+		return root.GenPowerShellCompletionWithDesc(w)
+	case "zsh":
+		return root.GenZshCompletion(w)
+	default:
+		return fmt.Errorf("unsupported shell %q. must be one of %s", shell, strings.Join(Shells, ", "))
+	}
+}
+
+// Categories returns the heartbeat category values.
+func Categories(*viper.Viper) ([]string, error) {
+	var values []string
+
+	for _, category := range heartbeat.Categories() {
+		values = append(values, category.String())
+	}
+
+	return values, nil
+}
+
+// EntityTypes returns the heartbeat entity type values.
+func EntityTypes(*viper.Viper) ([]string, error) {
+	var values []string
+
+	for _, entityType := range heartbeat.EntityTypes() {
+		values = append(values, entityType.String())
+	}
+
+	return values, nil
+}
+
+// Languages returns the language names, sorted and without duplicates.
+func Languages(*viper.Viper) ([]string, error) {
+	var (
+		seen   = make(map[string]bool)
+		values []string
+	)
+
+	for _, language := range heartbeat.Languages() {
+		name := language.String()
+		if seen[name] {
+			continue
+		}
+
+		seen[name] = true
+
+		values = append(values, name)
+	}
+
+	sort.Strings(values)
+
+	return values, nil
+}
+
+// ConfigKeys returns the keys of the config section selected by
+// --config-section. Keys already set in the config file are merged with the
+// documented keys of the [settings] section.
+func ConfigKeys(v *viper.Viper) ([]string, error) {
+	section := strings.TrimSpace(vipertools.GetString(v, "config-section"))
+	if section == "" {
+		section = "settings"
+	}
+
+	keys := make(map[string]bool)
+
+	if section == "settings" {
+		for _, key := range settingsKeys {
+			keys[key] = true
+		}
+	}
+
+	for key := range vipertools.GetStringMapString(v, section) {
+		keys[key] = true
+	}
+
+	values := make([]string, 0, len(keys))
+
+	for key := range keys {
+		values = append(values, key)
+	}
+
+	sort.Strings(values)
+
+	return values, nil
+}
+
+// Goals returns the goal ids of the current user, described by their title.
+// Goals are cached in the internal config file and fetched from the api,
+// once the cache expired. Stale cached goals are returned, if fetching fails.
+func Goals(v *viper.Viper, now time.Time) ([]string, error) {
+	cachedAt, err := time.Parse(ini.DateFormat, vipertools.GetString(v, "internal.goals_cached_at"))
+	if err == nil && now.Sub(cachedAt) < goalsCacheTTL {
+		return cachedGoals(v), nil
+	}
+
+	goals, err := fetchGoals(v)
+	if err != nil {
+		if cached := cachedGoals(v); len(cached) > 0 {
+			return cached, nil
+		}
+
+		return nil, err
+	}
+
+	titles := make(map[string]string, len(goals))
+
+	for _, g := range goals {
+		titles[g.ID] = g.Title
+	}
+
+	if err := cacheGoals(v, titles, now); err != nil {
+		return nil, err
+	}
+
+	return goalValues(titles), nil
+}
+
+func fetchGoals(v *viper.Viper) ([]goal.Goal, error) {
+	paramAPI, err := params.LoadAPIParams(v)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load API parameters: %w", err)
+	}
+
+	if paramAPI.Timeout == 0 || paramAPI.Timeout > goalsTimeout {
+		paramAPI.Timeout = goalsTimeout
+	}
+
+	apiClient, err := cmdapi.NewClient(paramAPI)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize api client: %w", err)
+	}
+
+	goals, err := apiClient.Goals()
+	if err != nil {
+		return nil, fmt.Errorf("failed fetching goals from api: %w", err)
+	}
+
+	return goals, nil
+}
+
+// cacheGoals replaces the cached goals in the internal config file.
+func cacheGoals(v *viper.Viper, titles map[string]string, now time.Time) error {
+	fp, err := ini.InternalFilePath(v)
+	if err != nil {
+		return fmt.Errorf("failed to get internal config file path: %s", err)
+	}
+
+	// internal config file may not exist yet
+	f, err := os.OpenFile(fp, os.O_CREATE|os.O_RDONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create internal config file: %s", err)
+	}
+
+	_ = f.Close()
+
+	w, err := ini.NewIniWriter(v, func(*viper.Viper) (string, error) { return fp, nil })
+	if err != nil {
+		return fmt.Errorf("failed to parse internal config file: %s", err)
+	}
+
+	w.File.DeleteSection(goalsSection)
+
+	if err := w.Write(goalsSection, titles); err != nil {
+		return fmt.Errorf("failed to write goals to internal config file: %s", err)
+	}
+
+	if err := w.Write("internal", map[string]string{"goals_cached_at": now.Format(ini.DateFormat)}); err != nil {
+		return fmt.Errorf("failed to write to internal config file: %s", err)
+	}
+
+	return nil
+}
+
+func cachedGoals(v *viper.Viper) []string {
+	return goalValues(vipertools.GetStringMapString(v, goalsSection))
+}
+
+// goalValues formats goals as completion values, with the title as
+// description.
+func goalValues(titles map[string]string) []string {
+	values := make([]string, 0, len(titles))
+
+	for id, title := range titles {
+		values = append(values, id+"\t"+title)
+	}
+
+	sort.Strings(values)
+
+	return values
+}
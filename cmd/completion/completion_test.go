@@ -0,0 +1,178 @@
+package completion_test
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/cmd/completion"
+	"github.com/wakatime/wakatime-cli/pkg/ini"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestScript(t *testing.T) {
+	root := &cobra.Command{Use: "wakatime-cli"}
+
+	for _, shell := range completion.Shells {
+		t.Run(shell, func(t *testing.T) {
+			var buf bytes.Buffer
+
+			err := completion.Script(root, shell, &buf)
+			require.NoError(t, err)
+
+			assert.Contains(t, buf.String(), "wakatime-cli")
+		})
+	}
+}
+
+func TestScript_UnsupportedShell(t *testing.T) {
+	err := completion.Script(&cobra.Command{Use: "wakatime-cli"}, "tcsh", io.Discard)
+	require.Error(t, err)
+
+	assert.Contains(t, err.Error(), "unsupported shell")
+}
+
+func TestCategories(t *testing.T) {
+	values, err := completion.Categories(viper.New())
+	require.NoError(t, err)
+
+	assert.Contains(t, values, "coding")
+	assert.Contains(t, values, "code reviewing")
+}
+
+func TestLanguages(t *testing.T) {
+	values, err := completion.Languages(viper.New())
+	require.NoError(t, err)
+
+	assert.Contains(t, values, "Go")
+	assert.NotContains(t, values, "Unknown")
+	assert.IsIncreasing(t, values)
+}
+
+func TestConfigKeys(t *testing.T) {
+	v := viper.New()
+	v.Set("config-section", "settings")
+	v.Set("settings.custom_key", "value")
+	v.Set("git.submodules_disabled", "true")
+
+	values, err := completion.ConfigKeys(v)
+	require.NoError(t, err)
+
+	assert.Contains(t, values, "api_key")
+	assert.Contains(t, values, "custom_key")
+	assert.NotContains(t, values, "submodules_disabled")
+}
+
+func TestConfigKeys_Section(t *testing.T) {
+	v := viper.New()
+	v.Set("config-section", "git")
+	v.Set("git.submodules_disabled", "true")
+
+	values, err := completion.ConfigKeys(v)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"submodules_disabled"}, values)
+}
+
+func TestGoals(t *testing.T) {
+	testServerURL, router, tearDown := setupTestServer()
+	defer tearDown()
+
+	var numCalls int
+
+	router.HandleFunc("/users/current/goals", func(w http.ResponseWriter, req *http.Request) {
+		numCalls++
+
+		f, err := os.Open("testdata/api_goals_response.json")
+		require.NoError(t, err)
+
+		defer f.Close()
+
+		_, err = io.Copy(w, f)
+		require.NoError(t, err)
+	})
+
+	internalConfig := filepath.Join(t.TempDir(), ".wakatime-internal.cfg")
+	now := time.Date(2022, 1, 3, 10, 0, 0, 0, time.UTC)
+	expected := []string{
+		"00000000-0000-4000-8000-000000000000\tCode 1 hr per day",
+		"00000000-0000-4000-8000-000000000001\tCode 5 hrs per week in Go",
+	}
+
+	v := newViper(testServerURL, internalConfig)
+
+	values, err := completion.Goals(v, now)
+	require.NoError(t, err)
+
+	assert.Equal(t, expected, values)
+	assert.Equal(t, 1, numCalls)
+	assert.FileExists(t, internalConfig)
+
+	// goals are completed from cache
+	v = newViper(testServerURL, internalConfig)
+
+	err = ini.ReadInConfig(v, internalConfig)
+	require.NoError(t, err)
+
+	values, err = completion.Goals(v, now.Add(30*time.Minute))
+	require.NoError(t, err)
+
+	assert.Equal(t, expected, values)
+	assert.Equal(t, 1, numCalls)
+}
+
+func TestGoals_StaleCache(t *testing.T) {
+	testServerURL, router, tearDown := setupTestServer()
+	defer tearDown()
+
+	var numCalls int
+
+	router.HandleFunc("/users/current/goals", func(w http.ResponseWriter, req *http.Request) {
+		numCalls++
+
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+
+	internalConfig := filepath.Join(t.TempDir(), ".wakatime-internal.cfg")
+	err := os.WriteFile(internalConfig, []byte(
+		"[internal]\ngoals_cached_at = 2022-01-03T08:00:00Z\n\n"+
+			"[goals]\n00000000-0000-4000-8000-000000000000 = Code 1 hr per day\n",
+	), 0600)
+	require.NoError(t, err)
+
+	v := newViper(testServerURL, internalConfig)
+
+	err = ini.ReadInConfig(v, internalConfig)
+	require.NoError(t, err)
+
+	values, err := completion.Goals(v, time.Date(2022, 1, 3, 10, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"00000000-0000-4000-8000-000000000000\tCode 1 hr per day"}, values)
+	assert.Equal(t, 1, numCalls)
+}
+
+func newViper(apiURL, internalConfig string) *viper.Viper {
+	v := viper.New()
+	v.Set("api-url", apiURL)
+	v.Set("internal-config", internalConfig)
+	v.Set("key", "00000000-0000-4000-8000-000000000000")
+
+	return v
+}
+
+func setupTestServer() (string, *http.ServeMux, func()) {
+	router := http.NewServeMux()
+	srv := httptest.NewServer(router)
+
+	return srv.URL, router, func() { srv.Close() }
+}
@@ -0,0 +1,363 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	cmdapi "github.com/wakatime/wakatime-cli/cmd/api"
+	cmdheartbeat "github.com/wakatime/wakatime-cli/cmd/heartbeat"
+	offlinecmd "github.com/wakatime/wakatime-cli/cmd/offline"
+	"github.com/wakatime/wakatime-cli/cmd/offlinesync"
+	"github.com/wakatime/wakatime-cli/cmd/params"
+	"github.com/wakatime/wakatime-cli/pkg/daemon"
+	"github.com/wakatime/wakatime-cli/pkg/exitcode"
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/offline"
+	"github.com/wakatime/wakatime-cli/pkg/vipertools"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+const (
+	// shutdownTimeout is the maximum time to wait for in-flight requests on shutdown.
+	shutdownTimeout = 10 * time.Second
+	// syncInterval is the interval in which the daemon syncs the offline queue.
+	syncInterval = time.Minute
+)
+
+// inheritedKeys are the command line flags of the daemon process, which apply
+// to all forwarded requests, unless overwritten by the request.
+// nolint:gochecknoglobals
+var inheritedKeys = []string{
+	"api-url",
+	"apiurl",
+	"config",
+	"disable-offline",
+	"disableoffline",
+	"hostname",
+	"internal-config",
+	"key",
+	"no-ssl-verify",
+	"offline-queue-file",
+	"proxy",
+	"ssl-certs-file",
+	"sync-offline-activity",
+	"timeout",
+	"trace-endpoint",
+	"trace-file",
+	"verbose",
+}
+
+// Run executes the daemon command.
+func Run(v *viper.Viper, newFlags vipertools.NewFlagsFunc) (int, error) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := Serve(ctx, v, newFlags); err != nil {
+		return exitcode.ErrGeneric, fmt.Errorf("daemon failed: %w", err)
+	}
+
+	log.Debugln("daemon stopped")
+
+	return exitcode.Success, nil
+}
+
+// Serve listens on the daemon address and handles forwarded heartbeats until
+// ctx is done. Queued heartbeats are handled before returning. The daemon owns
+// the offline queue and syncs it periodically.
+func Serve(ctx context.Context, v *viper.Viper, newFlags vipertools.NewFlagsFunc) error {
+	paramDaemon, err := params.LoadDaemonParams(v)
+	if err != nil {
+		return fmt.Errorf("failed to load daemon parameters: %w", err)
+	}
+
+	paramAPI, err := params.LoadAPIParams(v)
+	if err != nil {
+		return fmt.Errorf("failed to load API parameters: %w", err)
+	}
+
+	if _, err := cmdapi.NewClient(paramAPI); err != nil {
+		return fmt.Errorf("failed to initialize api client: %w", err)
+	}
+
+	queueFilepath, err := offline.QueueFilepath()
+	if err != nil {
+		log.Warnf("failed to load offline queue filepath: %s", err)
+	}
+
+	l, err := daemon.Listen(paramDaemon.Address)
+	if err != nil {
+		return err
+	}
+
+	// forwarded heartbeats are still sent on shutdown, until it times out
+	sendCtx, cancelSend := context.WithCancel(context.Background())
+	defer cancelSend()
+
+	h := &handler{
+		ctx:           sendCtx,
+		newFlags:      newFlags,
+		queueFilepath: queueFilepath,
+		v:             v,
+	}
+
+	server := daemon.NewServer(h.handle, daemon.Config{
+		MaxBatch: offline.SendLimit,
+		PID:      os.Getpid(),
+	})
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	go h.syncLoop(ctx)
+
+	go func() {
+		<-ctx.Done()
+
+		log.Debugln("daemon shutting down")
+
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			log.Warnf("failed to shut down daemon gracefully: %s", err)
+		}
+
+		// heartbeats still not sent are queued
+		<-shutdownCtx.Done()
+		cancelSend()
+	}()
+
+	log.Infof("daemon listening on %s", paramDaemon.Address)
+
+	return server.Serve(l)
+}
+
+// handler processes requests forwarded to the daemon. Sending heartbeats and
+// syncing the offline queue never run concurrently.
+type handler struct {
+	ctx           context.Context
+	mu            sync.Mutex
+	newFlags      vipertools.NewFlagsFunc
+	queueFilepath string
+	v             *viper.Viper
+}
+
+// batch contains heartbeats of requests sharing the same processing pipeline.
+type batch struct {
+	heartbeats []heartbeat.Heartbeat
+	params     params.Params
+	v          *viper.Viper
+}
+
+func (h *handler) handle(rr []daemon.Request) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, b := range h.batches(rr) {
+		log.SetRedaction(params.LoadRedactionParams(b.v, b.heartbeats...))
+
+		for len(b.heartbeats) > 0 {
+			n := len(b.heartbeats)
+			if n > offline.SendLimit {
+				n = offline.SendLimit
+			}
+
+			if err := cmdheartbeat.HandleHeartbeats(h.ctx, b.v, b.params, b.heartbeats[:n], h.queueFilepath); err != nil {
+				log.Errorf("failed to send %d heartbeat(s): %s", n, err)
+			}
+
+			b.heartbeats = b.heartbeats[n:]
+		}
+	}
+
+	log.SetRedaction(params.LoadRedactionParams(h.v))
+}
+
+// batches groups the heartbeats of requests by their processing pipeline
+// configuration, keeping the order of first occurrence.
+func (h *handler) batches(rr []daemon.Request) []*batch {
+	var (
+		batches []*batch
+		keys    = make(map[string]*batch)
+	)
+
+	for _, r := range rr {
+		v, err := h.requestViper(r)
+		if err != nil {
+			log.Errorf("failed to load forwarded request: %s", err)
+			continue
+		}
+
+		v, repoConfig := params.LoadRepoConfig(h.ctx, v)
+
+		p, err := params.Load(v)
+		if err != nil {
+			log.Errorf("failed to load command parameters of forwarded request: %s", err)
+
+			if err := offlinecmd.SaveHeartbeats(v, nil, h.queueFilepath); err != nil {
+				log.Errorf("failed to save heartbeats to offline queue: %s", err)
+			}
+
+			continue
+		}
+
+		if r.ExtraHeartbeats != "" {
+			p.Heartbeat.ExtraHeartbeats, err = params.ParseExtraHeartbeats(r.ExtraHeartbeats)
+			if err != nil {
+				log.Errorf("failed to parse extra heartbeats of forwarded request: %s", err)
+			}
+		}
+
+		key := batchKey(r, p, repoConfig)
+
+		b, ok := keys[key]
+		if !ok {
+			b = &batch{
+				params: p,
+				v:      v,
+			}
+
+			keys[key] = b
+			batches = append(batches, b)
+		}
+
+		b.heartbeats = append(b.heartbeats, cmdheartbeat.BuildHeartbeats(p)...)
+	}
+
+	return batches
+}
+
+// requestViper creates a viper instance for a forwarded request. Config
+// values are taken over from the daemon, command line flags are applied as if
+// the request had been passed on the command line.
+func (h *handler) requestViper(r daemon.Request) (*viper.Viper, error) {
+	v, flags := h.newFlags()
+
+	for _, key := range h.v.AllKeys() {
+		if strings.Contains(key, ".") && h.v.IsSet(key) {
+			v.Set(key, h.v.Get(key))
+		}
+	}
+
+	for _, key := range inheritedKeys {
+		if !h.v.IsSet(key) {
+			continue
+		}
+
+		if err := setFlag(flags, key, []string{h.v.GetString(key)}); err != nil {
+			return nil, err
+		}
+	}
+
+	for key, values := range r.Params {
+		if !isForwarded(key) {
+			log.Warnf("ignore unsupported parameter %q of forwarded request", key)
+			continue
+		}
+
+		if err := setFlag(flags, key, values); err != nil {
+			return nil, err
+		}
+	}
+
+	return v, nil
+}
+
+func (h *handler) syncLoop(ctx context.Context) {
+	ticker := time.NewTicker(syncInterval)
+	defer ticker.Stop()
+
+	for {
+		h.sync(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (h *handler) sync(ctx context.Context) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	err := offlinesync.SyncOfflineActivity(ctx, h.v, h.queueFilepath)
+	if err != nil {
+		var errSyncDisabled offlinesync.ErrSyncDisabled
+		if errors.As(err, &errSyncDisabled) {
+			log.Debugln(err.Error())
+			return
+		}
+
+		log.Warnf("failed to sync offline activity: %s", err)
+	}
+}
+
+// setFlag sets a command line flag, marking it as changed. Slice flags
+// receive all values, other flags the first one.
+func setFlag(flags *pflag.FlagSet, name string, values []string) error {
+	flag := flags.Lookup(name)
+	if flag == nil {
+		return fmt.Errorf("unknown flag %q", name)
+	}
+
+	if slice, ok := flag.Value.(pflag.SliceValue); ok {
+		if err := slice.Replace(values); err != nil {
+			return fmt.Errorf("invalid value for flag %q: %s", name, err)
+		}
+
+		flag.Changed = true
+
+		return nil
+	}
+
+	var value string
+	if len(values) > 0 {
+		value = values[0]
+	}
+
+	if err := flags.Set(name, value); err != nil {
+		return fmt.Errorf("invalid value for flag %q: %s", name, err)
+	}
+
+	return nil
+}
+
+// batchKey identifies requests, which can be processed by the same pipeline.
+// Only heartbeat data may differ between those. Requests merging different
+// repo config files are processed separately, as their settings differ.
+func batchKey(r daemon.Request, p params.Params, repoConfig string) string {
+	keys := make([]string, 0, len(r.Params))
+
+	for key := range r.Params {
+		if !isHeartbeatData(key) {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	var parts []string
+
+	for _, key := range keys {
+		data, _ := json.Marshal(r.Params[key])
+		parts = append(parts, key+"="+string(data))
+	}
+
+	obfuscate := heartbeat.ShouldSanitize(p.Heartbeat.Entity, p.Heartbeat.Sanitize.HideProjectNames)
+
+	return fmt.Sprintf("%t;%q;%s", obfuscate, repoConfig, strings.Join(parts, ";"))
+}
@@ -0,0 +1,328 @@
+package daemon_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	cmddaemon "github.com/wakatime/wakatime-cli/cmd/daemon"
+	"github.com/wakatime/wakatime-cli/cmd/params"
+	"github.com/wakatime/wakatime-cli/pkg/daemon"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServe(t *testing.T) {
+	var (
+		entities []string
+		mu       sync.Mutex
+		numCalls int
+	)
+
+	router := http.NewServeMux()
+	router.HandleFunc("/users/current/heartbeats.bulk", func(w http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+
+		var heartbeats []struct {
+			Entity    string `json:"entity"`
+			Project   string `json:"project"`
+			UserAgent string `json:"user_agent"`
+		}
+
+		err = json.Unmarshal(body, &heartbeats)
+		require.NoError(t, err)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		numCalls++
+
+		var responses []string
+
+		for _, h := range heartbeats {
+			assert.Equal(t, "wakatime-cli", h.Project)
+			assert.True(t, strings.HasSuffix(h.UserAgent, "vim/1.0.0 vim-wakatime/1.0.0"))
+
+			entities = append(entities, filepath.Base(h.Entity))
+			responses = append(responses, `[{"data": {}}, 201]`)
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		_, _ = fmt.Fprintf(w, `{"responses": [%s]}`, strings.Join(responses, ","))
+	})
+
+	apiServer := httptest.NewServer(router)
+	defer apiServer.Close()
+
+	tmpDir := t.TempDir()
+	address := filepath.Join(tmpDir, "daemon.sock")
+
+	v, flags := newFlags()
+	require.NoError(t, flags.Parse([]string{
+		"--api-url", apiServer.URL,
+		"--key", "00000000-0000-4000-8000-000000000000",
+		"--offline-queue-file", filepath.Join(tmpDir, "offline.bdb"),
+	}))
+	v.Set("daemon-address", address)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	served := make(chan error)
+
+	go func() {
+		served <- cmddaemon.Serve(ctx, v, newFlags)
+	}()
+
+	client, err := daemon.NewClient(address)
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		_, err := client.Status()
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+
+	for _, entity := range []string{"testdata/main.go", "testdata/main.py"} {
+		v, flags := newFlags()
+		require.NoError(t, flags.Parse([]string{
+			"--entity", entity,
+			"--plugin", "vim/1.0.0 vim-wakatime/1.0.0",
+			"--project", "wakatime-cli",
+			"--write",
+		}))
+
+		err := cmddaemon.Forward(address, v, flags)
+		require.NoError(t, err)
+	}
+
+	cancel()
+
+	require.NoError(t, <-served)
+
+	sort.Strings(entities)
+
+	assert.Equal(t, 1, numCalls)
+	assert.Equal(t, []string{"main.go", "main.py"}, entities)
+}
+
+func TestServe_RepoConfig(t *testing.T) {
+	var (
+		entities = make(map[string]string)
+		mu       sync.Mutex
+		numCalls int
+	)
+
+	router := http.NewServeMux()
+	router.HandleFunc("/users/current/heartbeats.bulk", func(w http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+
+		var heartbeats []struct {
+			Entity  string `json:"entity"`
+			Project string `json:"project"`
+		}
+
+		err = json.Unmarshal(body, &heartbeats)
+		require.NoError(t, err)
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		numCalls++
+
+		var responses []string
+
+		for _, h := range heartbeats {
+			entities[h.Project] = filepath.Base(h.Entity)
+			responses = append(responses, `[{"data": {}}, 201]`)
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		_, _ = fmt.Fprintf(w, `{"responses": [%s]}`, strings.Join(responses, ","))
+	})
+
+	apiServer := httptest.NewServer(router)
+	defer apiServer.Close()
+
+	tmpDir := t.TempDir()
+	address := filepath.Join(tmpDir, "daemon.sock")
+
+	repos := map[string]string{
+		"hidden":  "[settings]\nhide_file_names = true\n",
+		"visible": "[settings]\nhide_file_names = false\n",
+	}
+
+	for name, config := range repos {
+		err := os.MkdirAll(filepath.Join(tmpDir, name), 0700)
+		require.NoError(t, err)
+
+		err = os.WriteFile(filepath.Join(tmpDir, name, ".wakatime-project"), []byte(name+"\n"), 0600)
+		require.NoError(t, err)
+
+		err = os.WriteFile(filepath.Join(tmpDir, name, ".wakatime.cfg"), []byte(config), 0600)
+		require.NoError(t, err)
+
+		err = os.WriteFile(filepath.Join(tmpDir, name, "main.go"), []byte("package main\n"), 0600)
+		require.NoError(t, err)
+	}
+
+	v, flags := newFlags()
+	require.NoError(t, flags.Parse([]string{
+		"--api-url", apiServer.URL,
+		"--key", "00000000-0000-4000-8000-000000000000",
+		"--offline-queue-file", filepath.Join(tmpDir, "offline.bdb"),
+	}))
+	v.Set("daemon-address", address)
+	v.Set("settings.repo_config", true)
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	served := make(chan error)
+
+	go func() {
+		served <- cmddaemon.Serve(ctx, v, newFlags)
+	}()
+
+	client, err := daemon.NewClient(address)
+	require.NoError(t, err)
+
+	require.Eventually(t, func() bool {
+		_, err := client.Status()
+		return err == nil
+	}, time.Second, 10*time.Millisecond)
+
+	for _, name := range []string{"hidden", "visible"} {
+		v, flags := newFlags()
+		require.NoError(t, flags.Parse([]string{
+			"--entity", filepath.Join(tmpDir, name, "main.go"),
+			"--write",
+		}))
+
+		err := cmddaemon.Forward(address, v, flags)
+		require.NoError(t, err)
+	}
+
+	cancel()
+
+	require.NoError(t, <-served)
+
+	// heartbeats of repos with different repo config files are not batched
+	assert.Equal(t, 2, numCalls)
+	assert.Equal(t, map[string]string{
+		"hidden":  "HIDDEN.go",
+		"visible": "main.go",
+	}, entities)
+}
+
+func TestForward_NotRunning(t *testing.T) {
+	v, flags := newFlags()
+	require.NoError(t, flags.Parse([]string{"--entity", "testdata/main.go"}))
+
+	err := cmddaemon.Forward(filepath.Join(t.TempDir(), "daemon.sock"), v, flags)
+	require.Error(t, err)
+
+	var errNotRunning daemon.ErrNotRunning
+	assert.ErrorAs(t, err, &errNotRunning)
+}
+
+func TestForward_NotForwardable(t *testing.T) {
+	v, flags := newFlags()
+	require.NoError(t, flags.Parse([]string{
+		"--entity", "testdata/main.go",
+		"--api-url", "https://example.org/api/v1",
+		"--key", "00000000-0000-4000-8000-000000000000",
+	}))
+
+	err := cmddaemon.Forward(filepath.Join(t.TempDir(), "daemon.sock"), v, flags)
+
+	var errNotForwardable cmddaemon.ErrNotForwardable
+
+	require.ErrorAs(t, err, &errNotForwardable)
+	assert.Equal(t, "api-url", errNotForwardable.Flag)
+}
+
+func TestForward_SendFails(t *testing.T) {
+	address := filepath.Join(t.TempDir(), "daemon.sock")
+
+	l, err := daemon.Listen(address)
+	require.NoError(t, err)
+
+	router := http.NewServeMux()
+	router.HandleFunc("/status", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = io.WriteString(w, `{"pid": 1}`)
+	})
+	router.HandleFunc("/heartbeats", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+
+	server := &http.Server{Handler: router} // nolint:gosec
+	defer server.Close()
+
+	go func() {
+		_ = server.Serve(l)
+	}()
+
+	r, w, err := os.Pipe()
+	require.NoError(t, err)
+
+	defer r.Close()
+
+	origStdin := os.Stdin
+	defer func() { os.Stdin = origStdin }()
+
+	os.Stdin = r
+
+	_, err = w.WriteString("[{\"entity\": \"testdata/main.py\"}]\n")
+	require.NoError(t, err)
+
+	w.Close()
+
+	v, flags := newFlags()
+	require.NoError(t, flags.Parse([]string{"--entity", "testdata/main.go", "--extra-heartbeats"}))
+
+	err = cmddaemon.Forward(address, v, flags)
+	require.Error(t, err)
+
+	// extra heartbeats are kept for sending them locally
+	input, err := params.ReadExtraHeartbeatsInput(v)
+	require.NoError(t, err)
+
+	assert.Equal(t, "[{\"entity\": \"testdata/main.py\"}]\n", input)
+}
+
+// newFlags defines the subset of command line flags used in tests.
+func newFlags() (*viper.Viper, *pflag.FlagSet) {
+	flags := pflag.NewFlagSet("wakatime-cli", pflag.ContinueOnError)
+
+	for _, name := range []string{
+		"api-url", "category", "daemon-address", "entity", "entity-type", "key",
+		"offline-queue-file", "plugin", "project", "sync-offline-activity",
+	} {
+		flags.String(name, "", "")
+	}
+
+	flags.Bool("extra-heartbeats", false, "")
+	flags.Bool("write", false, "")
+	flags.Float64("time", 0, "")
+	flags.StringSlice("exclude", nil, "")
+
+	v := viper.New()
+	if err := v.BindPFlags(flags); err != nil {
+		panic(err)
+	}
+
+	return v, flags
+}
@@ -0,0 +1,216 @@
+package daemon
+
+import (
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/cmd/params"
+	"github.com/wakatime/wakatime-cli/pkg/daemon"
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/remote"
+	"github.com/wakatime/wakatime-cli/pkg/vipertools"
+	"github.com/wakatime/wakatime-cli/pkg/windows"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// heartbeatDataKeys are the forwarded command line flags, which only set
+// fields of the heartbeat itself. Requests differing only in these flags are
+// processed together.
+// nolint:gochecknoglobals
+var heartbeatDataKeys = []string{
+	"alternate-language",
+	"alternate-project",
+	"category",
+	"cursorpos",
+	"entity",
+	"entity-type",
+	"file",
+	"language",
+	"lineno",
+	"local-file",
+	"project",
+	"project-folder",
+	"time",
+	"write",
+}
+
+// forwardedKeys are the command line flags forwarded to the daemon in
+// addition to heartbeatDataKeys. These are the flags read by
+// params.LoadHeartbeatParams plus the plugin and api key.
+// nolint:gochecknoglobals
+var forwardedKeys = []string{
+	"detect-project-folder",
+	"exclude",
+	"exclude-unknown-project",
+	"hide-branch-names",
+	"hide-file-names",
+	"hide-filenames",
+	"hide-project-folder",
+	"hide-project-names",
+	"hidefilenames",
+	"include",
+	"include-only-with-project-file",
+	"key",
+	"lines-in-file",
+	"plugin",
+}
+
+// localKeys are the command line flags, which only configure the forwarding
+// process itself and are not forwarded.
+// nolint:gochecknoglobals
+var localKeys = []string{
+	"daemon-address",
+	"deadline",
+	"extra-heartbeats",
+	"log-file",
+	"log-to-stdout",
+	"logfile",
+	"no-daemon",
+	"output",
+	"sync-offline-activity",
+	"verbose",
+}
+
+// pathKeys are forwarded flags containing local paths, which are made absolute
+// before forwarding, as the daemon runs in a different working directory.
+// nolint:gochecknoglobals
+var pathKeys = []string{"entity", "file", "local-file", "project-folder"}
+
+// ErrNotForwardable is returned, if a command line flag is set, which the
+// daemon cannot apply per request, like --api-url or --proxy.
+type ErrNotForwardable struct {
+	Flag string
+}
+
+// Error method to implement error interface.
+func (e ErrNotForwardable) Error() string {
+	return fmt.Sprintf("flag --%s cannot be forwarded to the daemon", e.Flag)
+}
+
+// Forward forwards the heartbeat passed in via command line flags to the
+// daemon listening on address. Returns ErrNotForwardable or
+// daemon.ErrNotRunning before reading any extra heartbeats from stdin. Extra
+// heartbeats are kept in v, if sending them to the daemon fails.
+func Forward(address string, v *viper.Viper, flags *pflag.FlagSet) error {
+	var notForwardable []string
+
+	flags.Visit(func(flag *pflag.Flag) {
+		if !isForwarded(flag.Name) && !contains(localKeys, flag.Name) {
+			notForwardable = append(notForwardable, flag.Name)
+		}
+	})
+
+	if len(notForwardable) > 0 {
+		return ErrNotForwardable{Flag: notForwardable[0]}
+	}
+
+	c, err := daemon.NewClient(address)
+	if err != nil {
+		return fmt.Errorf("failed to initialize daemon client: %w", err)
+	}
+
+	status, err := c.Status()
+	if err != nil {
+		return err
+	}
+
+	log.Debugf("forward heartbeat to daemon with pid %d", status.PID)
+
+	r, err := newRequest(v, flags)
+	if err != nil {
+		return err
+	}
+
+	return c.Send(r)
+}
+
+func newRequest(v *viper.Viper, flags *pflag.FlagSet) (daemon.Request, error) {
+	r := daemon.Request{
+		Params: make(map[string][]string),
+	}
+
+	flags.Visit(func(flag *pflag.Flag) {
+		if !isForwarded(flag.Name) {
+			return
+		}
+
+		if slice, ok := flag.Value.(pflag.SliceValue); ok {
+			r.Params[flag.Name] = slice.GetSlice()
+			return
+		}
+
+		r.Params[flag.Name] = []string{flag.Value.String()}
+	})
+
+	if _, ok := r.Params["time"]; !ok {
+		now := float64(time.Now().UnixNano()) / 1000000000
+		r.Params["time"] = []string{strconv.FormatFloat(now, 'f', -1, 64)}
+	}
+
+	entityType := vipertools.GetString(v, "entity-type")
+	if entityType == "" || entityType == heartbeat.FileType.String() {
+		for _, key := range pathKeys {
+			values, ok := r.Params[key]
+			if !ok || values[0] == "" || isRemote(values[0]) {
+				continue
+			}
+
+			p, err := absolutePath(values[0])
+			if err != nil {
+				return daemon.Request{}, fmt.Errorf("failed to resolve %s: %s", key, err)
+			}
+
+			r.Params[key] = []string{p}
+		}
+	}
+
+	if v.GetBool("extra-heartbeats") {
+		input, err := params.ReadExtraHeartbeatsInput(v)
+		if err != nil {
+			log.Errorf("failed to read extra heartbeats: %s", err)
+		} else {
+			r.ExtraHeartbeats = input
+		}
+	}
+
+	return r, nil
+}
+
+func absolutePath(fp string) (string, error) {
+	expanded, err := homedir.Expand(fp)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Abs(expanded)
+}
+
+// isRemote returns true for remote and windows network mount entities,
+// which must be forwarded unchanged.
+func isRemote(fp string) bool {
+	return remote.RemoteAddressRegex.MatchString(fp) || windows.IsWindowsNetworkMount(fp)
+}
+
+func isForwarded(key string) bool {
+	return isHeartbeatData(key) || contains(forwardedKeys, key)
+}
+
+func isHeartbeatData(key string) bool {
+	return contains(heartbeatDataKeys, key)
+}
+
+func contains(keys []string, key string) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+
+	return false
+}
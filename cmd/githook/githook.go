@@ -0,0 +1,48 @@
+package githook
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/wakatime/wakatime-cli/pkg/exitcode"
+	"github.com/wakatime/wakatime-cli/pkg/githook"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/vipertools"
+
+	"github.com/spf13/viper"
+)
+
+// Run executes the install-git-hooks command. It installs git hooks into the
+// passed in repository and prints the installed hooks.
+func Run(v *viper.Viper) (int, error) {
+	dir := vipertools.GetString(v, "install-git-hooks")
+
+	installed, err := Install(dir)
+	for _, fp := range installed {
+		fmt.Println(fp)
+	}
+
+	if err != nil {
+		return exitcode.ErrGeneric, fmt.Errorf("failed to install git hooks: %w", err)
+	}
+
+	return exitcode.Success, nil
+}
+
+// Install installs git hooks calling the running wakatime-cli executable into
+// the hooks dir of the git repository at dir.
+func Install(dir string) ([]string, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve wakatime-cli executable: %s", err)
+	}
+
+	hooksDir, err := githook.HooksDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debugf("install git hooks into %q", hooksDir)
+
+	return githook.Install(hooksDir, executable)
+}
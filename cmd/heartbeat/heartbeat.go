@@ -1,12 +1,15 @@
 package heartbeat
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	apicmd "github.com/wakatime/wakatime-cli/cmd/api"
 	offlinecmd "github.com/wakatime/wakatime-cli/cmd/offline"
+	"github.com/wakatime/wakatime-cli/cmd/output"
 	"github.com/wakatime/wakatime-cli/cmd/params"
 	"github.com/wakatime/wakatime-cli/pkg/api"
 	"github.com/wakatime/wakatime-cli/pkg/backoff"
@@ -20,18 +23,22 @@ import (
 	"github.com/wakatime/wakatime-cli/pkg/offline"
 	"github.com/wakatime/wakatime-cli/pkg/project"
 	"github.com/wakatime/wakatime-cli/pkg/remote"
+	"github.com/wakatime/wakatime-cli/pkg/trace"
 
 	"github.com/spf13/viper"
 )
 
-// Run executes the heartbeat command.
-func Run(v *viper.Viper) (int, error) {
+// traceExportTimeout is the timeout for sending spans to a collector endpoint.
+const traceExportTimeout = 5 * time.Second
+
+// Run executes the heartbeat command. Sending is aborted, once ctx is done.
+func Run(ctx context.Context, v *viper.Viper) (int, error) {
 	queueFilepath, err := offline.QueueFilepath()
 	if err != nil {
 		log.Warnf("failed to load offline queue filepath: %s", err)
 	}
 
-	err = SendHeartbeats(v, queueFilepath)
+	err = SendHeartbeats(ctx, v, queueFilepath)
 	if err != nil {
 		var errauth api.ErrAuth
 		if errors.As(err, &errauth) {
@@ -70,8 +77,11 @@ func Run(v *viper.Viper) (int, error) {
 
 // SendHeartbeats sends a heartbeat to the wakatime api and includes additional
 // heartbeats from the offline queue, if available and offline sync is not
-// explicitly disabled.
-func SendHeartbeats(v *viper.Viper, queueFilepath string) error {
+// explicitly disabled. The repo config file of the entity applies to this
+// heartbeat only, so v is left unchanged.
+func SendHeartbeats(ctx context.Context, v *viper.Viper, queueFilepath string) error {
+	v, _ = params.LoadRepoConfig(ctx, v)
+
 	params, err := params.Load(v)
 	if err != nil {
 		if err := offlinecmd.SaveHeartbeats(v, nil, queueFilepath); err != nil {
@@ -81,11 +91,13 @@ func SendHeartbeats(v *viper.Viper, queueFilepath string) error {
 		return fmt.Errorf("failed to load command parameters: %w", err)
 	}
 
+	setRedaction(v, params.Heartbeat.ExtraHeartbeats)
+
 	setLogFields(&params)
 
 	log.Debugf("params: %s", params)
 
-	heartbeats := buildHeartbeats(params)
+	heartbeats := BuildHeartbeats(params)
 
 	// only send at once the maximum amount of `offline.SendLimit`.
 	if len(heartbeats) > offline.SendLimit {
@@ -102,7 +114,27 @@ func SendHeartbeats(v *viper.Viper, queueFilepath string) error {
 		heartbeats = heartbeats[:offline.SendLimit]
 	}
 
-	handleOpts := initHandleOptions(params)
+	return HandleHeartbeats(ctx, v, params, heartbeats, queueFilepath)
+}
+
+// HandleHeartbeats runs heartbeats through the processing pipeline configured
+// by params and sends them to the wakatime api. Heartbeats are queued for later
+// sending, if sending fails or is aborted, because ctx is done, and offline
+// queueing is not explicitly disabled.
+func HandleHeartbeats(
+	ctx context.Context,
+	v *viper.Viper,
+	params params.Params,
+	heartbeats []heartbeat.Heartbeat,
+	queueFilepath string,
+) error {
+	var tracer *trace.Tracer
+	if params.Trace.Enabled() {
+		tracer = trace.NewTracer()
+		defer exportTrace(tracer, params.Trace)
+	}
+
+	handleOpts := initHandleOptions(params, tracer)
 
 	if !params.Offline.Disabled {
 		if params.Offline.QueueFile != "" {
@@ -118,14 +150,14 @@ func SendHeartbeats(v *viper.Viper, queueFilepath string) error {
 			return fmt.Errorf("failed to initialize offline queue handle option: %w", err)
 		}
 
-		handleOpts = append(handleOpts, offlineHandleOpt)
+		handleOpts = append(handleOpts, trace.WithSpan(tracer, "offline_queue", offlineHandleOpt))
 	}
 
-	handleOpts = append(handleOpts, backoff.WithBackoff(backoff.Config{
+	handleOpts = append(handleOpts, trace.WithSpan(tracer, "backoff", backoff.WithBackoff(backoff.Config{
 		V:       v,
 		At:      params.API.BackoffAt,
 		Retries: params.API.BackoffRetries,
-	}))
+	})))
 
 	apiClient, err := apicmd.NewClient(params.API)
 	if err != nil {
@@ -138,13 +170,19 @@ func SendHeartbeats(v *viper.Viper, queueFilepath string) error {
 		return fmt.Errorf("failed to initialize api client: %w", err)
 	}
 
+	if tracer != nil {
+		api.WithTracing(tracer)(apiClient)
+	}
+
 	handle := heartbeat.NewHandle(apiClient, handleOpts...)
 
-	results, err := handle(heartbeats)
+	results, err := handle(ctx, heartbeats)
 	if err != nil {
 		return err
 	}
 
+	output.RecordResults(results)
+
 	for _, result := range results {
 		if len(result.Errors) > 0 {
 			log.Warnln(strings.Join(result.Errors, " "))
@@ -154,7 +192,8 @@ func SendHeartbeats(v *viper.Viper, queueFilepath string) error {
 	return nil
 }
 
-func buildHeartbeats(params params.Params) []heartbeat.Heartbeat {
+// BuildHeartbeats creates the main heartbeat and any extra heartbeats from params.
+func BuildHeartbeats(params params.Params) []heartbeat.Heartbeat {
 	heartbeats := []heartbeat.Heartbeat{}
 
 	userAgent := heartbeat.UserAgentUnknownPlugin()
@@ -205,45 +244,77 @@ func buildHeartbeats(params params.Params) []heartbeat.Heartbeat {
 	return heartbeats
 }
 
-func initHandleOptions(params params.Params) []heartbeat.HandleOption {
+func initHandleOptions(params params.Params, tracer *trace.Tracer) []heartbeat.HandleOption {
 	return []heartbeat.HandleOption{
-		heartbeat.WithFormatting(heartbeat.FormatConfig{
+		trace.WithSpan(tracer, "format", heartbeat.WithFormatting(heartbeat.FormatConfig{
 			RemoteAddressPattern: remote.RemoteAddressRegex,
-		}),
-		filter.WithFiltering(filter.Config{
+		})),
+		trace.WithSpan(tracer, "filter", filter.WithFiltering(filter.Config{
 			Exclude:                    params.Heartbeat.Filter.Exclude,
 			Include:                    params.Heartbeat.Filter.Include,
 			IncludeOnlyWithProjectFile: params.Heartbeat.Filter.IncludeOnlyWithProjectFile,
 			RemoteAddressPattern:       remote.RemoteAddressRegex,
-		}),
-		heartbeat.WithEntityModifer(),
-		remote.WithDetection(),
-		filestats.WithDetection(filestats.Config{
+		})),
+		trace.WithSpan(tracer, "entity_modifier", heartbeat.WithEntityModifer()),
+		trace.WithSpan(tracer, "remote_detection", remote.WithDetection()),
+		trace.WithSpan(tracer, "filestats_detection", filestats.WithDetection(filestats.Config{
 			LinesInFile: params.Heartbeat.LinesInFile,
-		}),
-		language.WithDetection(),
-		deps.WithDetection(deps.Config{
+		})),
+		trace.WithSpan(tracer, "language_detection", language.WithDetection()),
+		trace.WithSpan(tracer, "dependency_detection", deps.WithDetection(deps.Config{
 			FilePatterns: params.Heartbeat.Sanitize.HideFileNames,
-		}),
-		project.WithDetection(project.Config{
+		})),
+		trace.WithSpan(tracer, "project_detection", project.WithDetection(project.Config{
 			ShouldObfuscateProject: heartbeat.ShouldSanitize(
 				params.Heartbeat.Entity, params.Heartbeat.Sanitize.HideProjectNames),
+			DetectAppFolder:   params.Heartbeat.Project.DetectAppFolder,
+			GitRemote:         params.Heartbeat.Project.GitRemote,
 			MapPatterns:       params.Heartbeat.Project.MapPatterns,
+			MonorepoPatterns:  params.Heartbeat.Project.MonorepoPatterns,
 			SubmodulePatterns: params.Heartbeat.Project.DisableSubmodule,
-		}),
-		project.WithFiltering(project.FilterConfig{
+			SendCommit:        params.Heartbeat.Project.SendCommit,
+		})),
+		trace.WithSpan(tracer, "project_settings_filter", filter.WithProjectSettingsFiltering()),
+		trace.WithSpan(tracer, "project_filter", project.WithFiltering(project.FilterConfig{
 			ExcludeUnknownProject: params.Heartbeat.Filter.ExcludeUnknownProject,
-		}),
-		heartbeat.WithSanitization(heartbeat.SanitizeConfig{
+		})),
+		trace.WithSpan(tracer, "sanitization", heartbeat.WithSanitization(heartbeat.SanitizeConfig{
 			BranchPatterns:       params.Heartbeat.Sanitize.HideBranchNames,
 			FilePatterns:         params.Heartbeat.Sanitize.HideFileNames,
 			HideProjectFolder:    params.Heartbeat.Sanitize.HideProjectFolder,
 			ProjectPatterns:      params.Heartbeat.Sanitize.HideProjectNames,
 			RemoteAddressPattern: remote.RemoteAddressRegex,
-		}),
+		})),
 	}
 }
 
+// exportTrace exports recorded spans to the configured file and collector
+// endpoint and logs a timing summary in verbose mode.
+func exportTrace(tracer *trace.Tracer, params params.Trace) {
+	if params.Summary {
+		log.Debugf("timing summary:\n%s", tracer.Summary())
+	}
+
+	if params.File != "" {
+		if err := tracer.ExportFile(params.File); err != nil {
+			log.Warnf("failed to export trace to file: %s", err)
+		}
+	}
+
+	if params.Endpoint != "" {
+		if err := tracer.ExportHTTP(params.Endpoint, traceExportTimeout); err != nil {
+			log.Warnf("failed to export trace to collector: %s", err)
+		}
+	}
+}
+
+// setRedaction sets log redaction to the entity and extra heartbeats of this
+// invocation, with the sanitize params of its repo config. Names redacted for
+// previous invocations of long-running modes are dropped.
+func setRedaction(v *viper.Viper, extraHeartbeats []heartbeat.Heartbeat) {
+	log.SetRedaction(params.LoadRedactionParams(v, extraHeartbeats...))
+}
+
 func setLogFields(params *params.Params) {
 	if params.API.Plugin != "" {
 		log.WithField("plugin", params.API.Plugin)
@@ -1,25 +1,31 @@
 package heartbeat_test
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/wakatime/wakatime-cli/cmd"
 	cmdheartbeat "github.com/wakatime/wakatime-cli/cmd/heartbeat"
+	paramscmd "github.com/wakatime/wakatime-cli/cmd/params"
 	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
 	"github.com/wakatime/wakatime-cli/pkg/log"
 	"github.com/wakatime/wakatime-cli/pkg/offline"
+	"github.com/wakatime/wakatime-cli/pkg/project"
 
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/yookoala/realpath"
 )
 
 func TestSendHeartbeats(t *testing.T) {
@@ -96,12 +102,54 @@ func TestSendHeartbeats(t *testing.T) {
 	offlineQueueFile, err := os.CreateTemp(t.TempDir(), "")
 	require.NoError(t, err)
 
-	err = cmdheartbeat.SendHeartbeats(v, offlineQueueFile.Name())
+	err = cmdheartbeat.SendHeartbeats(context.Background(), v, offlineQueueFile.Name())
 	require.NoError(t, err)
 
 	assert.Eventually(t, func() bool { return numCalls == 1 }, time.Second, 50*time.Millisecond)
 }
 
+func TestSendHeartbeats_TraceFile(t *testing.T) {
+	testServerURL, router, tearDown := setupTestServer()
+	defer tearDown()
+
+	router.HandleFunc("/users/current/heartbeats.bulk", func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+
+		f, err := os.Open("testdata/api_heartbeats_response.json")
+		require.NoError(t, err)
+		defer f.Close()
+
+		_, err = io.Copy(w, f)
+		require.NoError(t, err)
+	})
+
+	traceFile := filepath.Join(t.TempDir(), "trace.jsonl")
+
+	v := viper.New()
+	v.SetDefault("sync-offline-activity", 1000)
+	v.Set("api-url", testServerURL)
+	v.Set("entity", "testdata/main.go")
+	v.Set("key", "00000000-0000-4000-8000-000000000000")
+	v.Set("plugin", "plugin")
+	v.Set("project", "wakatime-cli")
+	v.Set("time", 1585598059.1)
+	v.Set("timeout", 5)
+	v.Set("trace-file", traceFile)
+
+	offlineQueueFile, err := os.CreateTemp(t.TempDir(), "")
+	require.NoError(t, err)
+
+	err = cmdheartbeat.SendHeartbeats(context.Background(), v, offlineQueueFile.Name())
+	require.NoError(t, err)
+
+	data, err := os.ReadFile(traceFile)
+	require.NoError(t, err)
+
+	for _, name := range []string{"format", "language_detection", "project_detection", "api.Client.Do"} {
+		assert.Contains(t, string(data), fmt.Sprintf(`"name":%q`, name))
+	}
+}
+
 func TestSendHeartbeats_WithFiltering_Exclude(t *testing.T) {
 	testServerURL, router, tearDown := setupTestServer()
 	defer tearDown()
@@ -130,7 +178,7 @@ func TestSendHeartbeats_WithFiltering_Exclude(t *testing.T) {
 	offlineQueueFile, err := os.CreateTemp(t.TempDir(), "")
 	require.NoError(t, err)
 
-	err = cmdheartbeat.SendHeartbeats(v, offlineQueueFile.Name())
+	err = cmdheartbeat.SendHeartbeats(context.Background(), v, offlineQueueFile.Name())
 	require.NoError(t, err)
 
 	assert.Equal(t, 0, numCalls)
@@ -260,7 +308,7 @@ func TestSendHeartbeats_ExtraHeartbeats(t *testing.T) {
 
 	defer offlineQueueFile.Close()
 
-	err = cmdheartbeat.SendHeartbeats(v, offlineQueueFile.Name())
+	err = cmdheartbeat.SendHeartbeats(context.Background(), v, offlineQueueFile.Name())
 	require.NoError(t, err)
 
 	offlineCount, err := offline.CountHeartbeats(offlineQueueFile.Name())
@@ -271,6 +319,100 @@ func TestSendHeartbeats_ExtraHeartbeats(t *testing.T) {
 	assert.Eventually(t, func() bool { return numCalls == 1 }, time.Second, 50*time.Millisecond)
 }
 
+func TestSendHeartbeats_RedactHiddenEntity(t *testing.T) {
+	testServerURL, router, tearDown := setupTestServer()
+	defer tearDown()
+
+	router.HandleFunc("/users/current/heartbeats.bulk", func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+
+		f, err := os.Open("testdata/api_heartbeats_response.json")
+		require.NoError(t, err)
+		defer f.Close()
+
+		_, err = io.Copy(w, f)
+		require.NoError(t, err)
+	})
+
+	output := log.Output()
+	defer log.SetOutput(output)
+
+	var buf bytes.Buffer
+
+	log.SetOutput(&buf)
+	log.SetVerbose(true)
+
+	defer log.SetVerbose(false)
+
+	// no redaction set up front, like in long-running modes
+	log.SetRedaction(log.Redaction{})
+	defer log.SetRedaction(log.Redaction{})
+
+	v := viper.New()
+	v.SetDefault("sync-offline-activity", 1000)
+	v.Set("api-url", testServerURL)
+	v.Set("entity", "testdata/main.go")
+	v.Set("entity-type", "file")
+	v.Set("hide-file-names", "true")
+	v.Set("key", "00000000-0000-4000-8000-000000000000")
+	v.Set("timeout", 5)
+
+	offlineQueueFile, err := os.CreateTemp(t.TempDir(), "")
+	require.NoError(t, err)
+
+	err = cmdheartbeat.SendHeartbeats(context.Background(), v, offlineQueueFile.Name())
+	require.NoError(t, err)
+
+	assert.Contains(t, buf.String(), "params: ")
+	assert.NotContains(t, buf.String(), "testdata/main.go")
+}
+
+func TestBuildHeartbeats_GitHook(t *testing.T) {
+	tmpDir, err := realpath.Realpath(t.TempDir())
+	require.NoError(t, err)
+
+	repo := filepath.Join(tmpDir, "wakatime-cli")
+
+	err = os.MkdirAll(filepath.Join(repo, ".git"), 0700)
+	require.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(repo, ".git", "HEAD"), []byte("ref: refs/heads/feature/hooks\n"), 0600)
+	require.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(repo, ".git", "config"), []byte("[core]\n\tbare = false\n"), 0600)
+	require.NoError(t, err)
+
+	// flags passed by the installed git hooks
+	v := viper.New()
+	v.Set("category", "code reviewing")
+	v.Set("detect-project-folder", true)
+	v.Set("entity", "git")
+	v.Set("entity-type", "app")
+	v.Set("key", "00000000-0000-4000-8000-000000000000")
+	v.Set("project-folder", repo)
+
+	params, err := paramscmd.Load(v)
+	require.NoError(t, err)
+
+	opt := project.WithDetection(project.Config{
+		DetectAppFolder: params.Heartbeat.Project.DetectAppFolder,
+	})
+
+	handle := opt(func(_ context.Context, hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		require.Len(t, hh, 1)
+
+		require.NotNil(t, hh[0].Project)
+		require.NotNil(t, hh[0].Branch)
+		assert.Equal(t, "wakatime-cli", *hh[0].Project)
+		assert.Equal(t, "feature/hooks", *hh[0].Branch)
+
+		return nil, nil
+	})
+
+	_, err = handle(context.Background(), cmdheartbeat.BuildHeartbeats(params))
+	require.NoError(t, err)
+}
+
 func TestSendHeartbeats_NonExistingEntity(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -303,7 +445,7 @@ func TestSendHeartbeats_NonExistingEntity(t *testing.T) {
 
 	defer f.Close()
 
-	err = cmdheartbeat.SendHeartbeats(v, f.Name())
+	err = cmdheartbeat.SendHeartbeats(context.Background(), v, f.Name())
 	require.NoError(t, err)
 
 	output, err := io.ReadAll(logFile)
@@ -420,7 +562,7 @@ func TestSendHeartbeats_NonExistingExtraHeartbeatsEntity(t *testing.T) {
 		}
 	}()
 
-	err = cmdheartbeat.SendHeartbeats(v, offlineQueueFile.Name())
+	err = cmdheartbeat.SendHeartbeats(context.Background(), v, offlineQueueFile.Name())
 	require.NoError(t, err)
 
 	output, err := io.ReadAll(logFile)
@@ -5,19 +5,30 @@ import (
 	"path/filepath"
 
 	"github.com/wakatime/wakatime-cli/pkg/ini"
+	"github.com/wakatime/wakatime-cli/pkg/log"
 	"github.com/wakatime/wakatime-cli/pkg/vipertools"
 
 	"github.com/mitchellh/go-homedir"
 	"github.com/spf13/viper"
 )
 
-const defaultFile = ".wakatime.log"
+const (
+	defaultFile = ".wakatime.log"
+	// defaultMaxBackups is the default number of rotated log files to keep.
+	defaultMaxBackups = 3
+	// defaultMaxSizeMB is the default size in megabytes, after which the log file gets rotated.
+	defaultMaxSizeMB = 25
+)
 
 // Params contains log file parameters.
 type Params struct {
-	File     string
-	ToStdout bool
-	Verbose  bool
+	File       string
+	Format     string
+	Level      string
+	MaxBackups int
+	MaxSize    int64
+	ToStdout   bool
+	Verbose    bool
 }
 
 // LoadParams loads needed data from the configuration file.
@@ -27,6 +38,58 @@ func LoadParams(v *viper.Viper) (Params, error) {
 		debug = b
 	}
 
+	verbose := v.GetBool("verbose") || debug
+
+	format := log.FormatJSON
+	if f := vipertools.GetString(v, "settings.log_format"); f != "" {
+		if f != log.FormatJSON && f != log.FormatText {
+			return Params{}, ErrLogFile(fmt.Sprintf("invalid log format %q. must be %q or %q", f, log.FormatJSON, log.FormatText))
+		}
+
+		format = f
+	}
+
+	level := "info"
+	if l := vipertools.GetString(v, "settings.log_level"); l != "" {
+		switch l {
+		case "debug", "info", "warn", "error":
+			level = l
+		default:
+			return Params{}, ErrLogFile(fmt.Sprintf("invalid log level %q. must be debug, info, warn or error", l))
+		}
+	}
+
+	// verbose always enables debug messages
+	if verbose {
+		level = "debug"
+	}
+
+	maxSizeMB := defaultMaxSizeMB
+	if v.IsSet("settings.log_max_size") {
+		maxSizeMB = v.GetInt("settings.log_max_size")
+	}
+
+	if maxSizeMB < 0 {
+		return Params{}, ErrLogFile(fmt.Sprintf("invalid log max size %d. must be a positive number", maxSizeMB))
+	}
+
+	maxBackups := defaultMaxBackups
+	if v.IsSet("settings.log_max_backups") {
+		maxBackups = v.GetInt("settings.log_max_backups")
+	}
+
+	if maxBackups < 0 {
+		return Params{}, ErrLogFile(fmt.Sprintf("invalid log max backups %d. must be a positive number", maxBackups))
+	}
+
+	params := Params{
+		Format:     format,
+		Level:      level,
+		MaxBackups: maxBackups,
+		MaxSize:    int64(maxSizeMB) * 1024 * 1024,
+		Verbose:    verbose,
+	}
+
 	logFile, ok := vipertools.FirstNonEmptyString(v, "log-file", "logfile", "settings.log_file")
 	if ok {
 		p, err := homedir.Expand(logFile)
@@ -35,10 +98,9 @@ func LoadParams(v *viper.Viper) (Params, error) {
 				ErrLogFile(fmt.Sprintf("failed expanding log file: %s", err))
 		}
 
-		return Params{
-			File:    p,
-			Verbose: v.GetBool("verbose") || debug,
-		}, nil
+		params.File = p
+
+		return params, nil
 	}
 
 	home, err := ini.WakaHomeDir()
@@ -46,9 +108,8 @@ func LoadParams(v *viper.Viper) (Params, error) {
 		return Params{}, fmt.Errorf("failed getting user's home directory: %s", err)
 	}
 
-	return Params{
-		File:     filepath.Join(home, defaultFile),
-		ToStdout: v.GetBool("log-to-stdout"),
-		Verbose:  v.GetBool("verbose") || debug,
-	}, nil
+	params.File = filepath.Join(home, defaultFile)
+	params.ToStdout = v.GetBool("log-to-stdout")
+
+	return params, nil
 }
@@ -12,6 +12,60 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+func TestLoadParams_Rotation(t *testing.T) {
+	v := viper.New()
+	v.Set("log-file", "/path/to/wakatime.log")
+	v.Set("settings.log_format", "text")
+	v.Set("settings.log_level", "warn")
+	v.Set("settings.log_max_backups", 0)
+	v.Set("settings.log_max_size", 1)
+
+	params, err := logfile.LoadParams(v)
+	require.NoError(t, err)
+
+	assert.Equal(t, logfile.Params{
+		File:       "/path/to/wakatime.log",
+		Format:     "text",
+		Level:      "warn",
+		MaxBackups: 0,
+		MaxSize:    1024 * 1024,
+	}, params)
+}
+
+func TestLoadParams_VerboseOverridesLevel(t *testing.T) {
+	v := viper.New()
+	v.Set("log-file", "/path/to/wakatime.log")
+	v.Set("settings.log_level", "error")
+	v.Set("verbose", true)
+
+	params, err := logfile.LoadParams(v)
+	require.NoError(t, err)
+
+	assert.Equal(t, "debug", params.Level)
+}
+
+func TestLoadParams_InvalidFormat(t *testing.T) {
+	v := viper.New()
+	v.Set("settings.log_format", "xml")
+
+	_, err := logfile.LoadParams(v)
+	require.Error(t, err)
+
+	var errlogfile logfile.ErrLogFile
+	assert.ErrorAs(t, err, &errlogfile)
+}
+
+func TestLoadParams_InvalidLevel(t *testing.T) {
+	v := viper.New()
+	v.Set("settings.log_level", "trace")
+
+	_, err := logfile.LoadParams(v)
+	require.Error(t, err)
+
+	var errlogfile logfile.ErrLogFile
+	assert.ErrorAs(t, err, &errlogfile)
+}
+
 func TestLoadParams(t *testing.T) {
 	tmpFile, err := os.CreateTemp(t.TempDir(), "wakatime.log")
 	require.NoError(t, err)
@@ -41,15 +95,23 @@ func TestLoadParams(t *testing.T) {
 		"log file and verbose set": {
 			ViperDebug: true,
 			Expected: logfile.Params{
-				File:    filepath.Join(home, ".wakatime.log"),
-				Verbose: true,
+				File:       filepath.Join(home, ".wakatime.log"),
+				Format:     "json",
+				Level:      "debug",
+				MaxBackups: 3,
+				MaxSize:    25 * 1024 * 1024,
+				Verbose:    true,
 			},
 		},
 		"log file and verbose from config": {
 			ViperDebugConfig: true,
 			Expected: logfile.Params{
-				File:    filepath.Join(home, ".wakatime.log"),
-				Verbose: true,
+				File:       filepath.Join(home, ".wakatime.log"),
+				Format:     "json",
+				Level:      "debug",
+				MaxBackups: 3,
+				MaxSize:    25 * 1024 * 1024,
+				Verbose:    true,
 			},
 		},
 		"log file flag takes preceedence": {
@@ -57,38 +119,62 @@ func TestLoadParams(t *testing.T) {
 			ViperLogFileConfig: "otherfolder/wakatime.config.log",
 			ViperLogFileOld:    "otherfolder/wakatime.old.log",
 			Expected: logfile.Params{
-				File: tmpFile.Name(),
+				File:       tmpFile.Name(),
+				Format:     "json",
+				Level:      "info",
+				MaxBackups: 3,
+				MaxSize:    25 * 1024 * 1024,
 			},
 		},
 		"log file deprecated flag takes preceedence": {
 			ViperLogFileConfig: "otherfolder/wakatime.config.log",
 			ViperLogFileOld:    tmpFile.Name(),
 			Expected: logfile.Params{
-				File: tmpFile.Name(),
+				File:       tmpFile.Name(),
+				Format:     "json",
+				Level:      "info",
+				MaxBackups: 3,
+				MaxSize:    25 * 1024 * 1024,
 			},
 		},
 		"log file from config": {
 			ViperLogFileConfig: tmpFile.Name(),
 			Expected: logfile.Params{
-				File: tmpFile.Name(),
+				File:       tmpFile.Name(),
+				Format:     "json",
+				Level:      "info",
+				MaxBackups: 3,
+				MaxSize:    25 * 1024 * 1024,
 			},
 		},
 		"log file from WAKATIME_HOME": {
 			EnvVar: dir,
 			Expected: logfile.Params{
-				File: filepath.Join(dir, ".wakatime.log"),
+				File:       filepath.Join(dir, ".wakatime.log"),
+				Format:     "json",
+				Level:      "info",
+				MaxBackups: 3,
+				MaxSize:    25 * 1024 * 1024,
 			},
 		},
 		"log file from home dir": {
 			Expected: logfile.Params{
-				File: filepath.Join(home, ".wakatime.log"),
+				File:       filepath.Join(home, ".wakatime.log"),
+				Format:     "json",
+				Level:      "info",
+				MaxBackups: 3,
+				MaxSize:    25 * 1024 * 1024,
 			},
 		},
 		"log to stdout": {
 			ViperToStdout: true,
 			Expected: logfile.Params{
-				File:     filepath.Join(home, ".wakatime.log"),
-				ToStdout: true,
+				File:       filepath.Join(home, ".wakatime.log"),
+				Format:     "json",
+				Level:      "info",
+				MaxBackups: 3,
+				MaxSize:    25 * 1024 * 1024,
+				ToStdout:   true,
 			},
 		},
 	}
@@ -0,0 +1,174 @@
+package logsummary
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/cmd/logfile"
+	"github.com/wakatime/wakatime-cli/pkg/exitcode"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/vipertools"
+
+	"github.com/spf13/viper"
+)
+
+// defaultSince is the default time range of the summary.
+const defaultSince = time.Hour
+
+// Params contains log summary parameters.
+type Params struct {
+	Files  []string
+	Filter log.EntryFilter
+}
+
+// Run executes the log-summary command.
+func Run(v *viper.Viper) (int, error) {
+	output, err := Summary(v, time.Now())
+	if err != nil {
+		return exitcode.ErrGeneric, fmt.Errorf(
+			"failed to summarize log file: %s",
+			err,
+		)
+	}
+
+	fmt.Println(output)
+
+	return exitcode.Success, nil
+}
+
+// Summary returns a rendered summary of recent log entries, grouped by
+// message template and plugin.
+func Summary(v *viper.Viper, now time.Time) (string, error) {
+	params, err := LoadParams(v, now)
+	if err != nil {
+		return "", fmt.Errorf("failed to load log summary params: %w", err)
+	}
+
+	var entries []log.Entry
+
+	for _, fp := range params.Files {
+		parsed, err := readEntries(fp)
+		if err != nil {
+			return "", err
+		}
+
+		entries = append(entries, parsed...)
+	}
+
+	return render(log.Summarize(entries, params.Filter), params.Filter), nil
+}
+
+// LoadParams loads needed data from the configuration file. The log file and
+// its rotated backups are summarized.
+func LoadParams(v *viper.Viper, now time.Time) (Params, error) {
+	logfileParams, err := logfile.LoadParams(v)
+	if err != nil {
+		return Params{}, fmt.Errorf("failed to load log file params: %w", err)
+	}
+
+	since := now.Add(-defaultSince)
+
+	if s := vipertools.GetString(v, "log-summary-since"); s != "" {
+		since, err = parseSince(s, now)
+		if err != nil {
+			return Params{}, err
+		}
+	}
+
+	level := "error"
+
+	if l := vipertools.GetString(v, "log-summary-level"); l != "" {
+		switch l {
+		case "debug", "info", "warn", "error":
+			level = l
+		default:
+			return Params{}, fmt.Errorf("invalid level %q. must be debug, info, warn or error", l)
+		}
+	}
+
+	files := []string{logfileParams.File}
+	for i := 1; i <= logfileParams.MaxBackups; i++ {
+		files = append(files, fmt.Sprintf("%s.%d", logfileParams.File, i))
+	}
+
+	return Params{
+		Files: files,
+		Filter: log.EntryFilter{
+			Level:  level,
+			Plugin: vipertools.GetString(v, "log-summary-plugin"),
+			Since:  since,
+		},
+	}, nil
+}
+
+// parseSince parses either a duration relative to now, like 30m or 2h, or
+// a RFC3339 timestamp.
+func parseSince(s string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		if d < 0 {
+			return time.Time{}, fmt.Errorf("invalid since %q. duration must be positive", s)
+		}
+
+		return now.Add(-d), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid since %q. must be a duration like 2h or a RFC3339 timestamp", s)
+	}
+
+	return t, nil
+}
+
+func readEntries(fp string) ([]log.Entry, error) {
+	f, err := os.Open(fp)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file: %s", err)
+	}
+
+	defer f.Close()
+
+	entries, err := log.ParseEntries(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse log file %q: %s", fp, err)
+	}
+
+	return entries, nil
+}
+
+func render(groups []log.EntryGroup, filter log.EntryFilter) string {
+	if len(groups) == 0 {
+		return fmt.Sprintf("No %s messages since %s", filter.Level, filter.Since.Format(time.RFC3339))
+	}
+
+	var builder strings.Builder
+
+	for n, g := range groups {
+		if n > 0 {
+			builder.WriteString("\n")
+		}
+
+		plugin := g.Plugin
+		if plugin == "" {
+			plugin = "unknown plugin"
+		}
+
+		fmt.Fprintf(&builder, "[%s] %s: %s\n", g.Level, plugin, g.Template)
+		fmt.Fprintf(
+			&builder,
+			"  count: %d, first: %s, last: %s\n",
+			g.Count,
+			g.First.Format(time.RFC3339),
+			g.Last.Format(time.RFC3339),
+		)
+	}
+
+	return strings.TrimSuffix(builder.String(), "\n")
+}
@@ -0,0 +1,98 @@
+package logsummary_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/cmd/logsummary"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSummary(t *testing.T) {
+	v := viper.New()
+	v.Set("log-file", "testdata/wakatime.log")
+
+	output, err := logsummary.Summary(v, time.Date(2022, 1, 2, 11, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	assert.Equal(t,
+		"[error] vscode/1.63.0 vscode-wakatime/17.1.0: failed to run command: sending heartbeat(s) failed: invalid api key\n"+
+			"  count: 1, first: 2022-01-02T10:50:00Z, last: 2022-01-02T10:50:00Z\n"+
+			"\n"+
+			"[error] vim/8.2 vim-wakatime/9.0.1: failed to run command: sending heartbeat(s) later due to api error:"+
+			" failed making request to \"*\": connection refused\n"+
+			"  count: 1, first: 2022-01-02T10:45:00Z, last: 2022-01-02T10:45:00Z\n"+
+			"\n"+
+			"[error] vim/8.2 vim-wakatime/9.0.1: failed to run command: sending heartbeat(s) later due to api error:"+
+			" failed making request to \"*\": timeout\n"+
+			"  count: 1, first: 2022-01-02T10:05:00Z, last: 2022-01-02T10:05:00Z",
+		output,
+	)
+}
+
+func TestSummary_Filters(t *testing.T) {
+	v := viper.New()
+	v.Set("log-summary-level", "warn")
+	v.Set("log-file", "testdata/wakatime.log")
+	v.Set("log-summary-plugin", "vscode")
+	v.Set("log-summary-since", "15m")
+
+	output, err := logsummary.Summary(v, time.Date(2022, 1, 2, 10, 50, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	assert.Equal(t,
+		"[error] vscode/1.63.0 vscode-wakatime/17.1.0: failed to run command: sending heartbeat(s) failed: invalid api key\n"+
+			"  count: 1, first: 2022-01-02T10:50:00Z, last: 2022-01-02T10:50:00Z\n"+
+			"\n"+
+			"[warn] vscode/1.63.0 vscode-wakatime/17.1.0: missing <n> results from api.\n"+
+			"  count: 1, first: 2022-01-02T10:40:00Z, last: 2022-01-02T10:40:00Z",
+		output,
+	)
+}
+
+func TestSummary_IncludesRotatedFiles(t *testing.T) {
+	v := viper.New()
+	v.Set("log-file", "testdata/wakatime.log")
+	v.Set("log-summary-plugin", "vim")
+	v.Set("log-summary-since", "2022-01-02T00:00:00Z")
+
+	output, err := logsummary.Summary(v, time.Date(2022, 1, 2, 11, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	assert.Contains(t, output, "  count: 2, first: 2022-01-02T08:00:00Z, last: 2022-01-02T10:05:00Z")
+}
+
+func TestSummary_NoMessages(t *testing.T) {
+	v := viper.New()
+	v.Set("log-file", "testdata/wakatime.log")
+
+	output, err := logsummary.Summary(v, time.Date(2022, 1, 3, 11, 0, 0, 0, time.UTC))
+	require.NoError(t, err)
+
+	assert.Equal(t, "No error messages since 2022-01-03T10:00:00Z", output)
+}
+
+func TestLoadParams_InvalidSince(t *testing.T) {
+	v := viper.New()
+	v.Set("log-file", "testdata/wakatime.log")
+	v.Set("log-summary-since", "yesterday")
+
+	_, err := logsummary.LoadParams(v, time.Now())
+	require.Error(t, err)
+
+	assert.Contains(t, err.Error(), "invalid since \"yesterday\"")
+}
+
+func TestLoadParams_InvalidLevel(t *testing.T) {
+	v := viper.New()
+	v.Set("log-summary-level", "fatal")
+	v.Set("log-file", "testdata/wakatime.log")
+
+	_, err := logsummary.LoadParams(v, time.Now())
+	require.Error(t, err)
+
+	assert.Contains(t, err.Error(), "invalid level \"fatal\"")
+}
@@ -0,0 +1,201 @@
+package logtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	cmdheartbeat "github.com/wakatime/wakatime-cli/cmd/heartbeat"
+	offlinecmd "github.com/wakatime/wakatime-cli/cmd/offline"
+	"github.com/wakatime/wakatime-cli/cmd/params"
+	"github.com/wakatime/wakatime-cli/pkg/api"
+	"github.com/wakatime/wakatime-cli/pkg/exitcode"
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/offline"
+	"github.com/wakatime/wakatime-cli/pkg/vipertools"
+
+	"github.com/spf13/viper"
+)
+
+const (
+	// defaultEntity is the entity of logged time without description.
+	defaultEntity = "manual time"
+	// interval is the time between synthesized heartbeats. It is below any
+	// keystroke timeout, so the server counts the whole time range.
+	interval = time.Minute
+	// maxDuration limits the logged time range, to catch typos.
+	maxDuration = 24 * time.Hour
+)
+
+// Params contains manual time logging parameters.
+type Params struct {
+	Description string
+	End         time.Time
+	Start       time.Time
+}
+
+// Run executes the log command.
+func Run(ctx context.Context, v *viper.Viper) (int, error) {
+	queueFilepath, err := offline.QueueFilepath()
+	if err != nil {
+		log.Warnf("failed to load offline queue filepath: %s", err)
+	}
+
+	if err := LogTime(ctx, v, queueFilepath, time.Now()); err != nil {
+		var errauth api.ErrAuth
+		if errors.As(err, &errauth) {
+			return exitcode.ErrAuth, fmt.Errorf(
+				"logging time failed: invalid api key... find yours at wakatime.com/api-key. %w",
+				err,
+			)
+		}
+
+		var errapi api.Err
+		if errors.As(err, &errapi) {
+			return exitcode.ErrAPI, fmt.Errorf("logging time later due to api error: %w", err)
+		}
+
+		return exitcode.ErrGeneric, fmt.Errorf("logging time failed: %w", err)
+	}
+
+	log.Debugln("successfully logged time")
+
+	return exitcode.Success, nil
+}
+
+// LogTime sends app heartbeats covering the logged time range. Heartbeats
+// exceeding offline.SendLimit are saved to the offline queue and sent by the
+// next offline sync.
+func LogTime(ctx context.Context, v *viper.Viper, queueFilepath string, now time.Time) error {
+	p, err := LoadParams(v, now)
+	if err != nil {
+		return fmt.Errorf("failed to load log time params: %w", err)
+	}
+
+	entity := p.Description
+	if entity == "" {
+		entity = defaultEntity
+	}
+
+	v.Set("entity", entity)
+	v.Set("entity-type", heartbeat.AppType.String())
+
+	heartbeatParams, err := params.Load(v)
+	if err != nil {
+		return fmt.Errorf("failed to load command parameters: %w", err)
+	}
+
+	if heartbeatParams.Heartbeat.Project.Override == "" && heartbeatParams.Heartbeat.Project.Alternate == "" {
+		return errors.New("project is required to log time")
+	}
+
+	heartbeats := Heartbeats(cmdheartbeat.BuildHeartbeats(heartbeatParams)[0], p.Start, p.End)
+
+	log.Debugf("log %s of time with %d heartbeat(s)", p.End.Sub(p.Start), len(heartbeats))
+
+	if len(heartbeats) > offline.SendLimit {
+		if err := offlinecmd.SaveHeartbeats(v, heartbeats[offline.SendLimit:], queueFilepath); err != nil {
+			log.Errorf("failed to save extra heartbeats to offline queue: %s", err)
+		}
+
+		heartbeats = heartbeats[:offline.SendLimit]
+	}
+
+	return cmdheartbeat.HandleHeartbeats(ctx, v, heartbeatParams, heartbeats, queueFilepath)
+}
+
+// Heartbeats synthesizes copies of h from start to end, one per interval.
+// The last heartbeat is at end.
+func Heartbeats(h heartbeat.Heartbeat, start, end time.Time) []heartbeat.Heartbeat {
+	var heartbeats []heartbeat.Heartbeat
+
+	for t := start; t.Before(end); t = t.Add(interval) {
+		h.Time = unixSeconds(t)
+		heartbeats = append(heartbeats, h)
+	}
+
+	h.Time = unixSeconds(end)
+
+	return append(heartbeats, h)
+}
+
+// LoadParams loads log time params from viper.Viper instance. The end defaults
+// to start plus duration, if passed, or else to now.
+func LoadParams(v *viper.Viper, now time.Time) (Params, error) {
+	startStr := vipertools.GetString(v, "start")
+	if startStr == "" {
+		return Params{}, errors.New("start is required")
+	}
+
+	start, err := parseTime(startStr, now)
+	if err != nil {
+		return Params{}, fmt.Errorf("invalid start %q: %s", startStr, err)
+	}
+
+	endStr := vipertools.GetString(v, "end")
+	durationStr := vipertools.GetString(v, "duration")
+
+	var end time.Time
+
+	switch {
+	case endStr != "" && durationStr != "":
+		return Params{}, errors.New("end and duration cannot be used together")
+	case endStr != "":
+		end, err = parseTime(endStr, now)
+		if err != nil {
+			return Params{}, fmt.Errorf("invalid end %q: %s", endStr, err)
+		}
+	case durationStr != "":
+		d, err := time.ParseDuration(durationStr)
+		if err != nil {
+			return Params{}, fmt.Errorf("invalid duration %q. must be like 1h30m", durationStr)
+		}
+
+		end = start.Add(d)
+	default:
+		end = now
+	}
+
+	if !end.After(start) {
+		return Params{}, errors.New("end must be after start")
+	}
+
+	if end.After(now) {
+		return Params{}, errors.New("cannot log time in the future")
+	}
+
+	if end.Sub(start) > maxDuration {
+		return Params{}, fmt.Errorf("cannot log more than %s at once", maxDuration)
+	}
+
+	return Params{
+		Description: vipertools.GetString(v, "description"),
+		End:         end,
+		Start:       start,
+	}, nil
+}
+
+// parseTime parses a RFC3339 timestamp, a local date and time like
+// "2006-01-02 15:04" or a local time of the day of now, like "15:04".
+func parseTime(s string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+
+	if t, err := time.ParseInLocation("2006-01-02 15:04", s, now.Location()); err == nil {
+		return t, nil
+	}
+
+	t, err := time.ParseInLocation("15:04", s, now.Location())
+	if err != nil {
+		return time.Time{}, errors.New("must be a RFC3339 timestamp, like \"2006-01-02 15:04\" or like \"15:04\"")
+	}
+
+	return time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), 0, 0, now.Location()), nil
+}
+
+func unixSeconds(t time.Time) float64 {
+	return float64(t.UnixNano()) / 1000000000
+}
@@ -0,0 +1,220 @@
+package logtime_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/cmd/logtime"
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/offline"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogTime(t *testing.T) {
+	type apiHeartbeat struct {
+		Category string  `json:"category"`
+		Entity   string  `json:"entity"`
+		Project  string  `json:"project"`
+		Time     float64 `json:"time"`
+		Type     string  `json:"type"`
+	}
+
+	var received []apiHeartbeat
+
+	router := http.NewServeMux()
+	router.HandleFunc("/users/current/heartbeats.bulk", func(w http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+
+		err = json.Unmarshal(body, &received)
+		require.NoError(t, err)
+
+		responses := make([]string, len(received))
+		for i := range received {
+			responses[i] = `[{"data": {}}, 201]`
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		_, _ = fmt.Fprintf(w, `{"responses": [%s]}`, strings.Join(responses, ","))
+	})
+
+	apiServer := httptest.NewServer(router)
+	defer apiServer.Close()
+
+	queueFile := filepath.Join(t.TempDir(), "offline.bdb")
+	now := time.Date(2022, 1, 3, 12, 0, 0, 0, time.UTC)
+
+	v := viper.New()
+	v.Set("api-url", apiServer.URL)
+	v.Set("category", "designing")
+	v.Set("description", "Whiteboarding")
+	v.Set("duration", "90m")
+	v.Set("key", "00000000-0000-4000-8000-000000000000")
+	v.Set("offline-queue-file", queueFile)
+	v.Set("project", "wakatime-cli")
+	v.Set("start", "2022-01-03T10:00:00Z")
+
+	err := logtime.LogTime(context.Background(), v, queueFile, now)
+	require.NoError(t, err)
+
+	require.Len(t, received, offline.SendLimit)
+
+	assert.Equal(t, apiHeartbeat{
+		Category: "designing",
+		Entity:   "Whiteboarding",
+		Project:  "wakatime-cli",
+		Time:     1641204000,
+		Type:     "app",
+	}, received[0])
+	assert.Equal(t, float64(1641204000+60), received[1].Time)
+
+	// 91 heartbeats in total, one per minute including start and end
+	count, err := offline.CountHeartbeats(queueFile)
+	require.NoError(t, err)
+
+	assert.Equal(t, 91-offline.SendLimit, count)
+}
+
+func TestLogTime_ProjectRequired(t *testing.T) {
+	v := viper.New()
+	v.Set("key", "00000000-0000-4000-8000-000000000000")
+	v.Set("start", "10:00")
+
+	now := time.Date(2022, 1, 3, 12, 0, 0, 0, time.UTC)
+
+	err := logtime.LogTime(context.Background(), v, filepath.Join(t.TempDir(), "offline.bdb"), now)
+	assert.EqualError(t, err, "project is required to log time")
+}
+
+func TestHeartbeats(t *testing.T) {
+	start := time.Date(2022, 1, 3, 10, 0, 0, 0, time.UTC)
+	end := start.Add(150 * time.Second)
+
+	heartbeats := logtime.Heartbeats(heartbeat.Heartbeat{Entity: "meeting"}, start, end)
+
+	var times []float64
+	for _, h := range heartbeats {
+		assert.Equal(t, "meeting", h.Entity)
+
+		times = append(times, h.Time)
+	}
+
+	assert.Equal(t, []float64{1641204000, 1641204060, 1641204120, 1641204150}, times)
+}
+
+func TestLoadParams(t *testing.T) {
+	now := time.Date(2022, 1, 3, 12, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		Start    string
+		End      string
+		Duration string
+		Expected logtime.Params
+	}{
+		"end": {
+			Start: "2022-01-03T10:00:00Z",
+			End:   "2022-01-03T11:00:00Z",
+			Expected: logtime.Params{
+				End:   time.Date(2022, 1, 3, 11, 0, 0, 0, time.UTC),
+				Start: time.Date(2022, 1, 3, 10, 0, 0, 0, time.UTC),
+			},
+		},
+		"duration": {
+			Start:    "2022-01-02 23:30",
+			Duration: "1h",
+			Expected: logtime.Params{
+				End:   time.Date(2022, 1, 3, 0, 30, 0, 0, time.UTC),
+				Start: time.Date(2022, 1, 2, 23, 30, 0, 0, time.UTC),
+			},
+		},
+		"time of today until now": {
+			Start: "10:15",
+			Expected: logtime.Params{
+				End:   now,
+				Start: time.Date(2022, 1, 3, 10, 15, 0, 0, time.UTC),
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			v := viper.New()
+			v.Set("start", test.Start)
+			v.Set("end", test.End)
+			v.Set("duration", test.Duration)
+
+			params, err := logtime.LoadParams(v, now)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.Expected, params)
+		})
+	}
+}
+
+func TestLoadParams_Invalid(t *testing.T) {
+	now := time.Date(2022, 1, 3, 12, 0, 0, 0, time.UTC)
+
+	tests := map[string]struct {
+		Start    string
+		End      string
+		Duration string
+		Expected string
+	}{
+		"missing start": {
+			Expected: "start is required",
+		},
+		"invalid start": {
+			Start:    "yesterday",
+			Expected: `invalid start "yesterday": must be a RFC3339 timestamp, like "2006-01-02 15:04" or like "15:04"`,
+		},
+		"end and duration": {
+			Start:    "10:00",
+			End:      "11:00",
+			Duration: "1h",
+			Expected: "end and duration cannot be used together",
+		},
+		"invalid duration": {
+			Start:    "10:00",
+			Duration: "an hour",
+			Expected: `invalid duration "an hour". must be like 1h30m`,
+		},
+		"end before start": {
+			Start:    "11:00",
+			End:      "10:00",
+			Expected: "end must be after start",
+		},
+		"future": {
+			Start:    "11:00",
+			Duration: "2h",
+			Expected: "cannot log time in the future",
+		},
+		"too long": {
+			Start:    "2022-01-01 10:00",
+			Duration: "25h",
+			Expected: "cannot log more than 24h0m0s at once",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			v := viper.New()
+			v.Set("start", test.Start)
+			v.Set("end", test.End)
+			v.Set("duration", test.Duration)
+
+			_, err := logtime.LoadParams(v, now)
+			assert.EqualError(t, err, test.Expected)
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package offline
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
@@ -27,6 +28,10 @@ func SaveHeartbeats(v *viper.Viper, heartbeats []heartbeat.Heartbeat, queueFilep
 		return fmt.Errorf("failed to load command parameters: %w", err)
 	}
 
+	if len(params.Heartbeat.ExtraHeartbeats) > 0 {
+		log.SetRedaction(paramscmd.LoadRedactionParams(v, params.Heartbeat.ExtraHeartbeats...))
+	}
+
 	setLogFields(&params)
 
 	log.Debugf("params: %s", params)
@@ -57,7 +62,7 @@ func SaveHeartbeats(v *viper.Viper, heartbeats []heartbeat.Heartbeat, queueFilep
 	sender := offline.Sender{}
 	handle := heartbeat.NewHandle(&sender, handleOpts...)
 
-	_, _ = handle(heartbeats)
+	_, _ = handle(context.Background(), heartbeats)
 
 	return nil
 }
@@ -182,9 +187,14 @@ func initHandleOptions(params paramscmd.Params) []heartbeat.HandleOption {
 		project.WithDetection(project.Config{
 			ShouldObfuscateProject: heartbeat.ShouldSanitize(
 				params.Heartbeat.Entity, params.Heartbeat.Sanitize.HideProjectNames),
+			DetectAppFolder:   params.Heartbeat.Project.DetectAppFolder,
+			GitRemote:         params.Heartbeat.Project.GitRemote,
 			MapPatterns:       params.Heartbeat.Project.MapPatterns,
+			MonorepoPatterns:  params.Heartbeat.Project.MonorepoPatterns,
 			SubmodulePatterns: params.Heartbeat.Project.DisableSubmodule,
+			SendCommit:        params.Heartbeat.Project.SendCommit,
 		}),
+		filter.WithProjectSettingsFiltering(),
 		project.WithFiltering(project.FilterConfig{
 			ExcludeUnknownProject: params.Heartbeat.Filter.ExcludeUnknownProject,
 		}),
@@ -0,0 +1,52 @@
+package offlinelist
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/wakatime/wakatime-cli/cmd/params"
+	"github.com/wakatime/wakatime-cli/pkg/exitcode"
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/offline"
+
+	"github.com/spf13/viper"
+)
+
+// Run executes the offline-list command. It prints the heartbeats in the
+// offline db as json array, oldest first.
+func Run(v *viper.Viper) (int, error) {
+	queueFilepath, err := offline.QueueFilepath()
+	if err != nil {
+		return exitcode.ErrGeneric, fmt.Errorf(
+			"failed to load offline queue filepath: %s",
+			err,
+		)
+	}
+
+	p, err := params.LoadOfflineParams(v)
+	if err != nil {
+		return exitcode.ErrGeneric, fmt.Errorf("failed to load offline parameters: %w", err)
+	}
+
+	if p.QueueFile != "" {
+		queueFilepath = p.QueueFile
+	}
+
+	heartbeats, err := offline.ReadHeartbeats(queueFilepath)
+	if err != nil {
+		return exitcode.ErrGeneric, fmt.Errorf("failed to read offline heartbeats: %w", err)
+	}
+
+	if heartbeats == nil {
+		heartbeats = []heartbeat.Heartbeat{}
+	}
+
+	data, err := json.MarshalIndent(heartbeats, "", "  ")
+	if err != nil {
+		return exitcode.ErrGeneric, fmt.Errorf("failed to json marshal offline heartbeats: %s", err)
+	}
+
+	fmt.Println(string(data))
+
+	return exitcode.Success, nil
+}
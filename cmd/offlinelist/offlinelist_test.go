@@ -0,0 +1,132 @@
+package offlinelist_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/cmd/offlinelist"
+	"github.com/wakatime/wakatime-cli/pkg/exitcode"
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestOfflineList(t *testing.T) {
+	// setup offline queue
+	f, err := os.CreateTemp(t.TempDir(), "")
+	require.NoError(t, err)
+
+	defer f.Close()
+
+	db, err := bolt.Open(f.Name(), 0600, nil)
+	require.NoError(t, err)
+
+	dataGo, err := os.ReadFile("../testdata/heartbeat_go.json")
+	require.NoError(t, err)
+
+	dataPy, err := os.ReadFile("../testdata/heartbeat_py.json")
+	require.NoError(t, err)
+
+	insertHeartbeatRecords(t, db, "heartbeats", []heartbeatRecord{
+		{
+			ID:        "1592868367.219124-file-coding-wakatime-cli-heartbeat-/tmp/main.go-true",
+			Heartbeat: string(dataGo),
+		},
+		{
+			ID:        "1592868386.079084-file-debugging-wakatime-summary-/tmp/main.py-false",
+			Heartbeat: string(dataPy),
+		},
+	})
+
+	db.Close()
+
+	v := viper.New()
+	v.Set("offline-list", true)
+	v.Set("offline-queue-file", f.Name())
+
+	code, output := runCaptured(t, v)
+
+	assert.Equal(t, exitcode.Success, code)
+
+	var heartbeats []heartbeat.Heartbeat
+
+	err = json.Unmarshal([]byte(output), &heartbeats)
+	require.NoError(t, err)
+
+	require.Len(t, heartbeats, 2)
+	assert.Equal(t, "/tmp/main.go", heartbeats[0].Entity)
+	assert.Equal(t, "/tmp/main.py", heartbeats[1].Entity)
+}
+
+func TestOfflineList_Empty(t *testing.T) {
+	v := viper.New()
+	v.Set("offline-list", true)
+	v.Set("offline-queue-file", filepath.Join(t.TempDir(), "missing.bdb"))
+
+	code, output := runCaptured(t, v)
+
+	assert.Equal(t, exitcode.Success, code)
+	assert.Equal(t, "[]\n", output)
+}
+
+func runCaptured(t *testing.T, v *viper.Viper) (int, string) {
+	stdout := os.Stdout // keep backup of the real stdout
+	r, w, _ := os.Pipe()
+	os.Stdout = w
+
+	code, err := offlinelist.Run(v)
+	require.NoError(t, err)
+
+	outC := make(chan string)
+	// copy the output in a separate goroutine so printing can't block indefinitely
+	go func() {
+		var buf bytes.Buffer
+		_, err := io.Copy(&buf, r)
+		require.NoError(t, err)
+		outC <- buf.String()
+	}()
+
+	w.Close()
+
+	os.Stdout = stdout
+
+	return code, <-outC
+}
+
+type heartbeatRecord struct {
+	ID        string
+	Heartbeat string
+}
+
+func insertHeartbeatRecords(t *testing.T, db *bolt.DB, bucket string, hh []heartbeatRecord) {
+	for _, h := range hh {
+		insertHeartbeatRecord(t, db, bucket, h)
+	}
+}
+
+func insertHeartbeatRecord(t *testing.T, db *bolt.DB, bucket string, h heartbeatRecord) {
+	t.Helper()
+
+	err := db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(bucket))
+		if err != nil {
+			return fmt.Errorf("failed to create bucket: %s", err)
+		}
+
+		err = b.Put([]byte(h.ID), []byte(h.Heartbeat))
+		if err != nil {
+			return fmt.Errorf("failed put hearbeat: %s", err)
+		}
+
+		return nil
+	})
+	require.NoError(t, err)
+}
@@ -1,6 +1,7 @@
 package offlinesync
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
@@ -14,8 +15,9 @@ import (
 	"github.com/spf13/viper"
 )
 
-// Run executes the sync-offline-activity command.
-func Run(v *viper.Viper) (int, error) {
+// Run executes the sync-offline-activity command. Syncing is aborted, once ctx
+// is done.
+func Run(ctx context.Context, v *viper.Viper) (int, error) {
 	queueFilepath, err := offline.QueueFilepath()
 	if err != nil {
 		return exitcode.ErrGeneric, fmt.Errorf(
@@ -24,7 +26,7 @@ func Run(v *viper.Viper) (int, error) {
 		)
 	}
 
-	err = SyncOfflineActivity(v, queueFilepath)
+	err = SyncOfflineActivity(ctx, v, queueFilepath)
 	if err != nil {
 		var errauth api.ErrAuth
 		if errors.As(err, &errauth) {
@@ -70,7 +72,7 @@ func Run(v *viper.Viper) (int, error) {
 
 // SyncOfflineActivity syncs offline activity by sending heartbeats
 // from the offline queue to the WakaTime API.
-func SyncOfflineActivity(v *viper.Viper, queueFilepath string) error {
+func SyncOfflineActivity(ctx context.Context, v *viper.Viper, queueFilepath string) error {
 	paramOffline, err := params.LoadOfflineParams(v)
 	if err != nil {
 		return fmt.Errorf("failed to load offline parameters: %w", err)
@@ -96,5 +98,5 @@ func SyncOfflineActivity(v *viper.Viper, queueFilepath string) error {
 
 	syncFn := offline.Sync(queueFilepath, paramOffline.SyncMax)
 
-	return syncFn(apiClient.SendHeartbeats)
+	return syncFn(ctx, apiClient.SendHeartbeats)
 }
@@ -1,6 +1,7 @@
 package offlinesync_test
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
@@ -99,7 +100,7 @@ func TestSyncOfflineActivity(t *testing.T) {
 	v.Set("sync-offline-activity", 100)
 	v.Set("plugin", plugin)
 
-	err = offlinesync.SyncOfflineActivity(v, f.Name())
+	err = offlinesync.SyncOfflineActivity(context.Background(), v, f.Name())
 	require.NoError(t, err)
 
 	assert.Eventually(t, func() bool { return numCalls == 1 }, time.Second, 50*time.Millisecond)
@@ -0,0 +1,213 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/wakatime/wakatime-cli/pkg/api"
+	"github.com/wakatime/wakatime-cli/pkg/exitcode"
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/offline"
+)
+
+const (
+	// TextFormat is the default output format, printing command output as is.
+	TextFormat = "text"
+	// JSONFormat prints a single json object per command run.
+	JSONFormat = "json"
+)
+
+const (
+	// StatusError is the status of a failed command run.
+	StatusError = "error"
+	// StatusSuccess is the status of a successful command run.
+	StatusSuccess = "success"
+)
+
+// Error classes, derived from the typed errors of the api and offline
+// packages.
+const (
+	ClassAPI            = "api"
+	ClassAuth           = "auth"
+	ClassBackoff        = "backoff"
+	ClassBadRequest     = "bad_request"
+	ClassConfigParse    = "config_parse"
+	ClassGeneric        = "generic"
+	ClassOfflineEnqueue = "offline_enqueue"
+)
+
+// Result is the json object written for a command run.
+type Result struct {
+	Status     string   `json:"status"`
+	ExitCode   int      `json:"exit_code"`
+	ErrorClass string   `json:"error_class,omitempty"`
+	Message    string   `json:"message,omitempty"`
+	Output     string   `json:"output,omitempty"`
+	Details    *Details `json:"details,omitempty"`
+}
+
+// Details contains details of a command run.
+type Details struct {
+	Heartbeats []HeartbeatErrors `json:"heartbeats,omitempty"`
+}
+
+// HeartbeatErrors contains the errors of a single heartbeat result from the api.
+type HeartbeatErrors struct {
+	Entity string   `json:"entity"`
+	Status int      `json:"status"`
+	Errors []string `json:"errors"`
+}
+
+// nolint:gochecknoglobals
+var recorded struct {
+	sync.Mutex
+	heartbeats []HeartbeatErrors
+}
+
+// ParseFormat parses an output format. Returns an error for unsupported formats.
+func ParseFormat(s string) (string, error) {
+	switch s {
+	case "", TextFormat:
+		return TextFormat, nil
+	case JSONFormat:
+		return JSONFormat, nil
+	default:
+		return "", fmt.Errorf("invalid output format %q. must be %q or %q", s, TextFormat, JSONFormat)
+	}
+}
+
+// RecordResults records the errors of heartbeat results, which are included
+// in the details of the command run result.
+func RecordResults(results []heartbeat.Result) {
+	recorded.Lock()
+	defer recorded.Unlock()
+
+	for _, result := range results {
+		if len(result.Errors) == 0 {
+			continue
+		}
+
+		recorded.heartbeats = append(recorded.heartbeats, HeartbeatErrors{
+			Entity: result.Heartbeat.Entity,
+			Status: result.Status,
+			Errors: result.Errors,
+		})
+	}
+}
+
+// NewResult creates the result of a command run from its exit code and error.
+// Recorded heartbeat result errors are included as details.
+func NewResult(exitCode int, err error, output string) Result {
+	result := Result{
+		Status:   StatusSuccess,
+		ExitCode: exitCode,
+		Output:   strings.TrimSuffix(output, "\n"),
+	}
+
+	if exitCode != exitcode.Success {
+		result.Status = StatusError
+		result.ErrorClass = Class(exitCode, err)
+	}
+
+	if err != nil {
+		result.Message = err.Error()
+	}
+
+	recorded.Lock()
+	defer recorded.Unlock()
+
+	if len(recorded.heartbeats) > 0 {
+		result.Details = &Details{Heartbeats: recorded.heartbeats}
+	}
+
+	return result
+}
+
+// Class returns the error class of a failed command run.
+func Class(exitCode int, err error) string {
+	var (
+		errapi        api.Err
+		errauth       api.ErrAuth
+		errbackoff    api.ErrBackoff
+		errbadRequest api.ErrBadRequest
+		errenqueue    offline.ErrOfflineEnqueue
+	)
+
+	switch {
+	case exitCode == exitcode.ErrConfigFileParse:
+		return ClassConfigParse
+	case errors.As(err, &errauth), exitCode == exitcode.ErrAuth:
+		return ClassAuth
+	case errors.As(err, &errenqueue):
+		return ClassOfflineEnqueue
+	case errors.As(err, &errbackoff):
+		return ClassBackoff
+	case errors.As(err, &errbadRequest):
+		return ClassBadRequest
+	case errors.As(err, &errapi), exitCode == exitcode.ErrAPI:
+		return ClassAPI
+	default:
+		return ClassGeneric
+	}
+}
+
+// Write writes the result as json object to stdout on success, or else to
+// stderr.
+func Write(result Result) error {
+	w := os.Stdout
+	if result.Status != StatusSuccess {
+		w = os.Stderr
+	}
+
+	return WriteTo(w, result)
+}
+
+// WriteTo writes the result as single line json object to w.
+func WriteTo(w io.Writer, result Result) error {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return fmt.Errorf("failed to json marshal result: %s", err)
+	}
+
+	if _, err := fmt.Fprintln(w, string(data)); err != nil {
+		return fmt.Errorf("failed to write result: %s", err)
+	}
+
+	return nil
+}
+
+// CaptureStdout redirects stdout, until the returned restore function is
+// called. Restore returns everything written to stdout in between.
+func CaptureStdout() (restore func() string, err error) {
+	r, w, err := os.Pipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create pipe: %s", err)
+	}
+
+	stdout := os.Stdout
+	os.Stdout = w
+
+	captured := make(chan string)
+
+	go func() {
+		var buf bytes.Buffer
+
+		_, _ = io.Copy(&buf, r)
+
+		captured <- buf.String()
+	}()
+
+	return func() string {
+		_ = w.Close()
+
+		os.Stdout = stdout
+
+		return <-captured
+	}, nil
+}
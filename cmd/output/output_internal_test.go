@@ -0,0 +1,41 @@
+package output
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/exitcode"
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordResults(t *testing.T) {
+	t.Cleanup(func() {
+		recorded.heartbeats = nil
+	})
+
+	RecordResults([]heartbeat.Result{
+		{
+			Status:    http.StatusCreated,
+			Heartbeat: heartbeat.Heartbeat{Entity: "/tmp/main.go"},
+		},
+		{
+			Errors:    []string{"Can not log time before user was created."},
+			Status:    http.StatusBadRequest,
+			Heartbeat: heartbeat.Heartbeat{Entity: "/tmp/main.py"},
+		},
+	})
+
+	result := NewResult(exitcode.Success, nil, "")
+
+	assert.Equal(t, &Details{
+		Heartbeats: []HeartbeatErrors{
+			{
+				Entity: "/tmp/main.py",
+				Status: http.StatusBadRequest,
+				Errors: []string{"Can not log time before user was created."},
+			},
+		},
+	}, result.Details)
+}
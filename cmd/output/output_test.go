@@ -0,0 +1,144 @@
+package output_test
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/cmd/output"
+	"github.com/wakatime/wakatime-cli/pkg/api"
+	"github.com/wakatime/wakatime-cli/pkg/exitcode"
+	"github.com/wakatime/wakatime-cli/pkg/offline"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFormat(t *testing.T) {
+	tests := map[string]string{
+		"":     output.TextFormat,
+		"text": output.TextFormat,
+		"json": output.JSONFormat,
+	}
+
+	for value, expected := range tests {
+		t.Run(value, func(t *testing.T) {
+			format, err := output.ParseFormat(value)
+			require.NoError(t, err)
+
+			assert.Equal(t, expected, format)
+		})
+	}
+}
+
+func TestParseFormat_Invalid(t *testing.T) {
+	_, err := output.ParseFormat("yaml")
+	require.Error(t, err)
+
+	assert.Equal(t, `invalid output format "yaml". must be "text" or "json"`, err.Error())
+}
+
+func TestClass(t *testing.T) {
+	tests := map[string]struct {
+		ExitCode int
+		Err      error
+		Expected string
+	}{
+		"api": {
+			ExitCode: exitcode.ErrAPI,
+			Err:      fmt.Errorf("sending heartbeat(s) later due to api error: %w", api.Err("fail")),
+			Expected: output.ClassAPI,
+		},
+		"auth": {
+			ExitCode: exitcode.ErrAuth,
+			Err:      fmt.Errorf("invalid api key: %w", api.ErrAuth("fail")),
+			Expected: output.ClassAuth,
+		},
+		"backoff": {
+			ExitCode: exitcode.ErrAPI,
+			Err:      fmt.Errorf("sending heartbeat(s) later due to api error: %w", api.ErrBackoff("fail")),
+			Expected: output.ClassBackoff,
+		},
+		"bad request": {
+			ExitCode: exitcode.ErrGeneric,
+			Err:      fmt.Errorf("bad request: %w", api.ErrBadRequest("fail")),
+			Expected: output.ClassBadRequest,
+		},
+		"config parse": {
+			ExitCode: exitcode.ErrConfigFileParse,
+			Err:      errors.New("failed to load configuration file"),
+			Expected: output.ClassConfigParse,
+		},
+		"offline enqueue": {
+			ExitCode: exitcode.ErrGeneric,
+			Err:      fmt.Errorf("sending heartbeat(s) failed: %w", offline.ErrOfflineEnqueue("fail")),
+			Expected: output.ClassOfflineEnqueue,
+		},
+		"generic": {
+			ExitCode: exitcode.ErrGeneric,
+			Err:      errors.New("fail"),
+			Expected: output.ClassGeneric,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, output.Class(test.ExitCode, test.Err))
+		})
+	}
+}
+
+func TestNewResult(t *testing.T) {
+	result := output.NewResult(exitcode.Success, nil, "3 hrs 23 mins\n")
+
+	assert.Equal(t, output.Result{
+		Status:   output.StatusSuccess,
+		ExitCode: exitcode.Success,
+		Output:   "3 hrs 23 mins",
+	}, result)
+}
+
+func TestNewResult_Err(t *testing.T) {
+	result := output.NewResult(exitcode.ErrAuth, api.ErrAuth("invalid api key"), "")
+
+	assert.Equal(t, output.Result{
+		Status:     output.StatusError,
+		ExitCode:   exitcode.ErrAuth,
+		ErrorClass: output.ClassAuth,
+		Message:    "invalid api key",
+	}, result)
+}
+
+func TestWriteTo(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := output.WriteTo(&buf, output.Result{
+		Status:     output.StatusError,
+		ExitCode:   exitcode.ErrAPI,
+		ErrorClass: output.ClassBackoff,
+		Message:    "won't send heartbeat due to backoff",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(
+		t,
+		`{"status":"error","exit_code":102,"error_class":"backoff","message":"won't send heartbeat due to backoff"}`+"\n",
+		buf.String(),
+	)
+}
+
+func TestCaptureStdout(t *testing.T) {
+	stdout := os.Stdout
+
+	restore, err := output.CaptureStdout()
+	require.NoError(t, err)
+
+	fmt.Println("captured")
+
+	captured := restore()
+
+	assert.Equal(t, "captured\n", captured)
+	assert.Equal(t, stdout, os.Stdout)
+}
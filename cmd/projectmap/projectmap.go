@@ -0,0 +1,74 @@
+package projectmap
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/wakatime/wakatime-cli/cmd/params"
+	"github.com/wakatime/wakatime-cli/pkg/exitcode"
+	"github.com/wakatime/wakatime-cli/pkg/project"
+	"github.com/wakatime/wakatime-cli/pkg/vipertools"
+
+	"github.com/mitchellh/go-homedir"
+	"github.com/spf13/viper"
+)
+
+// Run executes the projectmap-test command, printing which [projectmap] rule
+// matches the given path and the resulting project name.
+func Run(v *viper.Viper) (int, error) {
+	output, err := Test(v)
+	if err != nil {
+		return exitcode.ErrGeneric, fmt.Errorf("failed to test projectmap: %w", err)
+	}
+
+	fmt.Println(output)
+
+	return exitcode.Success, nil
+}
+
+// Test returns which [projectmap] rule matches the path passed by
+// --projectmap-test and the resulting project name.
+func Test(v *viper.Viper) (string, error) {
+	fp, err := loadPath(v)
+	if err != nil {
+		return "", err
+	}
+
+	patterns, err := params.LoadProjectMapPatterns(v)
+	if err != nil {
+		return "", fmt.Errorf("failed to load projectmap: %w", err)
+	}
+
+	match, ok := project.MatchMapPatterns(fp, patterns)
+	if !ok {
+		return fmt.Sprintf("no rule of %d matched %q", len(patterns), fp), nil
+	}
+
+	return fmt.Sprintf(
+		"rule %d: %s\nproject: %s",
+		match.Index+1,
+		match.Pattern,
+		match.Project,
+	), nil
+}
+
+func loadPath(v *viper.Viper) (string, error) {
+	fp := strings.TrimSpace(vipertools.GetString(v, "projectmap-test"))
+	if fp == "" {
+		return "", errors.New("path must not be empty")
+	}
+
+	fp, err := homedir.Expand(fp)
+	if err != nil {
+		return "", fmt.Errorf("failed to expand path %q: %s", fp, err)
+	}
+
+	fp, err = filepath.Abs(fp)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve absolute path of %q: %s", fp, err)
+	}
+
+	return fp, nil
+}
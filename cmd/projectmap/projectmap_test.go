@@ -0,0 +1,64 @@
+package projectmap_test
+
+import (
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/cmd/projectmap"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTest(t *testing.T) {
+	tests := map[string]struct {
+		Path     string
+		Expected string
+	}{
+		"longest match": {
+			Path:     "/home/user/projects/wakatime/main.go",
+			Expected: "rule 2: /home/user/projects/(\\w+)/ = project-{0} [priority=0]\nproject: project-wakatime",
+		},
+		"priority": {
+			Path:     "/home/user/work/projects/wakatime/main.go",
+			Expected: "rule 3: /home/user/work/ = work [priority=1]\nproject: work",
+		},
+		"fallback": {
+			Path:     "/tmp/main.go",
+			Expected: "rule 4: .* = other [priority=-1]\nproject: other",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			v := viper.New()
+			v.Set("config", "testdata/wakatime.cfg")
+			v.Set("projectmap-test", test.Path)
+
+			output, err := projectmap.Test(v)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.Expected, output)
+		})
+	}
+}
+
+func TestTest_NoMatch(t *testing.T) {
+	v := viper.New()
+	v.Set("config", "testdata/missing.cfg")
+	v.Set("projectmap.^/home/user/projects/", "projects")
+	v.Set("projectmap-test", "/tmp/main.go")
+
+	output, err := projectmap.Test(v)
+	require.NoError(t, err)
+
+	assert.Equal(t, `no rule of 1 matched "/tmp/main.go"`, output)
+}
+
+func TestTest_EmptyPath(t *testing.T) {
+	v := viper.New()
+	v.Set("projectmap-test", " ")
+
+	_, err := projectmap.Test(v)
+	require.Error(t, err)
+}
@@ -1,6 +1,8 @@
 package cmd_test
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -13,6 +15,9 @@ import (
 	"time"
 
 	"github.com/wakatime/wakatime-cli/cmd"
+	"github.com/wakatime/wakatime-cli/pkg/api"
+	"github.com/wakatime/wakatime-cli/pkg/exitcode"
+	"github.com/wakatime/wakatime-cli/pkg/log"
 	"github.com/wakatime/wakatime-cli/pkg/version"
 
 	"github.com/spf13/viper"
@@ -208,7 +213,7 @@ func TestRunCmdWithOfflineSync(t *testing.T) {
 		v.SetDefault("sync-offline-activity", 24)
 		v.Set("plugin", "vim")
 
-		cmd.RunCmdWithOfflineSync(v, false, func(v *viper.Viper) (int, error) {
+		cmd.RunCmdWithOfflineSync(v, false, func(_ context.Context, v *viper.Viper) (int, error) {
 			return 0, nil
 		})
 
@@ -311,6 +316,47 @@ func TestRunCmdWithOfflineSync(t *testing.T) {
 	assert.Eventually(t, func() bool { return numCalls == 1 }, time.Second, 50*time.Millisecond)
 }
 
+func TestRunCmd_JSONOutput(t *testing.T) {
+	// this is exclusively run in subprocess
+	if os.Getenv("TEST_RUN") == "1" {
+		log.SetOutput(io.Discard)
+
+		v := viper.New()
+		v.Set("output", "json")
+
+		cmd.RunCmd(v, false, func(v *viper.Viper) (int, error) {
+			fmt.Println("not printed as is")
+
+			return exitcode.ErrAuth, fmt.Errorf("invalid api key: %w", api.ErrAuth("unauthorized"))
+		})
+
+		return
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	// run command in another runner, to effectively test os.Exit()
+	cmd := exec.Command(os.Args[0], "-test.run=TestRunCmd_JSONOutput") // nolint:gosec
+	cmd.Env = append(os.Environ(), "TEST_RUN=1")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+
+	var exitErr *exec.ExitError
+	require.True(t, errors.As(err, &exitErr))
+
+	assert.Equal(t, exitcode.ErrAuth, exitErr.ExitCode())
+	assert.Empty(t, stdout.String())
+	assert.JSONEq(t, `{
+		"status": "error",
+		"exit_code": 104,
+		"error_class": "auth",
+		"message": "invalid api key: unauthorized",
+		"output": "not printed as is"
+	}`, stderr.String())
+}
+
 func jsonEscape(t *testing.T, i string) string {
 	b, err := json.Marshal(i)
 	require.NoError(t, err)
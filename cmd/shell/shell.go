@@ -0,0 +1,70 @@
+package shell
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	cmdheartbeat "github.com/wakatime/wakatime-cli/cmd/heartbeat"
+	"github.com/wakatime/wakatime-cli/cmd/params"
+	"github.com/wakatime/wakatime-cli/pkg/exitcode"
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/shell"
+	"github.com/wakatime/wakatime-cli/pkg/vipertools"
+
+	"github.com/spf13/viper"
+)
+
+// RunHook executes the shell-hook command. It prints the hook snippet of the
+// passed in shell.
+func RunHook(v *viper.Viper) (int, error) {
+	executable, err := os.Executable()
+	if err != nil {
+		return exitcode.ErrGeneric, fmt.Errorf("failed to resolve wakatime-cli executable: %s", err)
+	}
+
+	hook, err := shell.Hook(vipertools.GetString(v, "shell-hook"), executable)
+	if err != nil {
+		return exitcode.ErrGeneric, err
+	}
+
+	fmt.Print(hook)
+
+	return exitcode.Success, nil
+}
+
+// Run executes the shell-command command. It sends an app heartbeat for the
+// program of a command line run in a terminal. Project and branch are detected
+// from the project folder, defaulting to the current working directory. The
+// category is inferred from the command line, unless passed explicitly.
+func Run(ctx context.Context, v *viper.Viper) (int, error) {
+	commandLine := vipertools.GetString(v, "shell-command")
+
+	program := shell.Program(commandLine)
+	if program == "" {
+		log.Debugln("skip shell command without program")
+
+		return exitcode.Success, nil
+	}
+
+	v.Set("entity", program)
+	v.Set("entity-type", heartbeat.AppType.String())
+
+	if vipertools.GetString(v, "category") == "" {
+		if category, ok := shell.Category(commandLine, params.LoadShellParams(v).Rules); ok {
+			v.Set("category", category.String())
+		}
+	}
+
+	if vipertools.GetString(v, "project-folder") == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			log.Warnf("failed to get working directory: %s", err)
+		} else {
+			v.Set("project-folder", wd)
+		}
+	}
+
+	return cmdheartbeat.Run(ctx, v)
+}
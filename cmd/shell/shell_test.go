@@ -0,0 +1,126 @@
+package shell_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	cmdshell "github.com/wakatime/wakatime-cli/cmd/shell"
+	"github.com/wakatime/wakatime-cli/pkg/exitcode"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type heartbeat struct {
+	Category string `json:"category"`
+	Entity   string `json:"entity"`
+	Project  string `json:"project"`
+	Type     string `json:"type"`
+}
+
+func TestRun(t *testing.T) {
+	tests := map[string]struct {
+		CommandLine string
+		Category    string
+		Config      map[string]string
+		Expected    heartbeat
+	}{
+		"inferred category": {
+			CommandLine: "CGO_ENABLED=0 go test ./...",
+			Expected: heartbeat{
+				Category: "running tests",
+				Entity:   "go",
+				Project:  "shell-project",
+				Type:     "app",
+			},
+		},
+		"explicit category": {
+			CommandLine: "go test ./...",
+			Category:    "debugging",
+			Expected: heartbeat{
+				Category: "debugging",
+				Entity:   "go",
+				Project:  "shell-project",
+				Type:     "app",
+			},
+		},
+		"configured category": {
+			CommandLine: "kubectl apply -f deployment.yaml",
+			Config:      map[string]string{"kubectl apply": "building"},
+			Expected: heartbeat{
+				Category: "building",
+				Entity:   "kubectl",
+				Project:  "shell-project",
+				Type:     "app",
+			},
+		},
+		"default category": {
+			CommandLine: "/usr/bin/kubectl get pods",
+			Expected: heartbeat{
+				Category: "coding",
+				Entity:   "kubectl",
+				Project:  "shell-project",
+				Type:     "app",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var received []heartbeat
+
+			router := http.NewServeMux()
+			router.HandleFunc("/users/current/heartbeats.bulk", func(w http.ResponseWriter, req *http.Request) {
+				body, err := io.ReadAll(req.Body)
+				require.NoError(t, err)
+
+				err = json.Unmarshal(body, &received)
+				require.NoError(t, err)
+
+				w.WriteHeader(http.StatusCreated)
+				_, _ = fmt.Fprint(w, `{"responses": [[{"data": {}}, 201]]}`)
+			})
+
+			apiServer := httptest.NewServer(router)
+			defer apiServer.Close()
+
+			v := viper.New()
+			v.Set("api-url", apiServer.URL)
+			v.Set("key", "00000000-0000-4000-8000-000000000000")
+			v.Set("offline-queue-file", filepath.Join(t.TempDir(), "offline.bdb"))
+			v.Set("project-folder", "testdata/project")
+			v.Set("shell-command", test.CommandLine)
+
+			if test.Category != "" {
+				v.Set("category", test.Category)
+			}
+
+			for command, category := range test.Config {
+				v.Set("shell_categories."+command, category)
+			}
+
+			code, err := cmdshell.Run(context.Background(), v)
+			require.NoError(t, err)
+
+			assert.Equal(t, exitcode.Success, code)
+			assert.Equal(t, []heartbeat{test.Expected}, received)
+		})
+	}
+}
+
+func TestRun_NoProgram(t *testing.T) {
+	v := viper.New()
+	v.Set("shell-command", "FOO=bar")
+
+	code, err := cmdshell.Run(context.Background(), v)
+	require.NoError(t, err)
+
+	assert.Equal(t, exitcode.Success, code)
+}
@@ -0,0 +1,265 @@
+package stdio
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/wakatime/wakatime-cli/cmd/configread"
+	"github.com/wakatime/wakatime-cli/cmd/configwrite"
+	cmdheartbeat "github.com/wakatime/wakatime-cli/cmd/heartbeat"
+	"github.com/wakatime/wakatime-cli/cmd/offlinesync"
+	"github.com/wakatime/wakatime-cli/cmd/params"
+	"github.com/wakatime/wakatime-cli/cmd/today"
+	"github.com/wakatime/wakatime-cli/cmd/todaygoal"
+	"github.com/wakatime/wakatime-cli/pkg/api"
+	"github.com/wakatime/wakatime-cli/pkg/exitcode"
+	"github.com/wakatime/wakatime-cli/pkg/ini"
+	"github.com/wakatime/wakatime-cli/pkg/jsonrpc"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/offline"
+	"github.com/wakatime/wakatime-cli/pkg/vipertools"
+
+	"github.com/spf13/viper"
+)
+
+// unsupportedKeys are command line flags, which cannot be passed as params.
+// nolint:gochecknoglobals
+var unsupportedKeys = map[string]bool{
+	"extra-heartbeats": true,
+	"stdio":            true,
+}
+
+// ErrorData is attached to errors of failed commands.
+type ErrorData struct {
+	ExitCode int `json:"exitcode"`
+}
+
+// method executes a command with the viper instance of a single call.
+type method func(h *handler, v *viper.Viper) (interface{}, error)
+
+// methods maps JSON-RPC method names to commands. Names match the command
+// line flags of the commands.
+// nolint:gochecknoglobals
+var methods = map[string]method{
+	"config-read":   configRead,
+	"config-write":  configWrite,
+	"heartbeat":     sendHeartbeat,
+	"offline-count": offlineCount,
+	"today":         todaySummary,
+	"today-goal":    todayGoal,
+}
+
+// Run executes the stdio command. It reads newline delimited JSON-RPC 2.0
+// requests from stdin and writes responses to stdout until stdin is closed.
+func Run(v *viper.Viper, newFlags vipertools.NewFlagsFunc) (int, error) {
+	// stdout is reserved for responses
+	if log.Output() == os.Stdout {
+		log.SetOutput(os.Stderr)
+		log.SetJww(v.GetBool("verbose"), os.Stderr)
+	}
+
+	if err := Serve(v, newFlags, os.Stdin, os.Stdout); err != nil {
+		return exitcode.ErrGeneric, fmt.Errorf("stdio mode failed: %s", err)
+	}
+
+	return exitcode.Success, nil
+}
+
+// Serve handles JSON-RPC requests read from r and writes responses to w.
+// Params of each request are command line flag names with their values, which
+// are applied on top of the flags wakatime-cli was started with.
+func Serve(v *viper.Viper, newFlags vipertools.NewFlagsFunc, r io.Reader, w io.Writer) error {
+	h := &handler{
+		newFlags: newFlags,
+		v:        v,
+	}
+
+	return jsonrpc.Serve(r, w, h.handle)
+}
+
+type handler struct {
+	newFlags vipertools.NewFlagsFunc
+	v        *viper.Viper
+}
+
+func (h *handler) handle(name string, raw json.RawMessage) (interface{}, error) {
+	m, ok := methods[name]
+	if !ok {
+		return nil, &jsonrpc.Error{
+			Code:    jsonrpc.CodeMethodNotFound,
+			Message: fmt.Sprintf("method %q not found", name),
+		}
+	}
+
+	log.Debugf("stdio method: %s", name)
+
+	v, err := h.callViper(raw)
+	if err != nil {
+		return nil, &jsonrpc.Error{
+			Code:    jsonrpc.CodeInvalidParams,
+			Message: err.Error(),
+		}
+	}
+
+	// entities and names hidden in one call must not carry over to the next
+	log.SetRedaction(params.LoadRedactionParams(v))
+	defer log.SetRedaction(params.LoadRedactionParams(h.v))
+
+	return m(h, v)
+}
+
+// callViper creates a viper instance for a single call. Config values and
+// command line flags are taken over from the stdio process, params are
+// applied as if passed on the command line.
+func (h *handler) callViper(raw json.RawMessage) (*viper.Viper, error) {
+	var values map[string]interface{}
+
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &values); err != nil {
+			return nil, fmt.Errorf("params must be an object of command line flags: %s", err)
+		}
+	}
+
+	v, flags := h.newFlags()
+
+	for _, key := range h.v.AllKeys() {
+		if h.v.IsSet(key) && !unsupportedKeys[key] {
+			v.Set(key, h.v.Get(key))
+		}
+	}
+
+	for key, value := range values {
+		if flags.Lookup(key) == nil || unsupportedKeys[key] {
+			return nil, fmt.Errorf("unsupported param %q", key)
+		}
+
+		v.Set(key, value)
+	}
+
+	return v, nil
+}
+
+func configRead(_ *handler, v *viper.Viper) (interface{}, error) {
+	value, err := configread.Read(v)
+	if err != nil {
+		return nil, commandError(exitcode.ErrConfigFileRead, fmt.Errorf("failed to read in config: %s", err))
+	}
+
+	return value, nil
+}
+
+func configWrite(h *handler, v *viper.Viper) (interface{}, error) {
+	w, err := ini.NewIniWriter(v, ini.FilePath)
+	if err != nil {
+		return nil, commandError(exitcode.ErrConfigFileParse, fmt.Errorf("failed to parse config file: %s", err))
+	}
+
+	if err := configwrite.Write(v, w); err != nil {
+		return nil, commandError(exitcode.ErrGeneric, fmt.Errorf("failed to write to config file: %s", err))
+	}
+
+	// following calls must see the written values
+	if err := ini.ReadInConfig(h.v, w.ConfigFilepath); err != nil {
+		log.Warnf("failed to reload config file: %s", err)
+	}
+
+	return nil, nil
+}
+
+func sendHeartbeat(_ *handler, v *viper.Viper) (interface{}, error) {
+	queueFilepath, err := offline.QueueFilepath()
+	if err != nil {
+		log.Warnf("failed to load offline queue filepath: %s", err)
+	}
+
+	ctx := context.Background()
+
+	if deadline := params.LoadInvocationParams(v).Deadline; deadline > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, deadline)
+		defer cancel()
+	}
+
+	if err := cmdheartbeat.SendHeartbeats(ctx, v, queueFilepath); err != nil {
+		return nil, commandError(apiExitCode(err, exitcode.ErrGeneric), fmt.Errorf("sending heartbeat(s) failed: %w", err))
+	}
+
+	if err := offlinesync.SyncOfflineActivity(ctx, v, queueFilepath); err != nil {
+		var errSyncDisabled offlinesync.ErrSyncDisabled
+		if !errors.As(err, &errSyncDisabled) {
+			log.Warnf("failed to sync offline activity: %s", err)
+		}
+	}
+
+	return nil, nil
+}
+
+func offlineCount(_ *handler, v *viper.Viper) (interface{}, error) {
+	queueFilepath, err := offline.QueueFilepath()
+	if err != nil {
+		return nil, commandError(exitcode.ErrGeneric, fmt.Errorf("failed to load offline queue filepath: %s", err))
+	}
+
+	p, err := params.LoadOfflineParams(v)
+	if err != nil {
+		return nil, commandError(exitcode.ErrGeneric, fmt.Errorf("failed to load offline parameters: %w", err))
+	}
+
+	if p.QueueFile != "" {
+		queueFilepath = p.QueueFile
+	}
+
+	count, err := offline.CountHeartbeats(queueFilepath)
+	if err != nil {
+		return nil, commandError(exitcode.ErrGeneric, fmt.Errorf("failed to count offline heartbeats: %w", err))
+	}
+
+	return count, nil
+}
+
+func todaySummary(_ *handler, v *viper.Viper) (interface{}, error) {
+	output, err := today.Today(v)
+	if err != nil {
+		return nil, commandError(apiExitCode(err, exitcode.ErrGeneric), fmt.Errorf("today fetch failed: %w", err))
+	}
+
+	return output, nil
+}
+
+func todayGoal(_ *handler, v *viper.Viper) (interface{}, error) {
+	output, err := todaygoal.Goal(v)
+	if err != nil {
+		return nil, commandError(apiExitCode(err, exitcode.ErrGeneric), fmt.Errorf("today goal fetch failed: %w", err))
+	}
+
+	return output, nil
+}
+
+// apiExitCode returns the exit code for api errors, like the command line
+// does, or fallback for any other error.
+func apiExitCode(err error, fallback int) int {
+	var errauth api.ErrAuth
+	if errors.As(err, &errauth) {
+		return exitcode.ErrAuth
+	}
+
+	var errapi api.Err
+	if errors.As(err, &errapi) {
+		return exitcode.ErrAPI
+	}
+
+	return fallback
+}
+
+func commandError(code int, err error) *jsonrpc.Error {
+	return &jsonrpc.Error{
+		Code:    jsonrpc.CodeCommandFailed,
+		Data:    ErrorData{ExitCode: code},
+		Message: err.Error(),
+	}
+}
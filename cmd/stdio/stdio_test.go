@@ -0,0 +1,171 @@
+package stdio_test
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/cmd/stdio"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServe(t *testing.T) {
+	var numCalls int
+
+	router := http.NewServeMux()
+	router.HandleFunc("/users/current/heartbeats.bulk", func(w http.ResponseWriter, req *http.Request) {
+		numCalls++
+
+		w.WriteHeader(http.StatusCreated)
+		_, _ = fmt.Fprint(w, `{"responses": [[{"data": {}}, 201]]}`)
+	})
+	router.HandleFunc("/users/current/statusbar/today", func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+
+	apiServer := httptest.NewServer(router)
+	defer apiServer.Close()
+
+	tmpDir := t.TempDir()
+	configFile := filepath.Join(tmpDir, "wakatime.cfg")
+
+	err := os.WriteFile(configFile, []byte("[settings]\ndebug = false\n"), 0600)
+	require.NoError(t, err)
+
+	v, flags := newFlags()
+	require.NoError(t, flags.Parse([]string{
+		"--api-url", apiServer.URL,
+		"--config", configFile,
+		"--key", "00000000-0000-4000-8000-000000000000",
+		"--offline-queue-file", filepath.Join(tmpDir, "offline.bdb"),
+		"--stdio",
+	}))
+
+	input := strings.Join([]string{
+		`{"jsonrpc": "2.0", "id": 1, "method": "config-write", "params": {"config-write": {"debug": "true"}}}`,
+		`{"jsonrpc": "2.0", "id": 2, "method": "config-read", "params": {"config-read": "debug"}}`,
+		`{"jsonrpc": "2.0", "id": 3, "method": "heartbeat", "params": {"entity": "testdata/main.go", "write": true}}`,
+		`{"jsonrpc": "2.0", "id": 4, "method": "offline-count"}`,
+		`{"jsonrpc": "2.0", "id": 5, "method": "today"}`,
+		`{"jsonrpc": "2.0", "id": 6, "method": "heartbeat", "params": {"extra-heartbeats": true}}`,
+		`{"jsonrpc": "2.0", "id": 7, "method": "unknown"}`,
+	}, "\n")
+
+	var output bytes.Buffer
+
+	err = stdio.Serve(v, newFlags, strings.NewReader(input), &output)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	require.Len(t, lines, 7)
+
+	assert.JSONEq(t, `{"jsonrpc": "2.0", "id": 1, "result": null}`, lines[0])
+	assert.JSONEq(t, `{"jsonrpc": "2.0", "id": 2, "result": "true"}`, lines[1])
+	assert.JSONEq(t, `{"jsonrpc": "2.0", "id": 3, "result": null}`, lines[2])
+	assert.JSONEq(t, `{"jsonrpc": "2.0", "id": 4, "result": 0}`, lines[3])
+	assert.Contains(t, lines[4], `"code":-32000`)
+	assert.Contains(t, lines[4], `"data":{"exitcode":104}`)
+	assert.JSONEq(t, `{
+		"jsonrpc": "2.0",
+		"id": 6,
+		"error": {"code": -32602, "message": "unsupported param \"extra-heartbeats\""}
+	}`, lines[5])
+	assert.JSONEq(t, `{
+		"jsonrpc": "2.0",
+		"id": 7,
+		"error": {"code": -32601, "message": "method \"unknown\" not found"}
+	}`, lines[6])
+
+	assert.Equal(t, 1, numCalls)
+
+	data, err := os.ReadFile(configFile)
+	require.NoError(t, err)
+
+	assert.Contains(t, string(data), "debug = true")
+}
+
+func TestServe_Redaction(t *testing.T) {
+	router := http.NewServeMux()
+	router.HandleFunc("/users/current/heartbeats.bulk", func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+		_, _ = fmt.Fprint(w, `{"responses": [[{"data": {}}, 201]]}`)
+	})
+
+	apiServer := httptest.NewServer(router)
+	defer apiServer.Close()
+
+	tmpDir := t.TempDir()
+
+	output := log.Output()
+	defer log.SetOutput(output)
+
+	var buf bytes.Buffer
+
+	log.SetOutput(&buf)
+	log.SetVerbose(true)
+
+	defer log.SetVerbose(false)
+
+	defer log.SetRedaction(log.Redaction{})
+
+	v, flags := newFlags()
+	require.NoError(t, flags.Parse([]string{
+		"--api-url", apiServer.URL,
+		"--config", filepath.Join(tmpDir, "wakatime.cfg"),
+		"--key", "00000000-0000-4000-8000-000000000000",
+		"--offline-queue-file", filepath.Join(tmpDir, "offline.bdb"),
+		"--stdio",
+	}))
+
+	// hidden in a previous call
+	log.AddRedactedNames("secret-project")
+
+	input := `{"jsonrpc": "2.0", "id": 1, "method": "heartbeat", ` +
+		`"params": {"entity": "testdata/main.go", "hide-file-names": "true"}}`
+
+	var response bytes.Buffer
+
+	err := stdio.Serve(v, newFlags, strings.NewReader(input), &response)
+	require.NoError(t, err)
+
+	assert.JSONEq(t, `{"jsonrpc": "2.0", "id": 1, "result": null}`, strings.TrimSpace(response.String()))
+
+	assert.Contains(t, buf.String(), "params: ")
+	assert.NotContains(t, buf.String(), "testdata/main.go")
+	assert.Equal(t, "secret-project", log.Redact("secret-project"))
+}
+
+// newFlags defines the subset of command line flags used in tests.
+func newFlags() (*viper.Viper, *pflag.FlagSet) {
+	flags := pflag.NewFlagSet("wakatime-cli", pflag.ContinueOnError)
+
+	for _, name := range []string{
+		"api-url", "config", "config-read", "entity", "hide-file-names", "key", "offline-queue-file",
+		"plugin", "sync-offline-activity", "today-goal",
+	} {
+		flags.String(name, "", "")
+	}
+
+	flags.String("config-section", "settings", "")
+	flags.StringToString("config-write", nil, "")
+	flags.Bool("extra-heartbeats", false, "")
+	flags.Bool("stdio", false, "")
+	flags.Bool("write", false, "")
+
+	v := viper.New()
+	if err := v.BindPFlags(flags); err != nil {
+		panic(err)
+	}
+
+	return v, flags
+}
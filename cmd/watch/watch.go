@@ -0,0 +1,210 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	cmdapi "github.com/wakatime/wakatime-cli/cmd/api"
+	cmdheartbeat "github.com/wakatime/wakatime-cli/cmd/heartbeat"
+	"github.com/wakatime/wakatime-cli/cmd/offlinesync"
+	"github.com/wakatime/wakatime-cli/cmd/params"
+	"github.com/wakatime/wakatime-cli/pkg/exitcode"
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/offline"
+	"github.com/wakatime/wakatime-cli/pkg/vipertools"
+	"github.com/wakatime/wakatime-cli/pkg/watch"
+
+	"github.com/spf13/viper"
+)
+
+// ignoredKeys are command line flags of the watch process, which do not apply
+// to the heartbeats of modified files.
+// nolint:gochecknoglobals
+var ignoredKeys = map[string]bool{
+	"cursorpos":        true,
+	"entity":           true,
+	"entity-type":      true,
+	"extra-heartbeats": true,
+	"file":             true,
+	"lineno":           true,
+	"time":             true,
+	"watch":            true,
+	"write":            true,
+}
+
+// Run executes the watch command.
+func Run(v *viper.Viper, newFlags vipertools.NewFlagsFunc) (int, error) {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := Watch(ctx, v, newFlags); err != nil {
+		return exitcode.ErrGeneric, fmt.Errorf("watch failed: %w", err)
+	}
+
+	log.Debugln("watch stopped")
+
+	return exitcode.Success, nil
+}
+
+// Watch watches the configured directories and sends a write heartbeat for
+// each modified file until ctx is done. Heartbeats run through the same
+// processing pipeline as heartbeats passed on the command line, so filters
+// and detection apply.
+func Watch(ctx context.Context, v *viper.Viper, newFlags vipertools.NewFlagsFunc) error {
+	paramWatch, err := params.LoadWatchParams(v)
+	if err != nil {
+		return fmt.Errorf("failed to load watch parameters: %w", err)
+	}
+
+	if len(paramWatch.Dirs) == 0 {
+		return errors.New("no directories to watch. set --watch-dir or watch_dirs in config file")
+	}
+
+	paramAPI, err := params.LoadAPIParams(v)
+	if err != nil {
+		return fmt.Errorf("failed to load API parameters: %w", err)
+	}
+
+	if _, err := cmdapi.NewClient(paramAPI); err != nil {
+		return fmt.Errorf("failed to initialize api client: %w", err)
+	}
+
+	queueFilepath, err := offline.QueueFilepath()
+	if err != nil {
+		log.Warnf("failed to load offline queue filepath: %s", err)
+	}
+
+	w, err := watch.NewWatcher(watch.Config{
+		Debounce: paramWatch.Debounce,
+		Dirs:     paramWatch.Dirs,
+	})
+	if err != nil {
+		return err
+	}
+
+	defer w.Close()
+
+	// heartbeats flushed once ctx is done are queued, instead of being sent
+	h := &handler{
+		ctx:           ctx,
+		newFlags:      newFlags,
+		queueFilepath: queueFilepath,
+		v:             v,
+	}
+
+	log.Infof("watching %s", paramWatch)
+
+	return w.Run(ctx, h.handle)
+}
+
+type handler struct {
+	ctx           context.Context
+	newFlags      vipertools.NewFlagsFunc
+	queueFilepath string
+	v             *viper.Viper
+}
+
+// batch contains heartbeats sharing the same processing pipeline.
+type batch struct {
+	heartbeats []heartbeat.Heartbeat
+	params     params.Params
+	v          *viper.Viper
+}
+
+func (h *handler) handle(events []watch.Event) {
+	for _, b := range h.batches(events) {
+		log.SetRedaction(params.LoadRedactionParams(b.v, b.heartbeats...))
+
+		for len(b.heartbeats) > 0 {
+			n := len(b.heartbeats)
+			if n > offline.SendLimit {
+				n = offline.SendLimit
+			}
+
+			if err := cmdheartbeat.HandleHeartbeats(h.ctx, b.v, b.params, b.heartbeats[:n], h.queueFilepath); err != nil {
+				log.Errorf("failed to send %d heartbeat(s): %s", n, err)
+			}
+
+			b.heartbeats = b.heartbeats[n:]
+		}
+	}
+
+	log.SetRedaction(params.LoadRedactionParams(h.v))
+
+	if err := offlinesync.SyncOfflineActivity(h.ctx, h.v, h.queueFilepath); err != nil {
+		var errSyncDisabled offlinesync.ErrSyncDisabled
+		if !errors.As(err, &errSyncDisabled) {
+			log.Warnf("failed to sync offline activity: %s", err)
+		}
+	}
+}
+
+// batchKey identifies the pipeline configuration depending on the entity.
+type batchKey struct {
+	obfuscate  bool
+	repoConfig string
+}
+
+// batches creates a write heartbeat per modified file. Heartbeats are grouped
+// by project name obfuscation and the merged repo config file, the only
+// pipeline configuration depending on the entity.
+func (h *handler) batches(events []watch.Event) []*batch {
+	var (
+		batches []*batch
+		keys    = make(map[batchKey]*batch)
+	)
+
+	for _, e := range events {
+		v, repoConfig := params.LoadRepoConfig(h.ctx, h.eventViper(e))
+
+		p, err := params.Load(v)
+		if err != nil {
+			log.Errorf("failed to load command parameters for %q: %s", e.File, err)
+			continue
+		}
+
+		key := batchKey{
+			obfuscate:  heartbeat.ShouldSanitize(p.Heartbeat.Entity, p.Heartbeat.Sanitize.HideProjectNames),
+			repoConfig: repoConfig,
+		}
+
+		b, ok := keys[key]
+		if !ok {
+			b = &batch{
+				params: p,
+				v:      v,
+			}
+
+			keys[key] = b
+			batches = append(batches, b)
+		}
+
+		b.heartbeats = append(b.heartbeats, cmdheartbeat.BuildHeartbeats(p)...)
+	}
+
+	return batches
+}
+
+// eventViper creates a viper instance for a modified file. Config values and
+// command line flags are taken over from the watch process, as if the file had
+// been passed on the command line.
+func (h *handler) eventViper(e watch.Event) *viper.Viper {
+	v, _ := h.newFlags()
+
+	for _, key := range h.v.AllKeys() {
+		if h.v.IsSet(key) && !ignoredKeys[key] {
+			v.Set(key, h.v.Get(key))
+		}
+	}
+
+	v.Set("entity", e.File)
+	v.Set("time", float64(e.Time.UnixNano())/1000000000)
+	v.Set("write", true)
+
+	return v
+}
@@ -0,0 +1,229 @@
+package watch_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	cmdwatch "github.com/wakatime/wakatime-cli/cmd/watch"
+
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type heartbeat struct {
+	Entity   string `json:"entity"`
+	IsWrite  bool   `json:"is_write"`
+	Language string `json:"language"`
+	Project  string `json:"project"`
+}
+
+func TestWatch(t *testing.T) {
+	received := make(chan []heartbeat, 10)
+
+	router := http.NewServeMux()
+	router.HandleFunc("/users/current/heartbeats.bulk", func(w http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+
+		var heartbeats []heartbeat
+
+		err = json.Unmarshal(body, &heartbeats)
+		require.NoError(t, err)
+
+		received <- heartbeats
+
+		w.WriteHeader(http.StatusCreated)
+		_, _ = fmt.Fprint(w, `{"responses": [[{"data": {}}, 201]]}`)
+	})
+
+	apiServer := httptest.NewServer(router)
+	defer apiServer.Close()
+
+	tmpDir := t.TempDir()
+	watchDir := filepath.Join(tmpDir, "project")
+
+	require.NoError(t, os.Mkdir(watchDir, 0700))
+
+	v, flags := newFlags()
+	require.NoError(t, flags.Parse([]string{
+		"--api-url", apiServer.URL,
+		"--exclude", `\.log$`,
+		"--key", "00000000-0000-4000-8000-000000000000",
+		"--offline-queue-file", filepath.Join(tmpDir, "offline.bdb"),
+		"--project", "wakatime-cli",
+		"--watch-dir", watchDir,
+	}))
+	v.Set("settings.watch_debounce", 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	watched := make(chan error)
+
+	go func() {
+		watched <- cmdwatch.Watch(ctx, v, newFlags)
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+
+	err := os.WriteFile(filepath.Join(watchDir, "main.go"), []byte("package main\n"), 0600)
+	require.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(watchDir, "debug.log"), []byte("debug\n"), 0600)
+	require.NoError(t, err)
+
+	var heartbeats []heartbeat
+
+	select {
+	case heartbeats = <-received:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for heartbeats")
+	}
+
+	cancel()
+
+	require.NoError(t, <-watched)
+
+	assert.Equal(t, []heartbeat{
+		{
+			Entity:   filepath.Join(watchDir, "main.go"),
+			IsWrite:  true,
+			Language: "Go",
+			Project:  "wakatime-cli",
+		},
+	}, heartbeats)
+}
+
+func TestWatch_RepoConfig(t *testing.T) {
+	received := make(chan []heartbeat, 10)
+
+	router := http.NewServeMux()
+	router.HandleFunc("/users/current/heartbeats.bulk", func(w http.ResponseWriter, req *http.Request) {
+		body, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+
+		var heartbeats []heartbeat
+
+		err = json.Unmarshal(body, &heartbeats)
+		require.NoError(t, err)
+
+		received <- heartbeats
+
+		w.WriteHeader(http.StatusCreated)
+		_, _ = fmt.Fprint(w, `{"responses": [[{"data": {}}, 201]]}`)
+	})
+
+	apiServer := httptest.NewServer(router)
+	defer apiServer.Close()
+
+	tmpDir := t.TempDir()
+	watchDir := filepath.Join(tmpDir, "projects")
+
+	repos := map[string]string{
+		"hidden":  "[settings]\nhide_file_names = true\n",
+		"visible": "[settings]\nhide_file_names = false\n",
+	}
+
+	for name, config := range repos {
+		err := os.MkdirAll(filepath.Join(watchDir, name), 0700)
+		require.NoError(t, err)
+
+		err = os.WriteFile(filepath.Join(watchDir, name, ".wakatime-project"), []byte(name+"\n"), 0600)
+		require.NoError(t, err)
+
+		err = os.WriteFile(filepath.Join(watchDir, name, ".wakatime.cfg"), []byte(config), 0600)
+		require.NoError(t, err)
+	}
+
+	v, flags := newFlags()
+	require.NoError(t, flags.Parse([]string{
+		"--api-url", apiServer.URL,
+		"--key", "00000000-0000-4000-8000-000000000000",
+		"--offline-queue-file", filepath.Join(tmpDir, "offline.bdb"),
+		"--watch-dir", watchDir,
+	}))
+	v.Set("settings.repo_config", true)
+	v.Set("settings.watch_debounce", 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	watched := make(chan error)
+
+	go func() {
+		watched <- cmdwatch.Watch(ctx, v, newFlags)
+	}()
+
+	time.Sleep(100 * time.Millisecond)
+
+	for name := range repos {
+		err := os.WriteFile(filepath.Join(watchDir, name, "main.go"), []byte("package main\n"), 0600)
+		require.NoError(t, err)
+	}
+
+	entities := make(map[string]string)
+
+	for len(entities) < len(repos) {
+		select {
+		case heartbeats := <-received:
+			for _, h := range heartbeats {
+				entities[h.Project] = h.Entity
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for heartbeats")
+		}
+	}
+
+	cancel()
+
+	require.NoError(t, <-watched)
+
+	assert.Equal(t, map[string]string{
+		"hidden":  "HIDDEN.go",
+		"visible": filepath.Join(watchDir, "visible", "main.go"),
+	}, entities)
+}
+
+func TestWatch_NoDirectories(t *testing.T) {
+	v, _ := newFlags()
+
+	err := cmdwatch.Watch(context.Background(), v, newFlags)
+	assert.EqualError(t, err, "no directories to watch. set --watch-dir or watch_dirs in config file")
+}
+
+// newFlags defines the subset of command line flags used in tests.
+func newFlags() (*viper.Viper, *pflag.FlagSet) {
+	flags := pflag.NewFlagSet("wakatime-cli", pflag.ContinueOnError)
+
+	for _, name := range []string{
+		"api-url", "category", "entity", "entity-type", "key",
+		"offline-queue-file", "plugin", "project", "sync-offline-activity",
+	} {
+		flags.String(name, "", "")
+	}
+
+	flags.Bool("extra-heartbeats", false, "")
+	flags.Bool("watch", false, "")
+	flags.Bool("write", false, "")
+	flags.Float64("time", 0, "")
+	flags.Int("watch-debounce", 0, "")
+	flags.StringSlice("exclude", nil, "")
+	flags.StringSlice("watch-dir", nil, "")
+
+	v := viper.New()
+	if err := v.BindPFlags(flags); err != nil {
+		panic(err)
+	}
+
+	return v, flags
+}
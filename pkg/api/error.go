@@ -23,3 +23,17 @@ type ErrBadRequest string
 func (e ErrBadRequest) Error() string {
 	return string(e)
 }
+
+// ErrBackoff represents an api error, caused by backing off from the api after
+// previous failures.
+type ErrBackoff string
+
+// Error method to implement error interface.
+func (e ErrBackoff) Error() string {
+	return string(e)
+}
+
+// Unwrap returns the general api error, so ErrBackoff is handled as Err.
+func (e ErrBackoff) Unwrap() error {
+	return Err(e)
+}
@@ -76,3 +76,77 @@ func ParseGoalResponse(data []byte) (*goal.Goal, error) {
 		Total: body.Data.ChartData[len(body.Data.ChartData)-1].ActualSecondsText,
 	}, nil
 }
+
+// Goals fetches all goals of the current user, without tracked working time.
+//
+// ErrRequest is returned upon request failure with no received response from api.
+// ErrAuth is returned upon receiving a 401 Unauthorized api response.
+// Err is returned on any other api response related error.
+func (c *Client) Goals() ([]goal.Goal, error) {
+	url := c.baseURL + "/users/current/goals"
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %s", err)
+	}
+
+	resp, err := c.Do(req)
+	if err != nil {
+		return nil, Err(fmt.Sprintf("failed to make request to %q: %s", url, err))
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, Err(fmt.Sprintf("failed to read response body from %q: %s", url, err))
+	}
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		break
+	case http.StatusUnauthorized:
+		return nil, ErrAuth(fmt.Sprintf("authentication failed at %q. body: %q", url, string(body)))
+	case http.StatusBadRequest:
+		return nil, ErrBadRequest(fmt.Sprintf("bad request at %q", url))
+	default:
+		return nil, Err(fmt.Sprintf(
+			"invalid response status from %q. got: %d, want: %d. body: %q",
+			url,
+			resp.StatusCode,
+			http.StatusOK,
+			string(body),
+		))
+	}
+
+	goals, err := ParseGoalsResponse(body)
+	if err != nil {
+		return nil, Err(fmt.Sprintf("failed to parse results from %q: %s", url, err))
+	}
+
+	return goals, nil
+}
+
+// ParseGoalsResponse parses the wakatime api response into a list of goal.Goal.
+func ParseGoalsResponse(data []byte) ([]goal.Goal, error) {
+	var body struct {
+		Data []struct {
+			ID    string `json:"id"`
+			Title string `json:"title"`
+		} `json:"data"`
+	}
+
+	if err := json.Unmarshal(data, &body); err != nil {
+		return nil, fmt.Errorf("failed to parse json response body: %s. body: %q", err, data)
+	}
+
+	goals := make([]goal.Goal, 0, len(body.Data))
+
+	for _, g := range body.Data {
+		goals = append(goals, goal.Goal{
+			ID:    g.ID,
+			Title: g.Title,
+		})
+	}
+
+	return goals, nil
+}
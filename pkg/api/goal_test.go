@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/wakatime/wakatime-cli/pkg/api"
+	"github.com/wakatime/wakatime-cli/pkg/goal"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -152,3 +153,64 @@ func TestClient_Goal_ErrInvalidUrl(t *testing.T) {
 
 	assert.True(t, errors.As(err, &apierr))
 }
+
+func TestClient_Goals(t *testing.T) {
+	u, router, tearDown := setupTestServer()
+	defer tearDown()
+
+	var numCalls int
+
+	router.HandleFunc("/users/current/goals", func(w http.ResponseWriter, req *http.Request) {
+		numCalls++
+
+		// check request
+		assert.Equal(t, http.MethodGet, req.Method)
+		assert.Equal(t, []string{"application/json"}, req.Header["Accept"])
+
+		// write response
+		f, err := os.Open("testdata/api_goals_response.json")
+		require.NoError(t, err)
+
+		w.WriteHeader(http.StatusOK)
+		_, err = io.Copy(w, f)
+		require.NoError(t, err)
+	})
+
+	c := api.NewClient(u)
+	goals, err := c.Goals()
+
+	require.NoError(t, err)
+
+	assert.Equal(t, []goal.Goal{
+		{
+			ID:    "00000000-0000-4000-8000-000000000000",
+			Title: "Code 1 hr per day",
+		},
+		{
+			ID:    "00000000-0000-4000-8000-000000000001",
+			Title: "Code 5 hrs per week in Go",
+		},
+	}, goals)
+
+	assert.Eventually(t, func() bool { return numCalls == 1 }, time.Second, 50*time.Millisecond)
+}
+
+func TestClient_Goals_ErrAuth(t *testing.T) {
+	u, router, tearDown := setupTestServer()
+	defer tearDown()
+
+	var numCalls int
+
+	router.HandleFunc("/users/current/goals", func(w http.ResponseWriter, req *http.Request) {
+		numCalls++
+		w.WriteHeader(http.StatusUnauthorized)
+	})
+
+	c := api.NewClient(u)
+	_, err := c.Goals()
+
+	var errauth api.ErrAuth
+
+	assert.True(t, errors.As(err, &errauth))
+	assert.Eventually(t, func() bool { return numCalls == 1 }, time.Second, 50*time.Millisecond)
+}
@@ -1,6 +1,7 @@
 package api_test
 
 import (
+	"context"
 	"errors"
 	"io"
 	"net/http"
@@ -55,7 +56,7 @@ func TestClient_SendHeartbeats(t *testing.T) {
 			})
 
 			c := api.NewClient(url)
-			results, err := c.SendHeartbeats(testHeartbeats())
+			results, err := c.SendHeartbeats(context.Background(), testHeartbeats())
 			require.NoError(t, err)
 
 			// check via assert.Equal on complete slice here, to assert exact order of results,
@@ -115,7 +116,7 @@ func TestClient_SendHeartbeats_Err(t *testing.T) {
 	})
 
 	c := api.NewClient(url)
-	_, err := c.SendHeartbeats(testHeartbeats())
+	_, err := c.SendHeartbeats(context.Background(), testHeartbeats())
 
 	var errapi api.Err
 
@@ -136,7 +137,7 @@ func TestClient_SendHeartbeats_ErrAuth(t *testing.T) {
 	})
 
 	c := api.NewClient(url)
-	_, err := c.SendHeartbeats(testHeartbeats())
+	_, err := c.SendHeartbeats(context.Background(), testHeartbeats())
 
 	var errauth api.ErrAuth
 
@@ -157,7 +158,7 @@ func TestClient_SendHeartbeats_ErrBadRequest(t *testing.T) {
 	})
 
 	c := api.NewClient(url)
-	_, err := c.SendHeartbeats(testHeartbeats())
+	_, err := c.SendHeartbeats(context.Background(), testHeartbeats())
 
 	var errbadRequest api.ErrBadRequest
 
@@ -166,9 +167,32 @@ func TestClient_SendHeartbeats_ErrBadRequest(t *testing.T) {
 	assert.Eventually(t, func() bool { return numCalls == 1 }, time.Second, 50*time.Millisecond)
 }
 
+func TestClient_SendHeartbeats_ContextCanceled(t *testing.T) {
+	url, router, close := setupTestServer()
+	defer close()
+
+	var numCalls int
+
+	router.HandleFunc("/users/current/heartbeats.bulk", func(w http.ResponseWriter, req *http.Request) {
+		numCalls++
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	c := api.NewClient(url)
+	_, err := c.SendHeartbeats(ctx, testHeartbeats())
+
+	var errapi api.Err
+
+	assert.True(t, errors.As(err, &errapi))
+	assert.Zero(t, numCalls)
+}
+
 func TestClient_SendHeartbeats_InvalidUrl(t *testing.T) {
 	c := api.NewClient("invalid-url")
-	_, err := c.SendHeartbeats(testHeartbeats())
+	_, err := c.SendHeartbeats(context.Background(), testHeartbeats())
 
 	var apierr api.Err
 
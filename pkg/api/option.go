@@ -6,11 +6,13 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
 	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/trace"
 
 	"github.com/Azure/go-ntlmssp"
 )
@@ -189,3 +191,26 @@ func WithUserAgent(plugin string) Option {
 		}
 	}
 }
+
+// WithTracing records a span for each request of the api client.
+func WithTracing(tracer *trace.Tracer) Option {
+	return func(c *Client) {
+		next := c.doFunc
+		c.doFunc = func(c *Client, req *http.Request) (*http.Response, error) {
+			attributes := map[string]string{
+				"http.method": req.Method,
+				"http.target": req.URL.Path,
+			}
+
+			end := tracer.Start("api.Client.Do", attributes)
+			defer end()
+
+			resp, err := next(c, req)
+			if err == nil {
+				attributes["http.status_code"] = strconv.Itoa(resp.StatusCode)
+			}
+
+			return resp, err
+		}
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/wakatime/wakatime-cli/pkg/api"
+	"github.com/wakatime/wakatime-cli/pkg/trace"
 	"github.com/wakatime/wakatime-cli/pkg/version"
 
 	"github.com/Azure/go-ntlmssp"
@@ -321,3 +322,33 @@ func TestOption_WithTimezone(t *testing.T) {
 
 	assert.Eventually(t, func() bool { return numCalls == 1 }, time.Second, 50*time.Millisecond)
 }
+
+func TestOption_WithTracing(t *testing.T) {
+	url, router, tearDown := setupTestServer()
+	defer tearDown()
+
+	router.HandleFunc("/users/current", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	tracer := trace.NewTracer()
+
+	req, err := http.NewRequest(http.MethodPost, url+"/users/current", nil)
+	require.NoError(t, err)
+
+	c := api.NewClient("", api.WithTracing(tracer))
+	resp, err := c.Do(req)
+	require.NoError(t, err)
+
+	defer resp.Body.Close()
+
+	spans := tracer.Spans()
+	require.Len(t, spans, 1)
+
+	assert.Equal(t, "api.Client.Do", spans[0].Name)
+	assert.Equal(t, map[string]string{
+		"http.method":      http.MethodPost,
+		"http.status_code": "201",
+		"http.target":      "/users/current",
+	}, spans[0].Attributes)
+}
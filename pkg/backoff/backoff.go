@@ -1,6 +1,7 @@
 package backoff
 
 import (
+	"context"
 	"fmt"
 	"math"
 	"strconv"
@@ -36,14 +37,14 @@ type Config struct {
 // a heartbeat when the api is unresponsive.
 func WithBackoff(config Config) heartbeat.HandleOption {
 	return func(next heartbeat.Handle) heartbeat.Handle {
-		return func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		return func(ctx context.Context, hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
 			log.Debugln("execute heartbeat backoff algorithm")
 
 			if shouldBackoff(config.Retries, config.At) {
-				return nil, api.Err("won't send heartbeat due to backoff")
+				return nil, api.ErrBackoff("won't send heartbeat due to backoff")
 			}
 
-			results, err := next(hh)
+			results, err := next(ctx, hh)
 			if err != nil {
 				log.Debugf("incrementing backoff due to error")
 
@@ -1,12 +1,14 @@
 package backoff_test
 
 import (
+	"context"
 	"errors"
 	"os"
 	"testing"
 	"time"
 
 	"github.com/spf13/viper"
+	"github.com/wakatime/wakatime-cli/pkg/api"
 	"github.com/wakatime/wakatime-cli/pkg/backoff"
 	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
 
@@ -28,7 +30,7 @@ func TestWithRetry(t *testing.T) {
 		V: v,
 	})
 
-	handle := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+	handle := opt(func(_ context.Context, hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
 		return []heartbeat.Result{
 			{
 				Status: 201,
@@ -36,7 +38,7 @@ func TestWithRetry(t *testing.T) {
 		}, nil
 	})
 
-	_, err = handle([]heartbeat.Heartbeat{})
+	_, err = handle(context.Background(), []heartbeat.Heartbeat{})
 	require.NoError(t, err)
 }
 
@@ -48,7 +50,7 @@ func TestWithRetry_BeforeNextBackoff(t *testing.T) {
 		Retries: 1,
 	})
 
-	handle := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+	handle := opt(func(_ context.Context, hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
 		return []heartbeat.Result{
 			{
 				Status: 201,
@@ -56,10 +58,16 @@ func TestWithRetry_BeforeNextBackoff(t *testing.T) {
 		}, nil
 	})
 
-	_, err := handle([]heartbeat.Heartbeat{})
+	_, err := handle(context.Background(), []heartbeat.Heartbeat{})
 	require.Error(t, err)
 
 	assert.Equal(t, "won't send heartbeat due to backoff", err.Error())
+
+	var errbackoff api.ErrBackoff
+	assert.True(t, errors.As(err, &errbackoff))
+
+	var errapi api.Err
+	assert.True(t, errors.As(err, &errapi))
 }
 
 func TestWithRetry_ApiError(t *testing.T) {
@@ -76,11 +84,11 @@ func TestWithRetry_ApiError(t *testing.T) {
 		V: v,
 	})
 
-	handle := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+	handle := opt(func(_ context.Context, hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
 		return []heartbeat.Result{}, errors.New("error")
 	})
 
-	_, err = handle([]heartbeat.Heartbeat{})
+	_, err = handle(context.Background(), []heartbeat.Heartbeat{})
 	require.Error(t, err)
 
 	assert.Equal(t, "error", err.Error())
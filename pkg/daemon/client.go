@@ -0,0 +1,98 @@
+package daemon
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"time"
+)
+
+// requestTimeout is the timeout for requests to a running daemon.
+const requestTimeout = 2 * time.Second
+
+// ErrNotRunning is returned, if no daemon listens on an address.
+type ErrNotRunning struct {
+	Err error
+}
+
+// Error method to implement error interface.
+func (e ErrNotRunning) Error() string {
+	return fmt.Sprintf("daemon is not running: %s", e.Err)
+}
+
+// Client sends requests to a running daemon.
+type Client struct {
+	client *http.Client
+}
+
+// NewClient creates a new Client connecting to the daemon at address.
+func NewClient(address string) (*Client, error) {
+	network, addr, err := ParseAddress(address)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &net.Dialer{Timeout: dialTimeout}
+
+	return &Client{
+		client: &http.Client{
+			Timeout: requestTimeout,
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					return dialer.DialContext(ctx, network, addr)
+				},
+				Proxy: nil,
+			},
+		},
+	}, nil
+}
+
+// Status returns the status of the daemon. Returns ErrNotRunning, if no
+// daemon accepts connections.
+func (c *Client) Status() (Status, error) {
+	resp, err := c.client.Get("http://daemon" + pathStatus)
+	if err != nil {
+		return Status{}, ErrNotRunning{Err: err}
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return Status{}, fmt.Errorf("invalid response status from daemon: %d", resp.StatusCode)
+	}
+
+	var status Status
+
+	if err := json.NewDecoder(resp.Body).Decode(&status); err != nil {
+		return Status{}, fmt.Errorf("failed to json decode daemon status: %s", err)
+	}
+
+	return status, nil
+}
+
+// Send forwards a request to the daemon.
+func (c *Client) Send(r Request) error {
+	data, err := json.Marshal(r)
+	if err != nil {
+		return fmt.Errorf("failed to json encode request: %s", err)
+	}
+
+	resp, err := c.client.Post("http://daemon"+pathHeartbeats, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed making request to daemon: %s", err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted {
+		body, _ := io.ReadAll(resp.Body)
+
+		return fmt.Errorf("invalid response status from daemon: %d. body: %q", resp.StatusCode, string(body))
+	}
+
+	return nil
+}
@@ -0,0 +1,22 @@
+package daemon_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/daemon"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClient_Status_NotRunning(t *testing.T) {
+	c, err := daemon.NewClient(filepath.Join(t.TempDir(), "daemon.sock"))
+	require.NoError(t, err)
+
+	_, err = c.Status()
+	require.Error(t, err)
+
+	var errNotRunning daemon.ErrNotRunning
+	assert.ErrorAs(t, err, &errNotRunning)
+}
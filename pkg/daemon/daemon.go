@@ -0,0 +1,210 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/log"
+)
+
+const (
+	// DefaultFlushInterval is the default interval in which queued requests are handled.
+	DefaultFlushInterval = time.Second
+	// DefaultMaxBatch is the default maximum amount of requests handled at once.
+	DefaultMaxBatch = 25
+	// pathHeartbeats is the endpoint accepting heartbeat requests.
+	pathHeartbeats = "/heartbeats"
+	// pathStatus is the endpoint used to check, if the daemon is running.
+	pathStatus = "/status"
+)
+
+// Request contains the heartbeat fields of a single wakatime-cli invocation,
+// which was forwarded to the daemon.
+type Request struct {
+	// ExtraHeartbeats is the raw JSON array of extra heartbeats read from stdin.
+	ExtraHeartbeats string `json:"extra_heartbeats,omitempty"`
+	// Params maps command line flag names to their values. Slice flags may
+	// have multiple values.
+	Params map[string][]string `json:"params"`
+}
+
+// Status represents the state of a running daemon.
+type Status struct {
+	PID    int `json:"pid"`
+	Queued int `json:"queued"`
+}
+
+// Handle processes a batch of requests.
+type Handle func(rr []Request)
+
+// Config contains daemon server configurations.
+type Config struct {
+	// FlushInterval is the maximum time requests are queued before being handled.
+	FlushInterval time.Duration
+	// MaxBatch is the amount of queued requests, which are handled immediately.
+	MaxBatch int
+	// PID is reported as part of the status.
+	PID int
+}
+
+// Server accepts requests on a listener and passes them batched to a Handle.
+type Server struct {
+	closed   bool
+	config   Config
+	done     chan struct{}
+	handle   Handle
+	mu       sync.RWMutex
+	queued   int32
+	requests chan Request
+	server   *http.Server
+}
+
+// NewServer creates a new Server, which passes received requests to handle.
+func NewServer(handle Handle, config Config) *Server {
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = DefaultFlushInterval
+	}
+
+	if config.MaxBatch <= 0 {
+		config.MaxBatch = DefaultMaxBatch
+	}
+
+	s := &Server{
+		config:   config,
+		done:     make(chan struct{}),
+		handle:   handle,
+		requests: make(chan Request, config.MaxBatch),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(pathHeartbeats, s.handleHeartbeats)
+	mux.HandleFunc(pathStatus, s.handleStatus)
+
+	s.server = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	return s
+}
+
+// Serve accepts requests on listener l. Blocks until the server is shut down
+// and all queued requests were handled.
+func (s *Server) Serve(l net.Listener) error {
+	go s.batch()
+
+	err := s.server.Serve(l)
+	if errors.Is(err, http.ErrServerClosed) {
+		<-s.done
+
+		return nil
+	}
+
+	return err
+}
+
+// Shutdown stops accepting new requests and flushes all queued requests.
+func (s *Server) Shutdown(ctx context.Context) error {
+	err := s.server.Shutdown(ctx)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.closed {
+		s.closed = true
+		close(s.requests)
+	}
+
+	return err
+}
+
+func (s *Server) handleHeartbeats(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+
+	var r Request
+
+	if err := json.NewDecoder(req.Body).Decode(&r); err != nil {
+		http.Error(w, fmt.Sprintf("failed to json decode request: %s", err), http.StatusBadRequest)
+		return
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if s.closed {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	s.requests <- r
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (s *Server) handleStatus(w http.ResponseWriter, _ *http.Request) {
+	status := Status{
+		PID:    s.config.PID,
+		Queued: int(atomic.LoadInt32(&s.queued)) + len(s.requests),
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	if err := json.NewEncoder(w).Encode(status); err != nil {
+		log.Warnf("failed to json encode daemon status: %s", err)
+	}
+}
+
+// batch collects received requests and passes them to the handle, once the
+// flush interval passed or the maximum batch size is reached.
+func (s *Server) batch() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.config.FlushInterval)
+	defer ticker.Stop()
+
+	var pending []Request
+
+	flush := func() {
+		if len(pending) == 0 {
+			return
+		}
+
+		log.Debugf("daemon handles %d queued request(s)", len(pending))
+
+		s.handle(pending)
+
+		pending = nil
+
+		atomic.StoreInt32(&s.queued, 0)
+	}
+
+	for {
+		select {
+		case r, ok := <-s.requests:
+			if !ok {
+				flush()
+				return
+			}
+
+			pending = append(pending, r)
+
+			atomic.StoreInt32(&s.queued, int32(len(pending)))
+
+			if len(pending) >= s.config.MaxBatch {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package daemon_test
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/daemon"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServer(t *testing.T) {
+	address := filepath.Join(t.TempDir(), "daemon.sock")
+
+	var (
+		batches [][]daemon.Request
+		mu      sync.Mutex
+	)
+
+	server := daemon.NewServer(func(rr []daemon.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		batches = append(batches, rr)
+	}, daemon.Config{
+		FlushInterval: time.Hour,
+		MaxBatch:      2,
+		PID:           42,
+	})
+
+	l, err := daemon.Listen(address)
+	require.NoError(t, err)
+
+	served := make(chan error)
+
+	go func() {
+		served <- server.Serve(l)
+	}()
+
+	c, err := daemon.NewClient(address)
+	require.NoError(t, err)
+
+	status, err := c.Status()
+	require.NoError(t, err)
+
+	assert.Equal(t, daemon.Status{PID: 42}, status)
+
+	for _, entity := range []string{"/tmp/first.go", "/tmp/second.go", "/tmp/third.go"} {
+		err = c.Send(daemon.Request{
+			Params: map[string][]string{"entity": {entity}},
+		})
+		require.NoError(t, err)
+	}
+
+	assert.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+
+		return len(batches) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	// third request is flushed on shutdown
+	err = server.Shutdown(context.Background())
+	require.NoError(t, err)
+
+	require.NoError(t, <-served)
+
+	assert.Equal(t, [][]daemon.Request{
+		{
+			{Params: map[string][]string{"entity": {"/tmp/first.go"}}},
+			{Params: map[string][]string{"entity": {"/tmp/second.go"}}},
+		},
+		{
+			{Params: map[string][]string{"entity": {"/tmp/third.go"}}},
+		},
+	}, batches)
+}
+
+func TestServer_FlushInterval(t *testing.T) {
+	address := filepath.Join(t.TempDir(), "daemon.sock")
+
+	handled := make(chan []daemon.Request, 1)
+
+	server := daemon.NewServer(func(rr []daemon.Request) {
+		handled <- rr
+	}, daemon.Config{
+		FlushInterval: 10 * time.Millisecond,
+	})
+
+	l, err := daemon.Listen(address)
+	require.NoError(t, err)
+
+	go func() {
+		_ = server.Serve(l)
+	}()
+
+	defer server.Shutdown(context.Background()) // nolint:errcheck
+
+	c, err := daemon.NewClient(address)
+	require.NoError(t, err)
+
+	err = c.Send(daemon.Request{ExtraHeartbeats: "[]"})
+	require.NoError(t, err)
+
+	select {
+	case rr := <-handled:
+		assert.Equal(t, []daemon.Request{{ExtraHeartbeats: "[]"}}, rr)
+	case <-time.After(time.Second):
+		t.Fatal("request was not handled")
+	}
+}
@@ -0,0 +1,80 @@
+package daemon
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"strings"
+	"time"
+)
+
+// dialTimeout is the timeout for connecting to a running daemon.
+const dialTimeout = 500 * time.Millisecond
+
+// ErrAlreadyRunning is returned, if another daemon listens on an address.
+var ErrAlreadyRunning = errors.New("daemon is already running")
+
+// ParseAddress returns the network and address to listen on or connect to.
+// Addresses in host:port format are served over tcp and must use a loopback
+// host. Any other address is treated as unix socket path.
+func ParseAddress(address string) (network string, addr string, err error) {
+	if address == "" {
+		return "", "", errors.New("empty daemon address")
+	}
+
+	host, _, err := net.SplitHostPort(address)
+	if err != nil || strings.ContainsAny(address, `/\`) {
+		return "unix", address, nil
+	}
+
+	if host == "localhost" {
+		return "tcp", address, nil
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil || !ip.IsLoopback() {
+		return "", "", fmt.Errorf("daemon address %q must use a loopback host", address)
+	}
+
+	return "tcp", address, nil
+}
+
+// Listen listens on the daemon address. Stale unix sockets of a previous
+// daemon are removed. Unix sockets are only accessible by the current user.
+func Listen(address string) (net.Listener, error) {
+	network, addr, err := ParseAddress(address)
+	if err != nil {
+		return nil, err
+	}
+
+	if network == "unix" {
+		if _, err := os.Stat(addr); err == nil {
+			conn, err := net.DialTimeout(network, addr, dialTimeout)
+			if err == nil {
+				conn.Close()
+
+				return nil, ErrAlreadyRunning
+			}
+
+			if err := os.Remove(addr); err != nil {
+				return nil, fmt.Errorf("failed to remove stale socket %q: %s", addr, err)
+			}
+		}
+	}
+
+	l, err := net.Listen(network, addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s %q: %s", network, addr, err)
+	}
+
+	if network == "unix" {
+		if err := os.Chmod(addr, 0600); err != nil {
+			l.Close()
+
+			return nil, fmt.Errorf("failed to restrict socket permissions: %s", err)
+		}
+	}
+
+	return l, nil
+}
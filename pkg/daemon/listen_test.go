@@ -0,0 +1,100 @@
+package daemon_test
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/daemon"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListen_StaleSocket(t *testing.T) {
+	address := filepath.Join(t.TempDir(), "daemon.sock")
+
+	l, err := net.Listen("unix", address)
+	require.NoError(t, err)
+
+	// keep the socket file, like a crashed daemon would do
+	l.(*net.UnixListener).SetUnlinkOnClose(false)
+	l.Close()
+
+	l, err = daemon.Listen(address)
+	require.NoError(t, err)
+
+	l.Close()
+}
+
+func TestListen_AlreadyRunning(t *testing.T) {
+	address := filepath.Join(t.TempDir(), "daemon.sock")
+
+	l, err := daemon.Listen(address)
+	require.NoError(t, err)
+
+	defer l.Close()
+
+	_, err = daemon.Listen(address)
+	assert.ErrorIs(t, err, daemon.ErrAlreadyRunning)
+}
+
+func TestParseAddress(t *testing.T) {
+	tests := map[string]struct {
+		Address  string
+		Network  string
+		Expected string
+	}{
+		"unix socket": {
+			Address:  "/home/user/.wakatime-daemon.sock",
+			Network:  "unix",
+			Expected: "/home/user/.wakatime-daemon.sock",
+		},
+		"windows socket": {
+			Address:  `C:\Users\user\.wakatime-daemon.sock`,
+			Network:  "unix",
+			Expected: `C:\Users\user\.wakatime-daemon.sock`,
+		},
+		"localhost": {
+			Address:  "localhost:9119",
+			Network:  "tcp",
+			Expected: "localhost:9119",
+		},
+		"loopback ip": {
+			Address:  "127.0.0.1:9119",
+			Network:  "tcp",
+			Expected: "127.0.0.1:9119",
+		},
+		"loopback ipv6": {
+			Address:  "[::1]:9119",
+			Network:  "tcp",
+			Expected: "[::1]:9119",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			network, address, err := daemon.ParseAddress(test.Address)
+			require.NoError(t, err)
+
+			assert.Equal(t, test.Network, network)
+			assert.Equal(t, test.Expected, address)
+		})
+	}
+}
+
+func TestParseAddress_Err(t *testing.T) {
+	tests := map[string]string{
+		"empty":       "",
+		"public ip":   "10.0.0.1:9119",
+		"any address": "0.0.0.0:9119",
+		"hostname":    "example.org:9119",
+	}
+
+	for name, address := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, _, err := daemon.ParseAddress(address)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package deps
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -31,7 +32,7 @@ type ParserC struct {
 }
 
 // Parse parses dependencies from C file content using the C lexer.
-func (p *ParserC) Parse(filepath string) ([]string, error) {
+func (p *ParserC) Parse(ctx context.Context, filepath string) ([]string, error) {
 	reader, err := os.Open(filepath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open file %q: %s", filepath, err)
@@ -47,6 +48,10 @@ func (p *ParserC) Parse(filepath string) ([]string, error) {
 		return nil, fmt.Errorf("failed to read from reader: %s", err)
 	}
 
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	iter, err := c.C.Tokenise(nil, string(data))
 	if err != nil {
 		return nil, fmt.Errorf("failed to tokenize file content: %s", err)
@@ -1,6 +1,7 @@
 package deps_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/wakatime/wakatime-cli/pkg/deps"
@@ -41,7 +42,7 @@ func TestParserC_Parse(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			parser := deps.ParserC{}
 
-			dependencies, err := parser.Parse(test.Filepath)
+			dependencies, err := parser.Parse(context.Background(), test.Filepath)
 			require.NoError(t, err)
 
 			assert.Equal(t, test.Expected, dependencies)
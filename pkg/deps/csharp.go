@@ -1,6 +1,7 @@
 package deps
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -32,7 +33,7 @@ type ParserCSharp struct {
 }
 
 // Parse parses dependencies from C# file content using the chroma C# lexer.
-func (p *ParserCSharp) Parse(filepath string) ([]string, error) {
+func (p *ParserCSharp) Parse(ctx context.Context, filepath string) ([]string, error) {
 	reader, err := os.Open(filepath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open file %q: %s", filepath, err)
@@ -48,6 +49,10 @@ func (p *ParserCSharp) Parse(filepath string) ([]string, error) {
 		return nil, fmt.Errorf("failed to read from reader: %s", err)
 	}
 
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	iter, err := c.CSharp.Tokenise(nil, string(data))
 	if err != nil {
 		return nil, fmt.Errorf("failed to tokenize file content: %s", err)
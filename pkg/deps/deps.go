@@ -1,6 +1,7 @@
 package deps
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
@@ -25,7 +26,7 @@ type Config struct {
 
 // DependencyParser is a dependency parser for a programming language.
 type DependencyParser interface {
-	Parse(filepath string) ([]string, error)
+	Parse(ctx context.Context, filepath string) ([]string, error)
 }
 
 // WithDetection initializes and returns a heartbeat handle option, which
@@ -34,10 +35,15 @@ type DependencyParser interface {
 // local file if available.
 func WithDetection(c Config) heartbeat.HandleOption {
 	return func(next heartbeat.Handle) heartbeat.Handle {
-		return func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		return func(ctx context.Context, hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
 			log.Debugln("execute dependency detection")
 
 			for n, h := range hh {
+				if ctx.Err() != nil {
+					log.Debugln("skip dependency detection, as context is done")
+					break
+				}
+
 				if h.EntityType != heartbeat.FileType {
 					continue
 				}
@@ -61,7 +67,7 @@ func WithDetection(c Config) heartbeat.HandleOption {
 					log.Warnf("error parsing language of string %q", *h.Language)
 				}
 
-				dependencies, err := Detect(filepath, language)
+				dependencies, err := Detect(ctx, filepath, language)
 				if err != nil {
 					log.Warnf("error detecting dependencies of heartbeat: %s", err)
 					continue
@@ -70,13 +76,13 @@ func WithDetection(c Config) heartbeat.HandleOption {
 				hh[n].Dependencies = dependencies
 			}
 
-			return next(hh)
+			return next(ctx, hh)
 		}
 	}
 }
 
 // Detect parses the dependencies from a heartbeat file of a specific language.
-func Detect(filepath string, language heartbeat.Language) ([]string, error) {
+func Detect(ctx context.Context, filepath string, language heartbeat.Language) ([]string, error) {
 	var parser DependencyParser
 
 	switch language {
@@ -123,7 +129,7 @@ func Detect(filepath string, language heartbeat.Language) ([]string, error) {
 		parser = &ParserUnknown{}
 	}
 
-	deps, err := parser.Parse(filepath)
+	deps, err := parser.Parse(ctx, filepath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse dependencies: %s", err)
 	}
@@ -1,6 +1,7 @@
 package deps_test
 
 import (
+	"context"
 	"regexp"
 	"testing"
 
@@ -15,7 +16,7 @@ import (
 func TestWithDetection(t *testing.T) {
 	opt := deps.WithDetection(deps.Config{})
 
-	h := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+	h := opt(func(_ context.Context, hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
 		assert.Equal(t, []heartbeat.Heartbeat{
 			{
 				Dependencies: []string{
@@ -35,7 +36,7 @@ func TestWithDetection(t *testing.T) {
 		}, nil
 	})
 
-	result, err := h([]heartbeat.Heartbeat{{
+	result, err := h(context.Background(), []heartbeat.Heartbeat{{
 		Entity:     "testdata/golang_minimal.go",
 		EntityType: heartbeat.FileType,
 		Language:   heartbeat.String("Go"),
@@ -54,7 +55,7 @@ func TestWithDetection_SkipSanitized(t *testing.T) {
 		FilePatterns: []regex.Regex{regexp.MustCompile(".*")},
 	})
 
-	h := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+	h := opt(func(_ context.Context, hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
 		assert.Len(t, hh[0].Dependencies, 0)
 
 		return []heartbeat.Result{
@@ -64,7 +65,7 @@ func TestWithDetection_SkipSanitized(t *testing.T) {
 		}, nil
 	})
 
-	result, err := h([]heartbeat.Heartbeat{{
+	result, err := h(context.Background(), []heartbeat.Heartbeat{{
 		Entity:     "testdata/golang.go",
 		EntityType: heartbeat.FileType,
 		Language:   heartbeat.String("Go"),
@@ -81,7 +82,7 @@ func TestWithDetection_SkipSanitized(t *testing.T) {
 func TestWithDetection_LocalFile(t *testing.T) {
 	opt := deps.WithDetection(deps.Config{})
 
-	h := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+	h := opt(func(_ context.Context, hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
 		assert.Equal(t, []heartbeat.Heartbeat{
 			{
 				Dependencies: []string{
@@ -102,7 +103,7 @@ func TestWithDetection_LocalFile(t *testing.T) {
 		}, nil
 	})
 
-	result, err := h([]heartbeat.Heartbeat{{
+	result, err := h(context.Background(), []heartbeat.Heartbeat{{
 		Entity:     "testdata/golang.go",
 		EntityType: heartbeat.FileType,
 		Language:   heartbeat.String("Go"),
@@ -120,7 +121,7 @@ func TestWithDetection_LocalFile(t *testing.T) {
 func TestWithDetection_NonFileType(t *testing.T) {
 	opt := deps.WithDetection(deps.Config{})
 
-	h := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+	h := opt(func(_ context.Context, hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
 		assert.Equal(t, []heartbeat.Heartbeat{
 			{
 				Entity:     "testdata/codefiles/golang.go",
@@ -135,7 +136,7 @@ func TestWithDetection_NonFileType(t *testing.T) {
 		}, nil
 	})
 
-	result, err := h([]heartbeat.Heartbeat{{
+	result, err := h(context.Background(), []heartbeat.Heartbeat{{
 		Entity:     "testdata/codefiles/golang.go",
 		EntityType: heartbeat.AppType,
 	}})
@@ -266,7 +267,7 @@ func TestDetect(t *testing.T) {
 
 	for name, test := range tests {
 		t.Run(name, func(t *testing.T) {
-			deps, err := deps.Detect(test.Filepath, test.Language)
+			deps, err := deps.Detect(context.Background(), test.Filepath, test.Language)
 			require.NoError(t, err)
 
 			assert.Equal(t, test.Dependencies, deps)
@@ -276,6 +277,7 @@ func TestDetect(t *testing.T) {
 
 func TestDetect_DuplicatesRemoved(t *testing.T) {
 	deps, err := deps.Detect(
+		context.Background(),
 		"testdata/golang_duplicate.go",
 		heartbeat.LanguageGo,
 	)
@@ -288,6 +290,7 @@ func TestDetect_DuplicatesRemoved(t *testing.T) {
 
 func TestDetect_LongDependenciesRemoved(t *testing.T) {
 	deps, err := deps.Detect(
+		context.Background(),
 		"testdata/python_with_long_import.py",
 		heartbeat.LanguagePython,
 	)
@@ -303,6 +306,7 @@ func TestDetect_LongDependenciesRemoved(t *testing.T) {
 
 func TestDetect_MaxDependenciesCountReached(t *testing.T) {
 	deps, err := deps.Detect(
+		context.Background(),
 		"testdata/python_with_many_imports.py",
 		heartbeat.LanguagePython,
 	)
@@ -313,6 +317,7 @@ func TestDetect_MaxDependenciesCountReached(t *testing.T) {
 
 func TestDetect_EmptyDependenciesRemoved(t *testing.T) {
 	deps, err := deps.Detect(
+		context.Background(),
 		"testdata/bower_empty_dependency.json",
 		heartbeat.LanguageJSON,
 	)
@@ -1,6 +1,7 @@
 package deps
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -28,7 +29,7 @@ type ParserElm struct {
 }
 
 // Parse parses dependencies from Elm file content using the chroma Elm lexer.
-func (p *ParserElm) Parse(filepath string) ([]string, error) {
+func (p *ParserElm) Parse(ctx context.Context, filepath string) ([]string, error) {
 	reader, err := os.Open(filepath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open file %q: %s", filepath, err)
@@ -44,6 +45,10 @@ func (p *ParserElm) Parse(filepath string) ([]string, error) {
 		return nil, fmt.Errorf("failed to read from reader: %s", err)
 	}
 
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	iter, err := e.Elm.Tokenise(nil, string(data))
 	if err != nil {
 		return nil, fmt.Errorf("failed to tokenize file content: %s", err)
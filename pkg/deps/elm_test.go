@@ -1,6 +1,7 @@
 package deps_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -11,7 +12,7 @@ import (
 func TestParserElm_Parse(t *testing.T) {
 	parser := deps.ParserElm{}
 
-	dependencies, err := parser.Parse("testdata/elm.elm")
+	dependencies, err := parser.Parse(context.Background(), "testdata/elm.elm")
 	require.NoError(t, err)
 
 	assert.Equal(t, []string{
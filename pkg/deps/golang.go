@@ -1,6 +1,7 @@
 package deps
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -32,7 +33,7 @@ type ParserGo struct {
 }
 
 // Parse parses dependencies from Golang file content using the chroma Golang lexer.
-func (p *ParserGo) Parse(filepath string) ([]string, error) {
+func (p *ParserGo) Parse(ctx context.Context, filepath string) ([]string, error) {
 	reader, err := os.Open(filepath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open file %q: %s", filepath, err)
@@ -48,6 +49,10 @@ func (p *ParserGo) Parse(filepath string) ([]string, error) {
 		return nil, fmt.Errorf("failed to read from reader: %s", err)
 	}
 
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	iter, err := g.Go.Tokenise(nil, string(data))
 	if err != nil {
 		return nil, fmt.Errorf("failed to tokenize file content: %s", err)
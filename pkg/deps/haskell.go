@@ -1,6 +1,7 @@
 package deps
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -28,7 +29,7 @@ type ParserHaskell struct {
 }
 
 // Parse parses dependencies from Haskell file content using the chroma Haskell lexer.
-func (p *ParserHaskell) Parse(filepath string) ([]string, error) {
+func (p *ParserHaskell) Parse(ctx context.Context, filepath string) ([]string, error) {
 	reader, err := os.Open(filepath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open file %q: %s", filepath, err)
@@ -44,6 +45,10 @@ func (p *ParserHaskell) Parse(filepath string) ([]string, error) {
 		return nil, fmt.Errorf("failed to read from reader: %s", err)
 	}
 
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	iter, err := h.Haskell.Tokenise(nil, string(data))
 	if err != nil {
 		return nil, fmt.Errorf("failed to tokenize file content: %s", err)
@@ -1,6 +1,7 @@
 package deps_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/wakatime/wakatime-cli/pkg/deps"
@@ -12,7 +13,7 @@ import (
 func TestParserHTML_Parse(t *testing.T) {
 	parser := deps.ParserHTML{}
 
-	dependencies, err := parser.Parse("testdata/html.html")
+	dependencies, err := parser.Parse(context.Background(), "testdata/html.html")
 	require.NoError(t, err)
 
 	assert.Equal(t, []string{
@@ -26,7 +27,7 @@ func TestParserHTML_Parse(t *testing.T) {
 func TestParserHTML_Parse_Django(t *testing.T) {
 	parser := deps.ParserHTML{}
 
-	dependencies, err := parser.Parse("testdata/html_django.html")
+	dependencies, err := parser.Parse(context.Background(), "testdata/html_django.html")
 	require.NoError(t, err)
 
 	assert.Equal(t, []string{
@@ -37,7 +38,7 @@ func TestParserHTML_Parse_Django(t *testing.T) {
 func TestParserHTML_Parse_WithPHP(t *testing.T) {
 	parser := deps.ParserHTML{}
 
-	dependencies, err := parser.Parse("testdata/html_with_php.html")
+	dependencies, err := parser.Parse(context.Background(), "testdata/html_with_php.html")
 	require.NoError(t, err)
 
 	assert.Equal(t, []string{
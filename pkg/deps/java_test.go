@@ -1,6 +1,7 @@
 package deps_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/wakatime/wakatime-cli/pkg/deps"
@@ -12,7 +13,7 @@ import (
 func TestParserJava_Parse(t *testing.T) {
 	parser := deps.ParserJava{}
 
-	dependencies, err := parser.Parse("testdata/java.java")
+	dependencies, err := parser.Parse(context.Background(), "testdata/java.java")
 	require.NoError(t, err)
 
 	assert.Equal(t, []string{
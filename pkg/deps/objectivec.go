@@ -1,6 +1,7 @@
 package deps
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -28,7 +29,7 @@ type ParserObjectiveC struct {
 }
 
 // Parse parses dependencies from Objective-C file content using the chroma Objective-C lexer.
-func (p *ParserObjectiveC) Parse(filepath string) ([]string, error) {
+func (p *ParserObjectiveC) Parse(ctx context.Context, filepath string) ([]string, error) {
 	reader, err := os.Open(filepath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open file %q: %s", filepath, err)
@@ -44,6 +45,10 @@ func (p *ParserObjectiveC) Parse(filepath string) ([]string, error) {
 		return nil, fmt.Errorf("failed to read from reader: %s", err)
 	}
 
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
 	iter, err := o.ObjectiveC.Tokenise(nil, string(data))
 	if err != nil {
 		return nil, fmt.Errorf("failed to tokenize file content: %s", err)
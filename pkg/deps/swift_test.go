@@ -1,6 +1,7 @@
 package deps_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/wakatime/wakatime-cli/pkg/deps"
@@ -12,7 +13,7 @@ import (
 func TestParserSwift_Parse(t *testing.T) {
 	parser := deps.ParserSwift{}
 
-	dependencies, err := parser.Parse("testdata/swift.swift")
+	dependencies, err := parser.Parse(context.Background(), "testdata/swift.swift")
 	require.NoError(t, err)
 
 	assert.Equal(t, []string{
@@ -1,6 +1,7 @@
 package deps_test
 
 import (
+	"context"
 	"testing"
 
 	"github.com/wakatime/wakatime-cli/pkg/deps"
@@ -12,7 +13,7 @@ import (
 func TestParserVbNet_Parse(t *testing.T) {
 	parser := deps.ParserVbNet{}
 
-	dependencies, err := parser.Parse("testdata/vbnet.vb")
+	dependencies, err := parser.Parse(context.Background(), "testdata/vbnet.vb")
 	require.NoError(t, err)
 
 	assert.Equal(t, []string{
@@ -0,0 +1,91 @@
+package diagnostic
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/version"
+)
+
+type versionInfo struct {
+	Version      string `json:"version"`
+	Commit       string `json:"commit"`
+	BuildDate    string `json:"build_date"`
+	Platform     string `json:"platform"`
+	Architecture string `json:"architecture"`
+}
+
+// WriteBundle writes the passed in diagnostics as a gzip compressed tar
+// archive to w. Version info of the wakatime-cli build is always included.
+func WriteBundle(w io.Writer, now time.Time, diagnostics ...Diagnostic) error {
+	gw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gw)
+
+	data, err := json.MarshalIndent(versionInfo{
+		Version:      version.Version,
+		Commit:       version.Commit,
+		BuildDate:    version.BuildDate,
+		Platform:     version.OS,
+		Architecture: version.Arch,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to json marshal version info: %s", err)
+	}
+
+	if err := writeBundleFile(tw, "version.json", data, now); err != nil {
+		return err
+	}
+
+	for _, d := range diagnostics {
+		var name string
+
+		switch d.Type {
+		case TypeLogs:
+			name = "logs.txt"
+		case TypeStack:
+			name = "stack.txt"
+		case TypeConfig:
+			name = "config.json"
+		case TypeOfflineQueue:
+			name = "offline_queue.json"
+		default:
+			return fmt.Errorf("unknown diagnostic type %d", d.Type)
+		}
+
+		if err := writeBundleFile(tw, name, []byte(d.Value), now); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("failed to close tar writer: %s", err)
+	}
+
+	if err := gw.Close(); err != nil {
+		return fmt.Errorf("failed to close gzip writer: %s", err)
+	}
+
+	return nil
+}
+
+func writeBundleFile(tw *tar.Writer, name string, data []byte, now time.Time) error {
+	err := tw.WriteHeader(&tar.Header{
+		Name:    name,
+		Mode:    0600,
+		Size:    int64(len(data)),
+		ModTime: now,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write tar header for %q: %s", name, err)
+	}
+
+	if _, err := tw.Write(data); err != nil {
+		return fmt.Errorf("failed to write %q to tar archive: %s", name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package diagnostic_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/diagnostic"
+	"github.com/wakatime/wakatime-cli/pkg/version"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteBundle(t *testing.T) {
+	version.OS = "some os"
+	version.Arch = "some architecture"
+	version.Version = "some version"
+
+	var buf bytes.Buffer
+
+	err := diagnostic.WriteBundle(
+		&buf,
+		time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC),
+		diagnostic.Logs("some logs"),
+		diagnostic.Stack("some stack"),
+		diagnostic.Config(`{"settings.debug":true}`),
+		diagnostic.OfflineQueue(`{"count":2}`),
+	)
+	require.NoError(t, err)
+
+	files := readBundle(t, &buf)
+
+	assert.Equal(t, "some logs", files["logs.txt"])
+	assert.Equal(t, "some stack", files["stack.txt"])
+	assert.Equal(t, `{"settings.debug":true}`, files["config.json"])
+	assert.Equal(t, `{"count":2}`, files["offline_queue.json"])
+
+	var info map[string]string
+
+	err = json.Unmarshal([]byte(files["version.json"]), &info)
+	require.NoError(t, err)
+
+	assert.Equal(t, "some version", info["version"])
+	assert.Equal(t, "some os", info["platform"])
+	assert.Equal(t, "some architecture", info["architecture"])
+}
+
+func TestWriteBundle_UnknownType(t *testing.T) {
+	var buf bytes.Buffer
+
+	err := diagnostic.WriteBundle(&buf, time.Now(), diagnostic.Diagnostic{Type: diagnostic.TypeUnknown})
+	require.Error(t, err)
+
+	assert.EqualError(t, err, "unknown diagnostic type 0")
+}
+
+func readBundle(t *testing.T, r io.Reader) map[string]string {
+	gr, err := gzip.NewReader(r)
+	require.NoError(t, err)
+
+	tr := tar.NewReader(gr)
+
+	files := make(map[string]string)
+
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+
+		require.NoError(t, err)
+
+		data, err := io.ReadAll(tr)
+		require.NoError(t, err)
+
+		files[header.Name] = string(data)
+	}
+
+	return files
+}
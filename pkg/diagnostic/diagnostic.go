@@ -10,6 +10,10 @@ const (
 	TypeLogs
 	// TypeStack designates a Diagnostic for stack trace.
 	TypeStack
+	// TypeConfig designates a Diagnostic for the sanitized effective config.
+	TypeConfig
+	// TypeOfflineQueue designates a Diagnostic for offline queue stats.
+	TypeOfflineQueue
 )
 
 // Diagnostic contains diagnostic info.
@@ -33,3 +37,19 @@ func Stack(stack string) Diagnostic {
 		Value: stack,
 	}
 }
+
+// Config creates a new instance of Diagnostic of type TypeConfig.
+func Config(config string) Diagnostic {
+	return Diagnostic{
+		Type:  TypeConfig,
+		Value: config,
+	}
+}
+
+// OfflineQueue creates a new instance of Diagnostic of type TypeOfflineQueue.
+func OfflineQueue(stats string) Diagnostic {
+	return Diagnostic{
+		Type:  TypeOfflineQueue,
+		Value: stats,
+	}
+}
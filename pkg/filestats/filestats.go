@@ -2,6 +2,7 @@ package filestats
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"os"
@@ -25,7 +26,7 @@ type Config struct {
 // moment only the total number of lines in a file is detected.
 func WithDetection(c Config) heartbeat.HandleOption {
 	return func(next heartbeat.Handle) heartbeat.Handle {
-		return func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		return func(ctx context.Context, hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
 			log.Debugln("execute filestats detection")
 
 			for n, h := range hh {
@@ -77,7 +78,7 @@ func WithDetection(c Config) heartbeat.HandleOption {
 				hh[n].Lines = heartbeat.Int(lines)
 			}
 
-			return next(hh)
+			return next(ctx, hh)
 		}
 	}
 }
@@ -1,6 +1,7 @@
 package filter
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"regexp"
@@ -24,7 +25,7 @@ type Config struct {
 // the provided configurations.
 func WithFiltering(config Config) heartbeat.HandleOption {
 	return func(next heartbeat.Handle) heartbeat.Handle {
-		return func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		return func(ctx context.Context, hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
 			log.Debugln("execute heartbeat filtering")
 
 			var filtered []heartbeat.Heartbeat
@@ -45,7 +46,40 @@ func WithFiltering(config Config) heartbeat.HandleOption {
 				return []heartbeat.Result{}, nil
 			}
 
-			return next(filtered)
+			return next(ctx, filtered)
+		}
+	}
+}
+
+// WithProjectSettingsFiltering initializes and returns a heartbeat handle
+// option, which filters heartbeats by the patterns of their project settings.
+// It must run after project detection, which sets the project settings.
+func WithProjectSettingsFiltering() heartbeat.HandleOption {
+	return func(next heartbeat.Handle) heartbeat.Handle {
+		return func(ctx context.Context, hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+			log.Debugln("execute project settings filtering")
+
+			var filtered []heartbeat.Heartbeat
+
+			for _, h := range hh {
+				if h.ProjectSettings != nil {
+					err := filterByPattern(h.Entity, h.ProjectSettings.Include, h.ProjectSettings.Exclude)
+					if err != nil {
+						log.Errorf("filter by project settings pattern: %s", err)
+
+						continue
+					}
+				}
+
+				filtered = append(filtered, h)
+			}
+
+			if len(filtered) == 0 {
+				log.Debugln("no heartbeat left after filtering. abort heartbeat handling.")
+				return []heartbeat.Result{}, nil
+			}
+
+			return next(ctx, filtered)
 		}
 	}
 }
@@ -1,6 +1,7 @@
 package filter_test
 
 import (
+	"context"
 	"errors"
 	"os"
 	"path/filepath"
@@ -28,7 +29,7 @@ func TestWithFiltering(t *testing.T) {
 	second.Time++
 
 	opt := filter.WithFiltering(filter.Config{})
-	h := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+	h := opt(func(_ context.Context, hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
 		assert.Equal(t, []heartbeat.Heartbeat{
 			{
 				Branch:         heartbeat.String("heartbeat"),
@@ -54,7 +55,7 @@ func TestWithFiltering(t *testing.T) {
 		}, nil
 	})
 
-	result, err := h([]heartbeat.Heartbeat{first, second})
+	result, err := h(context.Background(), []heartbeat.Heartbeat{first, second})
 	require.NoError(t, err)
 
 	assert.Equal(t, []heartbeat.Result{
@@ -66,11 +67,11 @@ func TestWithFiltering(t *testing.T) {
 
 func TestWithFiltering_AbortAllFiltered(t *testing.T) {
 	opt := filter.WithFiltering(filter.Config{})
-	h := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+	h := opt(func(_ context.Context, hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
 		return []heartbeat.Result{}, errors.New("this will should never be called")
 	})
 
-	result, err := h([]heartbeat.Heartbeat{testHeartbeat()})
+	result, err := h(context.Background(), []heartbeat.Heartbeat{testHeartbeat()})
 	require.NoError(t, err)
 
 	assert.Equal(t, result, []heartbeat.Result{})
@@ -136,6 +137,48 @@ func TestFilter_ErrMatchesExcludePattern(t *testing.T) {
 	assert.EqualError(t, err, "filter by pattern: skipping because matches exclude pattern \"^.*exclude-this-file.*$\"")
 }
 
+func TestWithProjectSettingsFiltering(t *testing.T) {
+	settings := &heartbeat.ProjectSettings{
+		Exclude: []regex.Regex{regexp.MustCompile("/vendor/")},
+		Include: []regex.Regex{regexp.MustCompile("/vendor/wakatime/")},
+	}
+
+	excluded := testHeartbeat()
+	excluded.Entity = "/tmp/project/vendor/main.go"
+	excluded.ProjectSettings = settings
+
+	included := testHeartbeat()
+	included.Entity = "/tmp/project/vendor/wakatime/main.go"
+	included.ProjectSettings = settings
+
+	notMatching := testHeartbeat()
+	notMatching.Entity = "/tmp/project/main.go"
+	notMatching.ProjectSettings = settings
+
+	withoutSettings := testHeartbeat()
+	withoutSettings.Entity = "/tmp/other/vendor/main.go"
+
+	opt := filter.WithProjectSettingsFiltering()
+	h := opt(func(_ context.Context, hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		assert.Equal(t, []heartbeat.Heartbeat{included, notMatching, withoutSettings}, hh)
+
+		return []heartbeat.Result{
+			{
+				Status: 201,
+			},
+		}, nil
+	})
+
+	result, err := h(context.Background(), []heartbeat.Heartbeat{excluded, included, notMatching, withoutSettings})
+	require.NoError(t, err)
+
+	assert.Equal(t, []heartbeat.Result{
+		{
+			Status: 201,
+		},
+	}, result)
+}
+
 func TestFilter_ErrNonExistingFile(t *testing.T) {
 	h := testHeartbeat()
 
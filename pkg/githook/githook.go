@@ -0,0 +1,157 @@
+package githook
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/version"
+)
+
+const (
+	// chainedSuffix is appended to the name of an existing hook, which is
+	// called by the installed hook.
+	chainedSuffix = ".wakatime-chained"
+	// marker identifies hooks installed by wakatime-cli.
+	marker = "# wakatime-cli git hook"
+)
+
+// Hook is a git hook and the category of heartbeats sent by it.
+type Hook struct {
+	Category heartbeat.Category
+	Name     string
+}
+
+// Hooks returns the installed hooks. Checking out branches and merging
+// changes are counted as code reviewing, pushing as building, as pre-push
+// hooks usually run tests and builds.
+func Hooks() []Hook {
+	return []Hook{
+		{Category: heartbeat.CodingCategory, Name: "post-commit"},
+		{Category: heartbeat.CodeReviewingCategory, Name: "post-checkout"},
+		{Category: heartbeat.CodeReviewingCategory, Name: "post-merge"},
+		{Category: heartbeat.BuildingCategory, Name: "pre-push"},
+	}
+}
+
+const script = `#!/bin/sh
+%[1]s
+# Sends a heartbeat in the background. A previously installed hook is chained
+# as %[2]s%[3]s and its exit code is kept.
+%[4]s --entity git --entity-type app --category '%[5]s' --project-folder "$PWD" --detect-project-folder \
+  --plugin "git/$(git --version | cut -d' ' -f3) git-wakatime/%[6]s" </dev/null >/dev/null 2>&1 &
+
+chained="$0%[3]s"
+if [ -x "$chained" ]; then
+  exec "$chained" "$@"
+fi
+`
+
+// HooksDir returns the hooks directory of the git repository at dir. Respects
+// the core.hooksPath config.
+func HooksDir(dir string) (string, error) {
+	cmd := exec.Command("git", "-C", dir, "rev-parse", "--git-path", "hooks")
+
+	var stderr bytes.Buffer
+
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to find hooks dir of %q: %s: %s", dir, err, strings.TrimSpace(stderr.String()))
+	}
+
+	hooksDir := strings.TrimSpace(string(out))
+	if !filepath.IsAbs(hooksDir) {
+		hooksDir = filepath.Join(dir, hooksDir)
+	}
+
+	return hooksDir, nil
+}
+
+// Install writes hooks calling the wakatime-cli executable into hooksDir.
+// Existing hooks not installed by wakatime-cli are renamed and chained, so
+// they keep running. Installing again updates previously installed hooks.
+// Returns the paths of installed hooks.
+func Install(hooksDir, executable string) ([]string, error) {
+	if err := os.MkdirAll(hooksDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create hooks dir: %s", err)
+	}
+
+	var installed []string
+
+	for _, hook := range Hooks() {
+		fp := filepath.Join(hooksDir, hook.Name)
+
+		if err := chain(fp); err != nil {
+			return installed, err
+		}
+
+		content := fmt.Sprintf(
+			script,
+			marker,
+			hook.Name,
+			chainedSuffix,
+			quote(filepath.ToSlash(executable)),
+			hook.Category,
+			version.Version,
+		)
+
+		// nolint:gosec
+		if err := os.WriteFile(fp, []byte(content), 0755); err != nil {
+			return installed, fmt.Errorf("failed to write %s hook: %s", hook.Name, err)
+		}
+
+		// hook may have existed with other permissions
+		if err := os.Chmod(fp, 0755); err != nil { // nolint:gosec
+			return installed, fmt.Errorf("failed to make %s hook executable: %s", hook.Name, err)
+		}
+
+		installed = append(installed, fp)
+	}
+
+	return installed, nil
+}
+
+// chain renames an existing hook at fp, unless installed by wakatime-cli.
+func chain(fp string) error {
+	data, err := os.ReadFile(fp)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+
+	if err != nil {
+		return fmt.Errorf("failed to read existing hook: %s", err)
+	}
+
+	if IsInstalled(data) {
+		return nil
+	}
+
+	chained := fp + chainedSuffix
+
+	if _, err := os.Stat(chained); err == nil {
+		return fmt.Errorf("cannot chain existing hook %q, because %q already exists", fp, chained)
+	}
+
+	if err := os.Rename(fp, chained); err != nil {
+		return fmt.Errorf("failed to chain existing hook: %s", err)
+	}
+
+	return nil
+}
+
+// IsInstalled returns true, if the hook content was written by wakatime-cli.
+func IsInstalled(content []byte) bool {
+	return bytes.Contains(content, []byte(marker))
+}
+
+// quote single quotes s for sh.
+func quote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,112 @@
+package githook_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/githook"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInstall(t *testing.T) {
+	hooksDir := filepath.Join(t.TempDir(), "hooks")
+
+	installed, err := githook.Install(hooksDir, "/usr/local/bin/wakatime-cli")
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{
+		filepath.Join(hooksDir, "post-commit"),
+		filepath.Join(hooksDir, "post-checkout"),
+		filepath.Join(hooksDir, "post-merge"),
+		filepath.Join(hooksDir, "pre-push"),
+	}, installed)
+
+	data, err := os.ReadFile(filepath.Join(hooksDir, "pre-push"))
+	require.NoError(t, err)
+
+	assert.True(t, githook.IsInstalled(data))
+	assert.Contains(t, string(data), `'/usr/local/bin/wakatime-cli' --entity git --entity-type app --category 'building'`)
+
+	data, err = os.ReadFile(filepath.Join(hooksDir, "post-merge"))
+	require.NoError(t, err)
+
+	assert.Contains(t, string(data), `--category 'code reviewing'`)
+	assert.Contains(t, string(data), `--project-folder "$PWD" --detect-project-folder`)
+}
+
+func TestInstall_ChainExisting(t *testing.T) {
+	hooksDir := t.TempDir()
+	existing := []byte("#!/bin/sh\nmake test\n")
+
+	err := os.WriteFile(filepath.Join(hooksDir, "pre-push"), existing, 0700) // nolint:gosec
+	require.NoError(t, err)
+
+	_, err = githook.Install(hooksDir, "/usr/local/bin/wakatime-cli")
+	require.NoError(t, err)
+
+	// installing again must not chain the installed hook
+	_, err = githook.Install(hooksDir, "/usr/local/bin/wakatime-cli")
+	require.NoError(t, err)
+
+	chained, err := os.ReadFile(filepath.Join(hooksDir, "pre-push.wakatime-chained"))
+	require.NoError(t, err)
+
+	assert.Equal(t, existing, chained)
+
+	data, err := os.ReadFile(filepath.Join(hooksDir, "pre-push"))
+	require.NoError(t, err)
+
+	assert.True(t, githook.IsInstalled(data))
+}
+
+func TestInstall_ChainConflict(t *testing.T) {
+	hooksDir := t.TempDir()
+
+	for _, name := range []string{"post-commit", "post-commit.wakatime-chained"} {
+		err := os.WriteFile(filepath.Join(hooksDir, name), []byte("#!/bin/sh\n"), 0700) // nolint:gosec
+		require.NoError(t, err)
+	}
+
+	_, err := githook.Install(hooksDir, "/usr/local/bin/wakatime-cli")
+	assert.Error(t, err)
+}
+
+func TestHooksDir(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary not found")
+	}
+
+	dir := t.TempDir()
+
+	runGit(t, "init", "-q", dir)
+
+	hooksDir, err := githook.HooksDir(dir)
+	require.NoError(t, err)
+
+	assert.Equal(t, filepath.Join(dir, ".git", "hooks"), hooksDir)
+
+	runGit(t, "-C", dir, "config", "core.hooksPath", "custom-hooks")
+
+	hooksDir, err = githook.HooksDir(dir)
+	require.NoError(t, err)
+
+	assert.Equal(t, filepath.Join(dir, "custom-hooks"), hooksDir)
+}
+
+func TestHooksDir_NotARepository(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git binary not found")
+	}
+
+	_, err := githook.HooksDir(filepath.Join(t.TempDir(), "missing"))
+	assert.Error(t, err)
+}
+
+func runGit(t *testing.T, args ...string) {
+	out, err := exec.Command("git", args...).CombinedOutput()
+	require.NoError(t, err, string(out))
+}
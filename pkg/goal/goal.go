@@ -2,5 +2,7 @@ package goal
 
 // Goal represents the tracked working time for a single goal.
 type Goal struct {
+	ID    string
+	Title string
 	Total string
 }
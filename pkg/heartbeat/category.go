@@ -44,6 +44,17 @@ const (
 	writingTestsCategoryString  = "writing tests"
 )
 
+// Categories returns all categories.
+func Categories() []Category {
+	var categories []Category
+
+	for c := CodingCategory; c <= WritingTestsCategory; c++ {
+		categories = append(categories, c)
+	}
+
+	return categories
+}
+
 // ParseCategory parses a category from a string.
 func ParseCategory(s string) (Category, error) {
 	switch s {
@@ -75,6 +75,16 @@ func TestCategory_MarshalJSON_DefaultCategory(t *testing.T) {
 	assert.JSONEq(t, `"coding"`, string(data))
 }
 
+func TestCategories(t *testing.T) {
+	categories := heartbeat.Categories()
+
+	assert.Len(t, categories, len(categoryTests()))
+
+	for _, category := range categories {
+		assert.Equal(t, category, categoryTests()[category.String()])
+	}
+}
+
 func TestCategory_String(t *testing.T) {
 	for value, category := range categoryTests() {
 		t.Run(value, func(t *testing.T) {
@@ -23,6 +23,11 @@ const (
 	appTypeString    = "app"
 )
 
+// EntityTypes returns all entity types.
+func EntityTypes() []EntityType {
+	return []EntityType{FileType, DomainType, AppType}
+}
+
 // ParseEntityType parses an entity type from a string.
 func ParseEntityType(s string) (EntityType, error) {
 	switch s {
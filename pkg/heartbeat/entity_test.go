@@ -34,6 +34,16 @@ func TestParseEntityType_Invalid(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestEntityTypes(t *testing.T) {
+	entityTypes := heartbeat.EntityTypes()
+
+	assert.Len(t, entityTypes, len(typeTests()))
+
+	for _, entityType := range entityTypes {
+		assert.Equal(t, entityType, typeTests()[entityType.String()])
+	}
+}
+
 func TestEntityType_UnmarshalJSON(t *testing.T) {
 	for value, entityType := range typeTests() {
 		t.Run(value, func(t *testing.T) {
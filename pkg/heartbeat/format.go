@@ -1,6 +1,7 @@
 package heartbeat
 
 import (
+	"context"
 	"path/filepath"
 	"regexp"
 	"runtime"
@@ -21,7 +22,7 @@ type FormatConfig struct {
 // can be used in a heartbeat processing pipeline to format entity's filepath.
 func WithFormatting(config FormatConfig) HandleOption {
 	return func(next Handle) Handle {
-		return func(hh []Heartbeat) ([]Result, error) {
+		return func(ctx context.Context, hh []Heartbeat) ([]Result, error) {
 			log.Debugln("execute heartbeat filepath formatting")
 
 			for n, h := range hh {
@@ -32,7 +33,7 @@ func WithFormatting(config FormatConfig) HandleOption {
 				hh[n] = Format(h)
 			}
 
-			return next(hh)
+			return next(ctx, hh)
 		}
 	}
 }
@@ -1,12 +1,14 @@
 package heartbeat
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"runtime"
 	"strings"
 
 	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/regex"
 	"github.com/wakatime/wakatime-cli/pkg/version"
 
 	"github.com/matishsiao/goInfo"
@@ -14,26 +16,43 @@ import (
 
 // Heartbeat is a structure representing activity for a user on a some entity.
 type Heartbeat struct {
-	Branch              *string    `json:"branch"`
-	Category            Category   `json:"category"`
-	CursorPosition      *int       `json:"cursorpos"`
-	Dependencies        []string   `json:"dependencies"`
-	Entity              string     `json:"entity"`
-	EntityRaw           string     `json:"-"`
-	EntityType          EntityType `json:"type"`
-	IsWrite             *bool      `json:"is_write"`
-	Language            *string    `json:"language"`
-	LanguageAlternate   string     `json:"-"`
-	LineNumber          *int       `json:"lineno"`
-	Lines               *int       `json:"lines"`
-	LocalFile           string     `json:"-"`
-	Project             *string    `json:"project"`
-	ProjectAlternate    string     `json:"-"`
-	ProjectOverride     string     `json:"-"`
-	ProjectPath         string     `json:"-"`
-	ProjectPathOverride string     `json:"-"`
-	Time                float64    `json:"time"`
-	UserAgent           string     `json:"user_agent"`
+	Branch              *string          `json:"branch"`
+	Category            Category         `json:"category"`
+	Commit              *string          `json:"commit,omitempty"`
+	CursorPosition      *int             `json:"cursorpos"`
+	Dependencies        []string         `json:"dependencies"`
+	Entity              string           `json:"entity"`
+	EntityRaw           string           `json:"-"`
+	EntityType          EntityType       `json:"type"`
+	IsWrite             *bool            `json:"is_write"`
+	Language            *string          `json:"language"`
+	LanguageAlternate   string           `json:"-"`
+	LineNumber          *int             `json:"lineno"`
+	Lines               *int             `json:"lines"`
+	LocalFile           string           `json:"-"`
+	Project             *string          `json:"project"`
+	ProjectAlternate    string           `json:"-"`
+	ProjectOverride     string           `json:"-"`
+	ProjectPath         string           `json:"-"`
+	ProjectPathOverride string           `json:"-"`
+	ProjectSettings     *ProjectSettings `json:"-"`
+	Time                float64          `json:"time"`
+	UserAgent           string           `json:"user_agent"`
+}
+
+// ProjectSettings contains the tracking settings of the project of a single
+// heartbeat, like from its .wakatime-project file. They only add to the
+// configured settings and cannot show what is hidden by config.
+type ProjectSettings struct {
+	// Exclude and Include are added to the configured filter patterns. Include
+	// only overrides Exclude of the project settings.
+	Exclude []regex.Regex
+	Include []regex.Regex
+	// BranchPatterns and FilePatterns are added to the configured sanitize patterns.
+	BranchPatterns []regex.Regex
+	FilePatterns   []regex.Regex
+	// HideProjectFolder hides the project folder, even if not configured.
+	HideProjectFolder bool
 }
 
 // New creates a new instance of Heartbeat with formatted entity
@@ -109,11 +128,12 @@ type Result struct {
 
 // Sender sends heartbeats to the wakatime api.
 type Sender interface {
-	SendHeartbeats(hh []Heartbeat) ([]Result, error)
+	SendHeartbeats(ctx context.Context, hh []Heartbeat) ([]Result, error)
 }
 
-// Handle does processing of heartbeats.
-type Handle func(hh []Heartbeat) ([]Result, error)
+// Handle does processing of heartbeats. Processing stops early, once ctx is
+// done.
+type Handle func(ctx context.Context, hh []Heartbeat) ([]Result, error)
 
 // HandleOption is a function, which allows chaining multiple Handles.
 type HandleOption func(next Handle) Handle
@@ -121,13 +141,13 @@ type HandleOption func(next Handle) Handle
 // NewHandle creates a new Handle, which acts like a processing pipeline,
 // with a sender eventually sending the heartbeats.
 func NewHandle(sender Sender, opts ...HandleOption) Handle {
-	return func(heartbeats []Heartbeat) ([]Result, error) {
+	return func(ctx context.Context, heartbeats []Heartbeat) ([]Result, error) {
 		var handle Handle = sender.SendHeartbeats
 		for i := len(opts) - 1; i >= 0; i-- {
 			handle = opts[i](handle)
 		}
 
-		return handle(heartbeats)
+		return handle(ctx, heartbeats)
 	}
 }
 
@@ -1,6 +1,7 @@
 package heartbeat_test
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -135,7 +136,7 @@ func TestHeartbeat_JSON_NilFields(t *testing.T) {
 
 func TestNewHandle(t *testing.T) {
 	sender := mockSender{
-		SendHeartbeatsFn: func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		SendHeartbeatsFn: func(_ context.Context, hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
 			assert.Equal(t, []heartbeat.Heartbeat{
 				{
 					Branch:     heartbeat.String("test"),
@@ -157,18 +158,18 @@ func TestNewHandle(t *testing.T) {
 
 	opts := []heartbeat.HandleOption{
 		func(next heartbeat.Handle) heartbeat.Handle {
-			return func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+			return func(_ context.Context, hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
 				for i := range hh {
 					hh[i].Branch = heartbeat.String("test")
 				}
 
-				return next(hh)
+				return next(context.Background(), hh)
 			}
 		},
 	}
 
 	handle := heartbeat.NewHandle(&sender, opts...)
-	_, err := handle([]heartbeat.Heartbeat{
+	_, err := handle(context.Background(), []heartbeat.Heartbeat{
 		{
 			Category:   heartbeat.CodingCategory,
 			Entity:     "/tmp/main.go",
@@ -240,11 +241,11 @@ func TestPluginFromUserAgent(t *testing.T) {
 }
 
 type mockSender struct {
-	SendHeartbeatsFn        func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error)
+	SendHeartbeatsFn        func(_ context.Context, hh []heartbeat.Heartbeat) ([]heartbeat.Result, error)
 	SendHeartbeatsFnInvoked bool
 }
 
-func (m *mockSender) SendHeartbeats(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+func (m *mockSender) SendHeartbeats(_ context.Context, hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
 	m.SendHeartbeatsFnInvoked = true
-	return m.SendHeartbeatsFn(hh)
+	return m.SendHeartbeatsFn(context.Background(), hh)
 }
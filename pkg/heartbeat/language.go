@@ -2344,6 +2344,17 @@ const (
 	languageWebIDLChromaStr             = "Web IDL"
 )
 
+// Languages returns all known languages, excluding LanguageUnknown.
+func Languages() []Language {
+	var languages []Language
+
+	for l := Language1CEnterprise; l <= LanguageZimpl; l++ {
+		languages = append(languages, l)
+	}
+
+	return languages
+}
+
 // ParseLanguage parses a language from a string. Will return false
 // as second parameter, if language could not be parsed.
 // nolint:gocyclo
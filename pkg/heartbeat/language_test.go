@@ -857,6 +857,19 @@ func TestParseLanguage(t *testing.T) {
 	})
 }
 
+func TestLanguages(t *testing.T) {
+	languages := heartbeat.Languages()
+
+	assert.NotContains(t, languages, heartbeat.LanguageUnknown)
+
+	for _, language := range languages {
+		// some languages share a name
+		parsed, ok := heartbeat.ParseLanguage(language.String())
+		assert.True(t, ok, language.String())
+		assert.Equal(t, language.String(), parsed.String())
+	}
+}
+
 func TestParseLanguage_Unknown(t *testing.T) {
 	parsed, ok := heartbeat.ParseLanguage("invalid")
 
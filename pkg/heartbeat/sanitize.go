@@ -1,6 +1,7 @@
 package heartbeat
 
 import (
+	"context"
 	"path/filepath"
 	"regexp"
 	"strings"
@@ -29,14 +30,19 @@ type SanitizeConfig struct {
 // can be used in a heartbeat processing pipeline to hide sensitive data.
 func WithSanitization(config SanitizeConfig) HandleOption {
 	return func(next Handle) Handle {
-		return func(hh []Heartbeat) ([]Result, error) {
+		return func(ctx context.Context, hh []Heartbeat) ([]Result, error) {
 			log.Debugln("execute heartbeat sanitization")
 
 			for n, h := range hh {
 				hh[n] = Sanitize(h, config)
+
+				// hidden branches must not show up in later log output
+				if h.Branch != nil && hh[n].Branch == nil {
+					log.AddRedactedNames(*h.Branch)
+				}
 			}
 
-			return next(hh)
+			return next(ctx, hh)
 		}
 	}
 }
@@ -44,6 +50,11 @@ func WithSanitization(config SanitizeConfig) HandleOption {
 // Sanitize accepts a heartbeat sanitizes it's sensitive data following passed
 // in configuration and returns the sanitized version. On empty config will do nothing.
 func Sanitize(h Heartbeat, config SanitizeConfig) Heartbeat {
+	// without branch patterns, branches of hidden files and projects are hidden
+	hideAllBranches := len(config.BranchPatterns) == 0
+
+	config = config.extend(h.ProjectSettings)
+
 	if len(h.Dependencies) == 0 {
 		h.Dependencies = nil
 	}
@@ -58,18 +69,23 @@ func Sanitize(h Heartbeat, config SanitizeConfig) Heartbeat {
 
 		h = santizeMetaData(h)
 
-		if h.Branch != nil && (len(config.BranchPatterns) == 0 || ShouldSanitize(*h.Branch, config.BranchPatterns)) {
+		if h.Branch != nil && (hideAllBranches || ShouldSanitize(*h.Branch, config.BranchPatterns)) {
 			h.Branch = nil
 		}
 	case h.Project != nil && ShouldSanitize(*h.Project, config.ProjectPatterns):
 		h = santizeMetaData(h)
-		if h.Branch != nil && (len(config.BranchPatterns) == 0 || ShouldSanitize(*h.Branch, config.BranchPatterns)) {
+		if h.Branch != nil && (hideAllBranches || ShouldSanitize(*h.Branch, config.BranchPatterns)) {
 			h.Branch = nil
 		}
 	case h.Branch != nil && ShouldSanitize(*h.Branch, config.BranchPatterns):
 		h.Branch = nil
 	}
 
+	// the commit identifies the branch
+	if h.Branch == nil {
+		h.Commit = nil
+	}
+
 	h = hideProjectFolder(h, config.HideProjectFolder)
 
 	h = hideCredentials(h, config.RemoteAddressPattern)
@@ -77,6 +93,20 @@ func Sanitize(h Heartbeat, config SanitizeConfig) Heartbeat {
 	return h
 }
 
+// extend returns the config with the patterns of project settings added.
+// Project settings can only hide more.
+func (c SanitizeConfig) extend(settings *ProjectSettings) SanitizeConfig {
+	if settings == nil {
+		return c
+	}
+
+	c.BranchPatterns = append(append([]regex.Regex{}, c.BranchPatterns...), settings.BranchPatterns...)
+	c.FilePatterns = append(append([]regex.Regex{}, c.FilePatterns...), settings.FilePatterns...)
+	c.HideProjectFolder = c.HideProjectFolder || settings.HideProjectFolder
+
+	return c
+}
+
 // hideProjectFolder makes entity relative to project folder if we're hiding the project folder.
 func hideProjectFolder(h Heartbeat, hideProjectFolder bool) Heartbeat {
 	if h.EntityType != FileType || !hideProjectFolder {
@@ -20,8 +20,8 @@ import (
 )
 
 const (
-	// defaultFile is the name of the default wakatime config file.
-	defaultFile = ".wakatime.cfg"
+	// DefaultFile is the name of the default wakatime config file.
+	DefaultFile = ".wakatime.cfg"
 	// defaultInternalFile is the name of the default wakatime internal config file.
 	defaultInternalFile = ".wakatime-internal.cfg"
 	// DateFormat is the default format for date in config file.
@@ -115,6 +115,121 @@ func ReadInConfig(v *viper.Viper, configFilePath string) error {
 	return nil
 }
 
+// MergeFunc returns the value of key merged from a config file into v.
+type MergeFunc func(v *viper.Viper, key, value string) string
+
+// MergeAllowedKeys merges the allowed keys of a config file into v, by their
+// MergeFunc. Keys are formatted as "section.key". Other keys are ignored.
+// Returns the merged keys.
+func MergeAllowedKeys(v *viper.Viper, configFilePath string, allowed map[string]MergeFunc) ([]string, error) {
+	file, err := ini.LoadSources(ini.LoadOptions{AllowPythonMultilineValues: true}, configFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing config file: %s", err)
+	}
+
+	var merged []string
+
+	for _, section := range file.Sections() {
+		for _, key := range section.Keys() {
+			name := strings.ToLower(section.Name() + "." + key.Name())
+
+			merge, ok := allowed[name]
+			if !ok {
+				log.Warnf("ignoring key %q of config file %q, which is not allowed", name, configFilePath)
+				continue
+			}
+
+			v.Set(name, merge(v, name, key.Value()))
+
+			merged = append(merged, name)
+		}
+	}
+
+	return merged, nil
+}
+
+// Replace is a MergeFunc, which replaces previous values.
+func Replace(_ *viper.Viper, _, value string) string {
+	return value
+}
+
+// AppendList is a MergeFunc, which appends a newline separated list to
+// previous values.
+func AppendList(v *viper.Viper, key, value string) string {
+	return strings.Join(append(v.GetStringSlice(key), value), "\n")
+}
+
+// TightenBool is a MergeFunc for bools, which enable a restriction. Values
+// can enable it, but not disable it.
+func TightenBool(v *viper.Viper, key, value string) string {
+	enabled, err := strconv.ParseBool(strings.TrimSpace(value))
+	if err != nil {
+		log.Warnf("ignoring invalid bool %q of key %q", value, key)
+		return strconv.FormatBool(v.GetBool(key))
+	}
+
+	return strconv.FormatBool(v.GetBool(key) || enabled)
+}
+
+// TightenBoolOrList returns a MergeFunc for "true", "false" or newline
+// separated lists of patterns, like hide_file_names. Lists are added to
+// previous patterns, and "false" or an empty list keep previous values.
+// Previous values are read from key, or else the first non-empty alias.
+func TightenBoolOrList(aliases ...string) MergeFunc {
+	return func(v *viper.Viper, key, value string) string {
+		previous, _ := vipertools.FirstNonEmptyString(v, append([]string{key}, aliases...)...)
+		previous = strings.TrimSpace(previous)
+		value = strings.TrimSpace(value)
+
+		switch {
+		case strings.EqualFold(previous, "true") || strings.EqualFold(value, "true"):
+			return "true"
+		case value == "" || strings.EqualFold(value, "false"):
+			return previous
+		case previous == "" || strings.EqualFold(previous, "false"):
+			return value
+		default:
+			return previous + "\n" + value
+		}
+	}
+}
+
+// KeyValue is a key and its value of a config file section.
+type KeyValue struct {
+	Key   string
+	Value string
+}
+
+// ReadSection reads the keys of a config file section in the order of the
+// file. Unlike viper, keys are not lowercased. Returns no keys, if the
+// config file does not exist.
+func ReadSection(configFilePath, section string) ([]KeyValue, error) {
+	if _, err := os.Stat(configFilePath); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	file, err := ini.LoadSources(ini.LoadOptions{AllowPythonMultilineValues: true}, configFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing config file: %s", err)
+	}
+
+	sec, err := file.GetSection(section)
+	if err != nil {
+		return nil, nil
+	}
+
+	var keyValues []KeyValue
+
+	for _, key := range sec.Keys() {
+		keyValues = append(keyValues, KeyValue{
+			Key:   key.Name(),
+			Value: key.Value(),
+		})
+	}
+
+	return keyValues, nil
+}
+
 // FilePath returns the path for wakatime config file.
 func FilePath(v *viper.Viper) (string, error) {
 	configFilepath := vipertools.GetString(v, "config")
@@ -132,7 +247,7 @@ func FilePath(v *viper.Viper) (string, error) {
 		return "", fmt.Errorf("failed getting user's home directory: %s", err)
 	}
 
-	return filepath.Join(home, defaultFile), nil
+	return filepath.Join(home, DefaultFile), nil
 }
 
 // InternalFilePath returns the path for the wakatime internal config file.
@@ -94,6 +94,163 @@ func TestReadInConfigMalformed(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestReadSection(t *testing.T) {
+	keyValues, err := ini.ReadSection("./testdata/wakatime-projectmap.cfg", "projectmap")
+	require.NoError(t, err)
+
+	assert.Equal(t, []ini.KeyValue{
+		{Key: "/home/user/projects/zebra", Value: "zebra"},
+		{Key: `/home/user/projects/Alpha(\d+)/`, Value: "alpha{0}"},
+		{Key: "/home/user/projects/", Value: "projects [priority=-1]"},
+	}, keyValues)
+}
+
+func TestReadSection_Missing(t *testing.T) {
+	keyValues, err := ini.ReadSection("./testdata/wakatime.cfg", "projectmap")
+	require.NoError(t, err)
+
+	assert.Empty(t, keyValues)
+
+	keyValues, err = ini.ReadSection("./testdata/any.cfg", "projectmap")
+	require.NoError(t, err)
+
+	assert.Empty(t, keyValues)
+}
+
+func TestReadSection_Malformed(t *testing.T) {
+	_, err := ini.ReadSection("./testdata/malformed.cfg", "projectmap")
+	require.Error(t, err)
+}
+
+func TestMergeAllowedKeys(t *testing.T) {
+	v := viper.New()
+	v.Set("settings.api_key", "b9485572-74bf-419a-916b-22056ca3a24c")
+	v.Set("settings.hide_file_names", "false")
+
+	merged, err := ini.MergeAllowedKeys(v, "./testdata/wakatime-repo.cfg", map[string]ini.MergeFunc{
+		"settings.hide_file_names": ini.TightenBoolOrList(),
+		"git.submodules_disabled":  ini.Replace,
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"settings.hide_file_names", "git.submodules_disabled"}, merged)
+	assert.Equal(t, "b9485572-74bf-419a-916b-22056ca3a24c", vipertools.GetString(v, "settings.api_key"))
+	assert.Equal(t, "true", vipertools.GetString(v, "settings.hide_file_names"))
+	assert.Equal(t, "true", vipertools.GetString(v, "git.submodules_disabled"))
+}
+
+func TestAppendList(t *testing.T) {
+	v := viper.New()
+	v.Set("settings.exclude", "^/tmp/\n^/var/")
+
+	value := ini.AppendList(v, "settings.exclude", "/vendor/")
+
+	v.Set("settings.exclude", value)
+
+	assert.Equal(t, []string{"^/tmp/", "^/var/", "/vendor/"}, v.GetStringSlice("settings.exclude"))
+}
+
+func TestTightenBool(t *testing.T) {
+	tests := map[string]struct {
+		Previous string
+		Value    string
+		Expected string
+	}{
+		"enable": {
+			Previous: "false",
+			Value:    "true",
+			Expected: "true",
+		},
+		"cannot disable": {
+			Previous: "true",
+			Value:    "false",
+			Expected: "true",
+		},
+		"invalid": {
+			Previous: "true",
+			Value:    "maybe",
+			Expected: "true",
+		},
+		"unset": {
+			Value:    "false",
+			Expected: "false",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			v := viper.New()
+
+			if test.Previous != "" {
+				v.Set("settings.hide_project_folder", test.Previous)
+			}
+
+			assert.Equal(t, test.Expected, ini.TightenBool(v, "settings.hide_project_folder", test.Value))
+		})
+	}
+}
+
+func TestTightenBoolOrList(t *testing.T) {
+	tests := map[string]struct {
+		Previous string
+		Alias    string
+		Value    string
+		Expected string
+	}{
+		"add patterns": {
+			Previous: "^/secret/",
+			Value:    "\\.env$",
+			Expected: "^/secret/\n\\.env$",
+		},
+		"false keeps patterns": {
+			Previous: "^/secret/",
+			Value:    "false",
+			Expected: "^/secret/",
+		},
+		"empty keeps patterns": {
+			Previous: "^/secret/",
+			Expected: "^/secret/",
+		},
+		"patterns cannot unhide all": {
+			Previous: "true",
+			Value:    "\\.env$",
+			Expected: "true",
+		},
+		"hide all": {
+			Previous: "^/secret/",
+			Value:    "true",
+			Expected: "true",
+		},
+		"legacy key": {
+			Alias:    "true",
+			Value:    "false",
+			Expected: "true",
+		},
+		"unset": {
+			Value:    "\\.env$",
+			Expected: "\\.env$",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			v := viper.New()
+
+			if test.Previous != "" {
+				v.Set("settings.hide_file_names", test.Previous)
+			}
+
+			if test.Alias != "" {
+				v.Set("settings.hidefilenames", test.Alias)
+			}
+
+			merge := ini.TightenBoolOrList("settings.hidefilenames")
+
+			assert.Equal(t, test.Expected, merge(v, "settings.hide_file_names", test.Value))
+		})
+	}
+}
+
 func TestFilePath(t *testing.T) {
 	home, err := os.UserHomeDir()
 	require.NoError(t, err)
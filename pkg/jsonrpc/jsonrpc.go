@@ -0,0 +1,160 @@
+package jsonrpc
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/wakatime/wakatime-cli/pkg/log"
+)
+
+// Version is the supported JSON-RPC protocol version.
+const Version = "2.0"
+
+// maxMessageSize is the maximum size of a single request line.
+const maxMessageSize = 1024 * 1024
+
+// Error codes defined by the JSON-RPC 2.0 specification.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+	// CodeCommandFailed is used for errors of a failed wakatime-cli command.
+	CodeCommandFailed = -32000
+)
+
+// Request is a JSON-RPC request. Requests without id are notifications and
+// will not be answered.
+type Request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// Response is a JSON-RPC response. Either Result or Error is set.
+type Response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// Error is a JSON-RPC error object.
+type Error struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+// Error method to implement error interface.
+func (e *Error) Error() string {
+	return fmt.Sprintf("%s (code %d)", e.Message, e.Code)
+}
+
+// Handle processes the params of a method call. Returning an *Error allows
+// to control the JSON-RPC error code. Any other error is reported as internal
+// error.
+type Handle func(method string, params json.RawMessage) (interface{}, error)
+
+// Serve reads newline delimited requests from r and writes newline delimited
+// responses to w. Requests are handled one by one. Returns nil, once r
+// reaches EOF.
+func Serve(r io.Reader, w io.Writer, handle Handle) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxMessageSize)
+
+	encoder := json.NewEncoder(w)
+
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		resp, ok := call(line, handle)
+		if !ok {
+			continue
+		}
+
+		if err := encoder.Encode(resp); err != nil {
+			return fmt.Errorf("failed to write response: %s", err)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read request: %s", err)
+	}
+
+	return nil
+}
+
+// call handles a single request line. Returns false, if no response must be
+// sent.
+func call(line []byte, handle Handle) (Response, bool) {
+	var req Request
+
+	if err := json.Unmarshal(line, &req); err != nil {
+		return errorResponse(nil, &Error{
+			Code:    CodeParseError,
+			Message: fmt.Sprintf("failed to parse request: %s", err),
+		}), true
+	}
+
+	notification := len(req.ID) == 0
+
+	if req.JSONRPC != Version || req.Method == "" {
+		return errorResponse(req.ID, &Error{
+			Code:    CodeInvalidRequest,
+			Message: fmt.Sprintf("invalid request. jsonrpc must be %q and method must be set", Version),
+		}), !notification
+	}
+
+	result, err := handle(req.Method, req.Params)
+	if err != nil {
+		var rpcErr *Error
+		if !errors.As(err, &rpcErr) {
+			rpcErr = &Error{
+				Code:    CodeInternalError,
+				Message: err.Error(),
+			}
+		}
+
+		if notification {
+			log.Warnf("failed to handle notification %q: %s", req.Method, err)
+		}
+
+		return errorResponse(req.ID, rpcErr), !notification
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		return errorResponse(req.ID, &Error{
+			Code:    CodeInternalError,
+			Message: fmt.Sprintf("failed to json encode result: %s", err),
+		}), !notification
+	}
+
+	return Response{
+		JSONRPC: Version,
+		ID:      req.ID,
+		Result:  data,
+	}, !notification
+}
+
+func errorResponse(id json.RawMessage, err *Error) Response {
+	if len(id) == 0 {
+		id = json.RawMessage("null")
+	}
+
+	return Response{
+		JSONRPC: Version,
+		ID:      id,
+		Error:   err,
+	}
+}
@@ -0,0 +1,82 @@
+package jsonrpc_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/jsonrpc"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServe(t *testing.T) {
+	input := strings.Join([]string{
+		`{"jsonrpc": "2.0", "id": 1, "method": "echo", "params": {"value": "foo"}}`,
+		``,
+		`{"jsonrpc": "2.0", "method": "echo", "params": {"value": "notification"}}`,
+		`{"jsonrpc": "2.0", "id": "two", "method": "fail"}`,
+		`{"jsonrpc": "2.0", "id": 3, "method": "internal"}`,
+		`{"jsonrpc": "2.0", "id": 4, "method": "empty"}`,
+		`{"jsonrpc": "1.0", "id": 5, "method": "echo"}`,
+		`invalid`,
+	}, "\n")
+
+	var calls []string
+
+	handle := func(method string, params json.RawMessage) (interface{}, error) {
+		calls = append(calls, method)
+
+		switch method {
+		case "echo":
+			var p struct {
+				Value string `json:"value"`
+			}
+
+			if err := json.Unmarshal(params, &p); err != nil {
+				return nil, err
+			}
+
+			return p.Value, nil
+		case "fail":
+			return nil, &jsonrpc.Error{
+				Code:    jsonrpc.CodeCommandFailed,
+				Data:    map[string]int{"exitcode": 104},
+				Message: "failed",
+			}
+		case "internal":
+			return nil, errors.New("internal")
+		default:
+			return nil, nil
+		}
+	}
+
+	var output bytes.Buffer
+
+	err := jsonrpc.Serve(strings.NewReader(input), &output, handle)
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"echo", "echo", "fail", "internal", "empty"}, calls)
+
+	lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+	require.Len(t, lines, 6)
+
+	assert.JSONEq(t, `{"jsonrpc": "2.0", "id": 1, "result": "foo"}`, lines[0])
+	assert.JSONEq(t, `{
+		"jsonrpc": "2.0",
+		"id": "two",
+		"error": {"code": -32000, "message": "failed", "data": {"exitcode": 104}}
+	}`, lines[1])
+	assert.JSONEq(t, `{"jsonrpc": "2.0", "id": 3, "error": {"code": -32603, "message": "internal"}}`, lines[2])
+	assert.JSONEq(t, `{"jsonrpc": "2.0", "id": 4, "result": null}`, lines[3])
+	assert.JSONEq(t, `{
+		"jsonrpc": "2.0",
+		"id": 5,
+		"error": {"code": -32600, "message": "invalid request. jsonrpc must be \"2.0\" and method must be set"}
+	}`, lines[4])
+	assert.Contains(t, lines[5], `"id":null`)
+	assert.Contains(t, lines[5], `"code":-32700`)
+}
@@ -1,6 +1,7 @@
 package language
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -15,7 +16,7 @@ import (
 // language info to heartbeats of entity type 'file'.
 func WithDetection() heartbeat.HandleOption {
 	return func(next heartbeat.Handle) heartbeat.Handle {
-		return func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		return func(ctx context.Context, hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
 			log.Debugln("execute language detection")
 
 			for n, h := range hh {
@@ -45,7 +46,7 @@ func WithDetection() heartbeat.HandleOption {
 				hh[n].Language = heartbeat.String(language.String())
 			}
 
-			return next(hh)
+			return next(ctx, hh)
 		}
 	}
 }
@@ -1,6 +1,7 @@
 package language_test
 
 import (
+	"context"
 	"fmt"
 	"testing"
 
@@ -14,7 +15,7 @@ import (
 func TestWithDetection(t *testing.T) {
 	opt := language.WithDetection()
 
-	h := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+	h := opt(func(_ context.Context, hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
 		assert.Len(t, hh, 1)
 		assert.Equal(t, heartbeat.LanguageGo.String(), *hh[0].Language)
 		assert.Equal(t, []heartbeat.Heartbeat{
@@ -32,7 +33,7 @@ func TestWithDetection(t *testing.T) {
 		}, nil
 	})
 
-	result, err := h([]heartbeat.Heartbeat{
+	result, err := h(context.Background(), []heartbeat.Heartbeat{
 		{
 			Entity:     "testdata/codefiles/golang.go",
 			EntityType: heartbeat.FileType,
@@ -50,7 +51,7 @@ func TestWithDetection(t *testing.T) {
 func TestWithDetection_Override(t *testing.T) {
 	opt := language.WithDetection()
 
-	h := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+	h := opt(func(_ context.Context, hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
 		assert.Len(t, hh, 1)
 		assert.Equal(t, heartbeat.LanguagePython.String(), *hh[0].Language)
 		assert.Equal(t, []heartbeat.Heartbeat{
@@ -68,7 +69,7 @@ func TestWithDetection_Override(t *testing.T) {
 		}, nil
 	})
 
-	result, err := h([]heartbeat.Heartbeat{
+	result, err := h(context.Background(), []heartbeat.Heartbeat{
 		{
 			Entity:     "testdata/codefiles/golang.go",
 			EntityType: heartbeat.FileType,
@@ -87,7 +88,7 @@ func TestWithDetection_Override(t *testing.T) {
 func TestWithDetection_Alternate(t *testing.T) {
 	opt := language.WithDetection()
 
-	h := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+	h := opt(func(_ context.Context, hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
 		assert.Len(t, hh, 1)
 		assert.Equal(t, []heartbeat.Heartbeat{
 			{
@@ -105,7 +106,7 @@ func TestWithDetection_Alternate(t *testing.T) {
 		}, nil
 	})
 
-	result, err := h([]heartbeat.Heartbeat{
+	result, err := h(context.Background(), []heartbeat.Heartbeat{
 		{
 			Entity:            "testdata/codefiles/unknown.xyz",
 			EntityType:        heartbeat.FileType,
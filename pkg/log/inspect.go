@@ -0,0 +1,187 @@
+package log
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// nolint:gochecknoglobals
+var (
+	templateQuotedRegex = regexp.MustCompile(`"[^"]*"|'[^']*'`)
+	templatePathRegex   = regexp.MustCompile(`(?:[a-zA-Z]:)?[/\\][^\s:,'"]+`)
+	templateNumberRegex = regexp.MustCompile(`\b\d+(\.\d+)?\b`)
+)
+
+// maxLineSize is the maximum size of a single log line, which will be parsed.
+const maxLineSize = 1024 * 1024
+
+// Entry is a single json line written by the logger.
+type Entry struct {
+	Caller  string    `json:"caller"`
+	File    string    `json:"file"`
+	Level   string    `json:"level"`
+	Message string    `json:"message"`
+	Now     time.Time `json:"now"`
+	Plugin  string    `json:"plugin"`
+}
+
+// EntryFilter defines which entries will be included in a summary.
+type EntryFilter struct {
+	// Level is the minimum level of included entries. Can be "debug", "info", "warn" or "error".
+	Level string
+	// Plugin will be matched against the plugin of an entry and if not empty, only matching entries are included.
+	Plugin string
+	// Since excludes entries logged before this time.
+	Since time.Time
+}
+
+// EntryGroup contains entries grouped by message template and plugin.
+type EntryGroup struct {
+	Count    int
+	First    time.Time
+	Last     time.Time
+	Level    string
+	Plugin   string
+	Template string
+}
+
+// ParseEntries parses json log lines from r. Lines, which cannot be parsed,
+// like those written in text format, are skipped.
+func ParseEntries(r io.Reader) ([]Entry, error) {
+	var entries []Entry
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), maxLineSize)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || !strings.HasPrefix(line, "{") {
+			continue
+		}
+
+		var entry Entry
+
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			continue
+		}
+
+		entries = append(entries, entry)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read log lines: %s", err)
+	}
+
+	return entries, nil
+}
+
+// Summarize groups entries matching the filter by message template and plugin.
+// Groups are sorted by last occurrence, most recent first.
+func Summarize(entries []Entry, filter EntryFilter) []EntryGroup {
+	minSeverity := levelSeverity(filter.Level)
+
+	var (
+		groups []*EntryGroup
+		index  = make(map[string]*EntryGroup)
+	)
+
+	for _, entry := range entries {
+		if levelSeverity(entry.Level) < minSeverity {
+			continue
+		}
+
+		if filter.Plugin != "" && !strings.Contains(entry.Plugin, filter.Plugin) {
+			continue
+		}
+
+		if !filter.Since.IsZero() && entry.Now.Before(filter.Since) {
+			continue
+		}
+
+		template := MessageTemplate(entry.Message)
+		key := entry.Plugin + "\x00" + template
+
+		group, ok := index[key]
+		if !ok {
+			group = &EntryGroup{
+				First:    entry.Now,
+				Level:    normalizeLevel(entry.Level),
+				Plugin:   entry.Plugin,
+				Template: template,
+			}
+			index[key] = group
+			groups = append(groups, group)
+		}
+
+		group.Count++
+
+		if entry.Now.Before(group.First) {
+			group.First = entry.Now
+		}
+
+		if entry.Now.After(group.Last) {
+			group.Last = entry.Now
+		}
+
+		if levelSeverity(entry.Level) > levelSeverity(group.Level) {
+			group.Level = normalizeLevel(entry.Level)
+		}
+	}
+
+	sort.SliceStable(groups, func(i, j int) bool {
+		return groups[i].Last.After(groups[j].Last)
+	})
+
+	summary := make([]EntryGroup, 0, len(groups))
+	for _, g := range groups {
+		summary = append(summary, *g)
+	}
+
+	return summary
+}
+
+// MessageTemplate replaces variable parts of a log message, like quoted
+// values, file paths and numbers, with placeholders.
+func MessageTemplate(message string) string {
+	template := templateQuotedRegex.ReplaceAllString(message, `"*"`)
+	template = templatePathRegex.ReplaceAllString(template, "<path>")
+	template = templateNumberRegex.ReplaceAllString(template, "<n>")
+
+	return template
+}
+
+// levelSeverity returns the severity of a level, following logrus level order.
+func levelSeverity(level string) int {
+	switch strings.ToLower(level) {
+	case "trace":
+		return 0
+	case "debug":
+		return 1
+	case "info", "":
+		return 2
+	case "warn", "warning":
+		return 3
+	case "error":
+		return 4
+	case "fatal":
+		return 5
+	case "panic":
+		return 6
+	default:
+		return 2
+	}
+}
+
+func normalizeLevel(level string) string {
+	if level == "warning" {
+		return "warn"
+	}
+
+	return level
+}
@@ -0,0 +1,98 @@
+package log_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/log"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEntries(t *testing.T) {
+	input := `{"caller":"run.go:248","file":"/path/to/main.go","level":"error","message":"failed","now":"2022-01-02T10:05:00Z","plugin":"vim"}
+time="2022-01-02T10:05:00Z" level=error message=failed
+{"invalid json"
+`
+
+	entries, err := log.ParseEntries(strings.NewReader(input))
+	require.NoError(t, err)
+
+	assert.Equal(t, []log.Entry{
+		{
+			Caller:  "run.go:248",
+			File:    "/path/to/main.go",
+			Level:   "error",
+			Message: "failed",
+			Now:     time.Date(2022, 1, 2, 10, 5, 0, 0, time.UTC),
+			Plugin:  "vim",
+		},
+	}, entries)
+}
+
+func TestSummarize(t *testing.T) {
+	entries := []log.Entry{
+		{Level: "error", Message: `failed to open "/a"`, Now: time.Date(2022, 1, 2, 10, 0, 0, 0, time.UTC), Plugin: "vim"},
+		{Level: "warning", Message: "missing 2 results", Now: time.Date(2022, 1, 2, 10, 10, 0, 0, time.UTC), Plugin: "vim"},
+		{Level: "error", Message: `failed to open "/b"`, Now: time.Date(2022, 1, 2, 10, 20, 0, 0, time.UTC), Plugin: "vim"},
+		{Level: "error", Message: `failed to open "/c"`, Now: time.Date(2022, 1, 2, 10, 30, 0, 0, time.UTC), Plugin: "vscode"},
+		{Level: "debug", Message: "sent", Now: time.Date(2022, 1, 2, 10, 40, 0, 0, time.UTC), Plugin: "vim"},
+	}
+
+	groups := log.Summarize(entries, log.EntryFilter{Level: "warn"})
+
+	assert.Equal(t, []log.EntryGroup{
+		{
+			Count:    1,
+			First:    time.Date(2022, 1, 2, 10, 30, 0, 0, time.UTC),
+			Last:     time.Date(2022, 1, 2, 10, 30, 0, 0, time.UTC),
+			Level:    "error",
+			Plugin:   "vscode",
+			Template: `failed to open "*"`,
+		},
+		{
+			Count:    2,
+			First:    time.Date(2022, 1, 2, 10, 0, 0, 0, time.UTC),
+			Last:     time.Date(2022, 1, 2, 10, 20, 0, 0, time.UTC),
+			Level:    "error",
+			Plugin:   "vim",
+			Template: `failed to open "*"`,
+		},
+		{
+			Count:    1,
+			First:    time.Date(2022, 1, 2, 10, 10, 0, 0, time.UTC),
+			Last:     time.Date(2022, 1, 2, 10, 10, 0, 0, time.UTC),
+			Level:    "warn",
+			Plugin:   "vim",
+			Template: "missing <n> results",
+		},
+	}, groups)
+}
+
+func TestMessageTemplate(t *testing.T) {
+	tests := map[string]struct {
+		Message  string
+		Expected string
+	}{
+		"quoted": {
+			Message:  `failed making request to "https://api.wakatime.com/api/v1": timeout`,
+			Expected: `failed making request to "*": timeout`,
+		},
+		"path": {
+			Message:  "failed to open /path/to/file.go: permission denied",
+			Expected: "failed to open <path>: permission denied",
+		},
+		"numbers": {
+			Message:  "missing 12 results from api after 1.5 secs",
+			Expected: "missing <n> results from api after <n> secs",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, log.MessageTemplate(test.Message))
+		})
+	}
+}
@@ -38,27 +38,17 @@ var (
 	Fatalln = logEntry.Fatalln
 )
 
+const (
+	// FormatJSON formats log entries as json lines.
+	FormatJSON = "json"
+	// FormatText formats log entries as human readable text.
+	FormatText = "text"
+)
+
 func new() *l.Entry {
 	entry := l.NewEntry(&l.Logger{
-		Out: os.Stdout,
-		Formatter: &l.JSONFormatter{
-			FieldMap: l.FieldMap{
-				l.FieldKeyTime: "now",
-				l.FieldKeyFile: "caller",
-				l.FieldKeyMsg:  "message",
-			},
-			DisableHTMLEscape: true,
-			CallerPrettyfier: func(f *runtime.Frame) (string, string) {
-				// Simplifies function description by removing package name from it.
-				lastSlash := strings.LastIndexByte(f.Function, '/')
-				if lastSlash < 0 {
-					lastSlash = 0
-				}
-				lastDot := strings.LastIndexByte(f.Function[lastSlash:], '.') + lastSlash
-
-				return f.Function[lastDot+1:], fmt.Sprintf("%s:%d", f.File, f.Line)
-			},
-		},
+		Out:          os.Stdout,
+		Formatter:    &redactFormatter{formatter: jsonFormatter()},
 		Level:        l.InfoLevel,
 		ExitFunc:     os.Exit,
 		ReportCaller: true,
@@ -68,6 +58,42 @@ func new() *l.Entry {
 	return entry
 }
 
+func jsonFormatter() l.Formatter {
+	return &l.JSONFormatter{
+		FieldMap: l.FieldMap{
+			l.FieldKeyTime: "now",
+			l.FieldKeyFile: "caller",
+			l.FieldKeyMsg:  "message",
+		},
+		DisableHTMLEscape: true,
+		CallerPrettyfier:  callerPrettyfier,
+	}
+}
+
+func textFormatter() l.Formatter {
+	return &l.TextFormatter{
+		FieldMap: l.FieldMap{
+			l.FieldKeyTime: "now",
+			l.FieldKeyFile: "caller",
+			l.FieldKeyMsg:  "message",
+		},
+		DisableColors:    true,
+		FullTimestamp:    true,
+		CallerPrettyfier: callerPrettyfier,
+	}
+}
+
+// callerPrettyfier simplifies function description by removing package name from it.
+func callerPrettyfier(f *runtime.Frame) (string, string) {
+	lastSlash := strings.LastIndexByte(f.Function, '/')
+	if lastSlash < 0 {
+		lastSlash = 0
+	}
+	lastDot := strings.LastIndexByte(f.Function[lastSlash:], '.') + lastSlash
+
+	return f.Function[lastDot+1:], fmt.Sprintf("%s:%d", f.File, f.Line)
+}
+
 // Output returns the current log output.
 func Output() io.Writer {
 	return logEntry.Logger.Out
@@ -87,6 +113,38 @@ func SetVerbose(verbose bool) {
 	}
 }
 
+// SetFormat sets the format of log entries. Can be FormatJSON or FormatText.
+func SetFormat(format string) error {
+	switch format {
+	case FormatJSON:
+		logEntry.Logger.SetFormatter(&redactFormatter{formatter: jsonFormatter()})
+	case FormatText:
+		logEntry.Logger.SetFormatter(&redactFormatter{formatter: textFormatter()})
+	default:
+		return fmt.Errorf("invalid log format %q", format)
+	}
+
+	return nil
+}
+
+// SetLevel sets the minimum level of logged messages. Can be "debug", "info",
+// "warn" or "error".
+func SetLevel(level string) error {
+	switch level {
+	case "debug", "info", "warn", "error":
+		parsed, err := l.ParseLevel(level)
+		if err != nil {
+			return err
+		}
+
+		logEntry.Logger.SetLevel(parsed)
+	default:
+		return fmt.Errorf("invalid log level %q", level)
+	}
+
+	return nil
+}
+
 // SetJww sets jww log when debug enabled.
 func SetJww(verbose bool, w io.Writer) {
 	if verbose {
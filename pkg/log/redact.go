@@ -0,0 +1,228 @@
+package log
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+
+	l "github.com/sirupsen/logrus"
+)
+
+const (
+	// hidden replaces secrets and credentials in log output.
+	hidden = "<hidden>"
+	// MinSecretLength is the minimum length of secrets to be masked. Shorter
+	// secrets would mask unrelated parts of log lines.
+	MinSecretLength = 6
+)
+
+// Redaction defines sensitive data, which will be masked before anything
+// is written to the log output.
+type Redaction struct {
+	// CredentialsPatterns will be matched against each token of the log output and if matching,
+	// the named group "credentials" will be masked.
+	CredentialsPatterns []*regexp.Regexp
+	// Entities will be obfuscated the same way as hidden file names are sent to the api.
+	Entities []string
+	// Names like hidden project and branch names will be masked, where found as
+	// whole words.
+	Names []string
+	// Secrets like api keys and proxy passwords will be masked, if at least
+	// MinSecretLength characters long.
+	Secrets []string
+}
+
+// nolint:gochecknoglobals
+var (
+	redaction   Redaction
+	redactionMu sync.RWMutex
+)
+
+// SetRedaction sets the sensitive data, which will be masked in all log output.
+func SetRedaction(r Redaction) {
+	redactionMu.Lock()
+	defer redactionMu.Unlock()
+
+	redaction = r
+}
+
+// AddRedactedNames adds names to the current redaction, like project and
+// branch names hidden after their detection.
+func AddRedactedNames(names ...string) {
+	redactionMu.Lock()
+	defer redactionMu.Unlock()
+
+	for _, name := range names {
+		if name != "" {
+			redaction.Names = append(redaction.Names, name)
+		}
+	}
+}
+
+// Redact masks sensitive data in s following the current redaction.
+func Redact(s string) string {
+	redactionMu.RLock()
+	defer redactionMu.RUnlock()
+
+	for _, entity := range redaction.Entities {
+		if entity == "" {
+			continue
+		}
+
+		s = replaceAll(s, entity, "HIDDEN"+filepath.Ext(entity))
+	}
+
+	for _, secret := range redaction.Secrets {
+		if len(secret) < MinSecretLength {
+			continue
+		}
+
+		s = replaceAll(s, secret, hidden)
+	}
+
+	for _, name := range redaction.Names {
+		if name == "" {
+			continue
+		}
+
+		s = replaceWord(s, name, "HIDDEN")
+	}
+
+	if len(redaction.CredentialsPatterns) > 0 {
+		s = redactCredentials(s, redaction.CredentialsPatterns)
+	}
+
+	return s
+}
+
+// replaceAll replaces old with new in s, including its json escaped form.
+func replaceAll(s, old, new string) string {
+	s = strings.ReplaceAll(s, old, new)
+
+	escaped, err := json.Marshal(old)
+	if err != nil {
+		return s
+	}
+
+	if escapedStr := string(escaped[1 : len(escaped)-1]); escapedStr != old {
+		s = strings.ReplaceAll(s, escapedStr, new)
+	}
+
+	return s
+}
+
+// replaceWord replaces old with new in s, where old is not part of a longer
+// word.
+func replaceWord(s, old, new string) string {
+	var (
+		builder strings.Builder
+		last    int
+	)
+
+	for i := 0; i <= len(s)-len(old); {
+		idx := strings.Index(s[i:], old)
+		if idx < 0 {
+			break
+		}
+
+		start, end := i+idx, i+idx+len(old)
+
+		before, _ := utf8.DecodeLastRuneInString(s[:start])
+		after, _ := utf8.DecodeRuneInString(s[end:])
+
+		if (start > 0 && isWordRune(before)) || (end < len(s) && isWordRune(after)) {
+			i = start + 1
+			continue
+		}
+
+		builder.WriteString(s[last:start])
+		builder.WriteString(new)
+
+		last, i = end, end
+	}
+
+	builder.WriteString(s[last:])
+
+	return builder.String()
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || r == '-' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// redactCredentials splits s into tokens separated by whitespace and quotes and
+// masks the credentials of each token matching one of the patterns.
+func redactCredentials(s string, patterns []*regexp.Regexp) string {
+	var (
+		builder strings.Builder
+		start   = -1
+	)
+
+	for i, r := range s + " " {
+		if !isTokenSeparator(r) {
+			if start < 0 {
+				start = i
+			}
+
+			continue
+		}
+
+		if start >= 0 {
+			builder.WriteString(redactTokenCredentials(s[start:i], patterns))
+
+			start = -1
+		}
+
+		if i < len(s) {
+			builder.WriteRune(r)
+		}
+	}
+
+	return builder.String()
+}
+
+func redactTokenCredentials(token string, patterns []*regexp.Regexp) string {
+	for _, p := range patterns {
+		idx := p.SubexpIndex("credentials")
+		if idx < 0 {
+			continue
+		}
+
+		match := p.FindStringSubmatchIndex(token)
+		if match == nil || match[2*idx] < 0 {
+			continue
+		}
+
+		return token[:match[2*idx]] + hidden + "@" + token[match[2*idx+1]:]
+	}
+
+	return token
+}
+
+func isTokenSeparator(r rune) bool {
+	switch r {
+	case ' ', '\t', '\n', '\r', '"', '\'', '`', ',':
+		return true
+	default:
+		return false
+	}
+}
+
+// redactFormatter masks sensitive data in the output of the wrapped formatter.
+type redactFormatter struct {
+	formatter l.Formatter
+}
+
+// Format implements logrus.Formatter interface.
+func (f *redactFormatter) Format(entry *l.Entry) ([]byte, error) {
+	data, err := f.formatter.Format(entry)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(Redact(string(data))), nil
+}
@@ -0,0 +1,241 @@
+package log
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/juju/mutex"
+)
+
+// rotateMutexTimeout is the maximum time to wait for the rotation mutex.
+const rotateMutexTimeout = time.Second
+
+// RotatingFile is an io.Writer appending to a log file, which gets rotated once
+// it would exceed a maximum size. Rotation is guarded by a system wide mutex and
+// detects rotations done by other processes, so multiple wakatime-cli processes
+// can safely share the same log file.
+type RotatingFile struct {
+	filepath   string
+	maxBackups int
+	maxSize    int64
+
+	mu       sync.Mutex
+	file     *os.File
+	reported bool
+}
+
+// NewRotatingFile opens the log file at filepath for appending. A maxSize of
+// zero disables rotation. At most maxBackups rotated files are kept, named
+// filepath.1 up to filepath.N with filepath.1 being the most recent one.
+func NewRotatingFile(filepath string, maxSize int64, maxBackups int) (*RotatingFile, error) {
+	f, err := openLogFile(filepath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &RotatingFile{
+		filepath:   filepath,
+		maxBackups: maxBackups,
+		maxSize:    maxSize,
+		file:       f,
+	}, nil
+}
+
+// Write implements io.Writer interface. Writing continues to the current file,
+// if rotation fails. The first rotation error is reported to stderr, as there
+// is no way to log it.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.maxSize > 0 {
+		info, err := f.file.Stat()
+		if err == nil && info.Size()+int64(len(p)) > f.maxSize {
+			if err := f.rotate(); err != nil && !f.reported {
+				f.reported = true
+
+				fmt.Fprintf(os.Stderr, "failed to rotate log file %q: %s\n", f.filepath, err)
+			}
+		}
+	}
+
+	return f.file.Write(p)
+}
+
+// Close closes the underlying log file.
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.file.Close()
+}
+
+func (f *RotatingFile) rotate() error {
+	releaser, err := mutex.Acquire(mutex.Spec{
+		Name:    "wakatime-cli-log-mutex",
+		Delay:   time.Millisecond,
+		Timeout: rotateMutexTimeout,
+		Clock:   &mutexClock{delay: time.Millisecond},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to acquire mutex: %s", err)
+	}
+
+	defer releaser.Release()
+
+	current, err := f.file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat log file: %s", err)
+	}
+
+	// another process already rotated the log file
+	if onDisk, err := os.Stat(f.filepath); err != nil || !os.SameFile(current, onDisk) {
+		return f.reopen()
+	}
+
+	// open files cannot be renamed on windows
+	if err := f.file.Close(); err != nil {
+		return fmt.Errorf("failed to close log file: %s", err)
+	}
+
+	backupErr := f.backup()
+
+	file, err := openLogFile(f.filepath)
+	if err != nil {
+		return err
+	}
+
+	f.file = file
+
+	return backupErr
+}
+
+// backup moves the content of the log file to the first backup file, after
+// shifting existing backups. Files still opened by other processes cannot be
+// renamed on windows, so the log file is copied and truncated instead. The log
+// file is truncated even if backing it up fails, to keep it below max size.
+func (f *RotatingFile) backup() error {
+	if f.maxBackups == 0 {
+		return truncateLogFile(f.filepath)
+	}
+
+	if err := removeIfExists(backupFilepath(f.filepath, f.maxBackups)); err != nil {
+		return truncateAfter(f.filepath, err)
+	}
+
+	for i := f.maxBackups - 1; i > 0; i-- {
+		if err := renameIfExists(backupFilepath(f.filepath, i), backupFilepath(f.filepath, i+1)); err != nil {
+			return truncateAfter(f.filepath, err)
+		}
+	}
+
+	if err := os.Rename(f.filepath, backupFilepath(f.filepath, 1)); err == nil {
+		return nil
+	}
+
+	if err := copyFile(f.filepath, backupFilepath(f.filepath, 1)); err != nil {
+		return truncateAfter(f.filepath, err)
+	}
+
+	return truncateLogFile(f.filepath)
+}
+
+// reopen opens the log file at its path again. Upon failure, the current
+// file handle is kept.
+func (f *RotatingFile) reopen() error {
+	file, err := openLogFile(f.filepath)
+	if err != nil {
+		return err
+	}
+
+	_ = f.file.Close()
+
+	f.file = file
+
+	return nil
+}
+
+func openLogFile(filepath string) (*os.File, error) {
+	f, err := os.OpenFile(filepath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0666)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open log file: %s", err)
+	}
+
+	return f, nil
+}
+
+// truncateAfter truncates the log file after failing to back it up and
+// returns both errors.
+func truncateAfter(filepath string, err error) error {
+	if truncateErr := truncateLogFile(filepath); truncateErr != nil {
+		return fmt.Errorf("%s, %s", err, truncateErr)
+	}
+
+	return err
+}
+
+func truncateLogFile(filepath string) error {
+	if err := os.Truncate(filepath, 0); err != nil {
+		return fmt.Errorf("failed to truncate log file: %s", err)
+	}
+
+	return nil
+}
+
+func removeIfExists(fp string) error {
+	if err := os.Remove(fp); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove backup: %s", err)
+	}
+
+	return nil
+}
+
+func renameIfExists(src, dst string) error {
+	if err := os.Rename(src, dst); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to rename backup: %s", err)
+	}
+
+	return nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src) // nolint:gosec
+	if err != nil {
+		return fmt.Errorf("failed to open log file: %s", err)
+	}
+
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0666) // nolint:gosec
+	if err != nil {
+		return fmt.Errorf("failed to create backup: %s", err)
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		_ = out.Close()
+
+		return fmt.Errorf("failed to copy log file: %s", err)
+	}
+
+	return out.Close()
+}
+
+func backupFilepath(filepath string, n int) string {
+	return fmt.Sprintf("%s.%d", filepath, n)
+}
+
+// mutexClock is used to implement mutex.Clock interface.
+type mutexClock struct {
+	delay time.Duration
+}
+
+func (mc *mutexClock) After(time.Duration) <-chan time.Time {
+	return time.After(mc.delay)
+}
+
+func (mc *mutexClock) Now() time.Time {
+	return time.Now()
+}
@@ -0,0 +1,159 @@
+package log_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/log"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotatingFile(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "wakatime.log")
+
+	f, err := log.NewRotatingFile(fp, 10, 2)
+	require.NoError(t, err)
+
+	defer f.Close()
+
+	for _, line := range []string{"first\n", "second\n", "third\n", "fourth\n"} {
+		_, err = f.Write([]byte(line))
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, "fourth\n", readFile(t, fp))
+	assert.Equal(t, "third\n", readFile(t, fp+".1"))
+	assert.Equal(t, "second\n", readFile(t, fp+".2"))
+	assert.NoFileExists(t, fp+".3")
+}
+
+func TestRotatingFile_NoBackups(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "wakatime.log")
+
+	f, err := log.NewRotatingFile(fp, 10, 0)
+	require.NoError(t, err)
+
+	defer f.Close()
+
+	for _, line := range []string{"first\n", "second\n"} {
+		_, err = f.Write([]byte(line))
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, "second\n", readFile(t, fp))
+	assert.NoFileExists(t, fp+".1")
+}
+
+func TestRotatingFile_Disabled(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "wakatime.log")
+
+	f, err := log.NewRotatingFile(fp, 0, 2)
+	require.NoError(t, err)
+
+	defer f.Close()
+
+	for i := 0; i < 10; i++ {
+		_, err = f.Write([]byte("some log line\n"))
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, strings.Repeat("some log line\n", 10), readFile(t, fp))
+	assert.NoFileExists(t, fp+".1")
+}
+
+func TestRotatingFile_RotatedByOtherProcess(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "wakatime.log")
+
+	f1, err := log.NewRotatingFile(fp, 10, 2)
+	require.NoError(t, err)
+
+	defer f1.Close()
+
+	f2, err := log.NewRotatingFile(fp, 10, 2)
+	require.NoError(t, err)
+
+	defer f2.Close()
+
+	_, err = f1.Write([]byte("first\n"))
+	require.NoError(t, err)
+
+	// f1 rotates
+	_, err = f1.Write([]byte("second\n"))
+	require.NoError(t, err)
+
+	// f2 detects rotation and must not rotate again
+	_, err = f2.Write([]byte("third\n"))
+	require.NoError(t, err)
+
+	assert.Equal(t, "second\nthird\n", readFile(t, fp))
+	assert.Equal(t, "first\n", readFile(t, fp+".1"))
+	assert.NoFileExists(t, fp+".2")
+}
+
+func TestRotatingFile_OpenedByOtherProcess(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "wakatime.log")
+
+	f, err := log.NewRotatingFile(fp, 10, 1)
+	require.NoError(t, err)
+
+	defer f.Close()
+
+	other, err := os.OpenFile(fp, os.O_APPEND|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+
+	defer other.Close()
+
+	for _, line := range []string{"first\n", "second\n"} {
+		_, err = f.Write([]byte(line))
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, "second\n", readFile(t, fp))
+	assert.Equal(t, "first\n", readFile(t, fp+".1"))
+}
+
+func TestRotatingFile_BackupFails(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "wakatime.log")
+
+	// a non-empty directory can neither be replaced nor written to
+	err := os.MkdirAll(filepath.Join(fp+".1", "dir"), 0700)
+	require.NoError(t, err)
+
+	stderr := os.Stderr
+	defer func() { os.Stderr = stderr }()
+
+	errFile, err := os.Create(filepath.Join(t.TempDir(), "stderr"))
+	require.NoError(t, err)
+
+	defer errFile.Close()
+
+	os.Stderr = errFile
+
+	f, err := log.NewRotatingFile(fp, 10, 1)
+	require.NoError(t, err)
+
+	defer f.Close()
+
+	for _, line := range []string{"first\n", "second\n", "third\n"} {
+		_, err = f.Write([]byte(line))
+		require.NoError(t, err)
+	}
+
+	// the log file stays below max size
+	assert.Equal(t, "third\n", readFile(t, fp))
+
+	output := readFile(t, errFile.Name())
+	assert.Contains(t, output, "failed to rotate log file")
+	assert.Equal(t, 1, strings.Count(output, "\n"))
+}
+
+func readFile(t *testing.T, fp string) string {
+	data, err := os.ReadFile(fp)
+	require.NoError(t, err)
+
+	return string(data)
+}
@@ -1,10 +1,12 @@
 package offline
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"math"
 	"net/http"
+	"os"
 	"path/filepath"
 	"time"
 
@@ -30,13 +32,16 @@ const (
 	// SyncMaxDefault is the default maximum number of heartbeats from the
 	// offline queue, which will be synced upon sending heartbeats to the API.
 	SyncMaxDefault = 1000
+	// queueGracePeriod is the time unsent heartbeats can still be pushed to
+	// the queue, after the context of sending them is done.
+	queueGracePeriod = 5 * time.Second
 )
 
 // Sender is a noop api client, used by offline.SaveHeartbeats.
 type Sender struct{}
 
 // SendHeartbeats always returns an error.
-func (s *Sender) SendHeartbeats(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+func (s *Sender) SendHeartbeats(_ context.Context, hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
 	return nil, api.Err("skip sending heartbeats and only save to offline db")
 }
 
@@ -45,10 +50,11 @@ func (s *Sender) SendHeartbeats(hh []heartbeat.Heartbeat) ([]heartbeat.Result, e
 // of heartbeat sending to the API. Upon inability to send due to missing or
 // failing connection to API, failed sending or errors returned by API, the
 // heartbeats will be temporarily stored in a DB and sending will be retried
-// at next usages of the wakatime cli.
+// at next usages of the wakatime cli. Heartbeats are also stored, if sending
+// is aborted, because the context is done.
 func WithQueue(filepath string) (heartbeat.HandleOption, error) {
 	return func(next heartbeat.Handle) heartbeat.Handle {
-		return func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		return func(ctx context.Context, hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
 			log.Debugf("execute offline queue with file %s", filepath)
 
 			if len(hh) == 0 {
@@ -57,11 +63,14 @@ func WithQueue(filepath string) (heartbeat.HandleOption, error) {
 				return nil, nil
 			}
 
-			results, err := next(hh)
+			results, err := next(ctx, hh)
 			if err != nil {
 				log.Debugf("pushing %d heartbeat(s) to queue due to error", len(hh))
 
-				requeueErr := pushHeartbeatsWithRetry(filepath, hh)
+				queueCtx, cancel := queueContext(ctx)
+				defer cancel()
+
+				requeueErr := pushHeartbeatsWithRetry(queueCtx, filepath, hh)
 				if requeueErr != nil {
 					return nil, ErrOfflineEnqueue(fmt.Sprintf(
 						"failed to push heatbeats to queue after api error: %s. error: %s",
@@ -72,7 +81,7 @@ func WithQueue(filepath string) (heartbeat.HandleOption, error) {
 				return nil, err
 			}
 
-			err = handleResults(filepath, results, hh)
+			err = handleResults(ctx, filepath, results, hh)
 			if err != nil {
 				return nil, fmt.Errorf("failed to handle results: %s", err)
 			}
@@ -95,8 +104,9 @@ func QueueFilepath() (string, error) {
 }
 
 // Sync returns a function to send queued heartbeats to the WakaTime API.
-func Sync(filepath string, syncLimit int) func(next heartbeat.Handle) error {
-	return func(next heartbeat.Handle) error {
+// Syncing stops, once ctx is done.
+func Sync(filepath string, syncLimit int) func(ctx context.Context, next heartbeat.Handle) error {
+	return func(ctx context.Context, next heartbeat.Handle) error {
 		log.Debugf("execute offline sync with file %s", filepath)
 
 		var (
@@ -111,6 +121,10 @@ func Sync(filepath string, syncLimit int) func(next heartbeat.Handle) error {
 				break
 			}
 
+			if err := ctx.Err(); err != nil {
+				return fmt.Errorf("aborted offline sync: %w", err)
+			}
+
 			var num = SendLimit
 
 			if alreadySent+SendLimit > syncLimit {
@@ -118,7 +132,7 @@ func Sync(filepath string, syncLimit int) func(next heartbeat.Handle) error {
 				alreadySent += num
 			}
 
-			hh, err := popHeartbeats(filepath, num)
+			hh, err := popHeartbeats(ctx, filepath, num)
 			if err != nil {
 				return fmt.Errorf("failed to fetch heartbeat from offline queue: %s", err)
 			}
@@ -131,17 +145,21 @@ func Sync(filepath string, syncLimit int) func(next heartbeat.Handle) error {
 
 			log.Debugf("send %d heartbeats on sync run %d", len(hh), run)
 
-			results, err := next(hh)
+			results, err := next(ctx, hh)
 			if err != nil {
-				requeueErr := pushHeartbeatsWithRetry(filepath, hh)
+				queueCtx, cancel := queueContext(ctx)
+
+				requeueErr := pushHeartbeatsWithRetry(queueCtx, filepath, hh)
 				if requeueErr != nil {
 					log.Warnf("failed to push heatbeats to queue after api error: %s", requeueErr)
 				}
 
+				cancel()
+
 				return err
 			}
 
-			err = handleResults(filepath, results, hh)
+			err = handleResults(ctx, filepath, results, hh)
 			if err != nil {
 				return fmt.Errorf("failed to handle heatbeats api results: %s", err)
 			}
@@ -151,12 +169,15 @@ func Sync(filepath string, syncLimit int) func(next heartbeat.Handle) error {
 	}
 }
 
-func handleResults(filepath string, results []heartbeat.Result, hh []heartbeat.Heartbeat) error {
+func handleResults(ctx context.Context, filepath string, results []heartbeat.Result, hh []heartbeat.Heartbeat) error {
 	var (
 		err               error
 		withInvalidStatus []heartbeat.Heartbeat
 	)
 
+	queueCtx, cancel := queueContext(ctx)
+	defer cancel()
+
 	// push heartbeats with invalid result status codes to queue
 	for n, result := range results {
 		if n >= len(hh) {
@@ -188,7 +209,7 @@ func handleResults(filepath string, results []heartbeat.Result, hh []heartbeat.H
 	if len(withInvalidStatus) > 0 {
 		log.Debugf("pushing %d heartbeat(s) with invalid result to queue", len(withInvalidStatus))
 
-		err = pushHeartbeatsWithRetry(filepath, withInvalidStatus)
+		err = pushHeartbeatsWithRetry(queueCtx, filepath, withInvalidStatus)
 		if err != nil {
 			log.Warnf("failed to push heatbeats with invalid status to queue: %s", err)
 		}
@@ -201,7 +222,7 @@ func handleResults(filepath string, results []heartbeat.Result, hh []heartbeat.H
 
 		start := len(hh) - leftovers
 
-		err = pushHeartbeatsWithRetry(filepath, hh[start:])
+		err = pushHeartbeatsWithRetry(queueCtx, filepath, hh[start:])
 		if err != nil {
 			log.Warnf("failed to push leftover heatbeats to queue: %s", err)
 		}
@@ -210,8 +231,8 @@ func handleResults(filepath string, results []heartbeat.Result, hh []heartbeat.H
 	return err
 }
 
-func popHeartbeats(filepath string, limit int) ([]heartbeat.Heartbeat, error) {
-	db, err := bolt.Open(filepath, 0600, &bolt.Options{Timeout: 10 * time.Minute})
+func popHeartbeats(ctx context.Context, filepath string, limit int) ([]heartbeat.Heartbeat, error) {
+	db, err := openDB(ctx, filepath, 10*time.Minute)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open db connection: %s", err)
 	}
@@ -242,7 +263,7 @@ func popHeartbeats(filepath string, limit int) ([]heartbeat.Heartbeat, error) {
 	return queued, nil
 }
 
-func pushHeartbeatsWithRetry(filepath string, hh []heartbeat.Heartbeat) error {
+func pushHeartbeatsWithRetry(ctx context.Context, filepath string, hh []heartbeat.Heartbeat) error {
 	var (
 		count int
 		err   error
@@ -263,13 +284,17 @@ func pushHeartbeatsWithRetry(filepath string, hh []heartbeat.Heartbeat) error {
 			)
 		}
 
-		err = pushHeartbeats(filepath, hh)
+		err = pushHeartbeats(ctx, filepath, hh)
 		if err != nil {
 			count++
 
 			sleepSeconds := math.Pow(2, float64(count))
 
-			time.Sleep(time.Duration(sleepSeconds) * time.Second)
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("abort requeuing: %s. heartbeats: %d", err, len(hh))
+			case <-time.After(time.Duration(sleepSeconds) * time.Second):
+			}
 
 			continue
 		}
@@ -280,8 +305,8 @@ func pushHeartbeatsWithRetry(filepath string, hh []heartbeat.Heartbeat) error {
 	return nil
 }
 
-func pushHeartbeats(filepath string, hh []heartbeat.Heartbeat) error {
-	db, err := bolt.Open(filepath, 0600, &bolt.Options{Timeout: 10 * time.Minute})
+func pushHeartbeats(ctx context.Context, filepath string, hh []heartbeat.Heartbeat) error {
+	db, err := openDB(ctx, filepath, 10*time.Minute)
 	if err != nil {
 		return fmt.Errorf("failed to open db connection: %s", err)
 	}
@@ -307,6 +332,63 @@ func pushHeartbeats(filepath string, hh []heartbeat.Heartbeat) error {
 	return nil
 }
 
+// openDB opens the bolt db at filepath, waiting up to timeout for the file
+// lock. Waiting is aborted, once ctx is done.
+func openDB(ctx context.Context, filepath string, timeout time.Duration) (*bolt.DB, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	type opened struct {
+		db  *bolt.DB
+		err error
+	}
+
+	ch := make(chan opened, 1)
+
+	go func() {
+		db, err := bolt.Open(filepath, 0600, &bolt.Options{Timeout: timeout})
+		ch <- opened{db: db, err: err}
+	}()
+
+	select {
+	case o := <-ch:
+		return o.db, o.err
+	case <-ctx.Done():
+		// release the db, once opened after all
+		go func() {
+			if o := <-ch; o.err == nil {
+				_ = o.db.Close()
+			}
+		}()
+
+		return nil, ctx.Err()
+	}
+}
+
+// queueContext returns the context for pushing unsent heartbeats to the queue.
+// It is done queueGracePeriod after ctx, so heartbeats are still queued after
+// sending has been aborted.
+func queueContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	queueCtx, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-queueCtx.Done():
+			return
+		}
+
+		select {
+		case <-time.After(queueGracePeriod):
+			cancel()
+		case <-queueCtx.Done():
+		}
+	}()
+
+	return queueCtx, cancel
+}
+
 // CountHeartbeats returns the total number of heartbeats in the offline db.
 func CountHeartbeats(filepath string) (int, error) {
 	db, err := bolt.Open(filepath, 0600, &bolt.Options{Timeout: 30 * time.Second})
@@ -340,6 +422,110 @@ func CountHeartbeats(filepath string) (int, error) {
 	return count, nil
 }
 
+// QueueStats contains statistics about the offline db.
+type QueueStats struct {
+	Count    int     `json:"count"`
+	FileSize int64   `json:"file_size"`
+	Oldest   float64 `json:"oldest,omitempty"`
+	Newest   float64 `json:"newest,omitempty"`
+}
+
+// Stats returns statistics about the offline db. A missing db file is
+// reported as an empty queue, instead of creating it.
+func Stats(filepath string) (QueueStats, error) {
+	info, err := os.Stat(filepath)
+	if os.IsNotExist(err) {
+		return QueueStats{}, nil
+	}
+
+	if err != nil {
+		return QueueStats{}, fmt.Errorf("failed to stat db file: %s", err)
+	}
+
+	db, err := bolt.Open(filepath, 0600, &bolt.Options{Timeout: 30 * time.Second, ReadOnly: true})
+	if err != nil {
+		return QueueStats{}, fmt.Errorf("failed to open db connection: %s", err)
+	}
+
+	defer db.Close()
+
+	stats := QueueStats{FileSize: info.Size()}
+
+	err = db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(dbBucket))
+		if b == nil {
+			return nil
+		}
+
+		return b.ForEach(func(_, value []byte) error {
+			var h heartbeat.Heartbeat
+
+			if err := json.Unmarshal(value, &h); err != nil {
+				return fmt.Errorf("failed to json unmarshal heartbeat data: %s", err)
+			}
+
+			stats.Count++
+
+			if stats.Oldest == 0 || h.Time < stats.Oldest {
+				stats.Oldest = h.Time
+			}
+
+			if h.Time > stats.Newest {
+				stats.Newest = h.Time
+			}
+
+			return nil
+		})
+	})
+	if err != nil {
+		return QueueStats{}, err
+	}
+
+	return stats, nil
+}
+
+// ReadHeartbeats returns all heartbeats in the offline db, oldest first,
+// without removing them. A missing db file is reported as an empty queue,
+// instead of creating it.
+func ReadHeartbeats(filepath string) ([]heartbeat.Heartbeat, error) {
+	if _, err := os.Stat(filepath); os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	db, err := bolt.Open(filepath, 0600, &bolt.Options{Timeout: 30 * time.Second, ReadOnly: true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open db connection: %s", err)
+	}
+
+	defer db.Close()
+
+	var heartbeats []heartbeat.Heartbeat
+
+	err = db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(dbBucket))
+		if b == nil {
+			return nil
+		}
+
+		return b.ForEach(func(_, value []byte) error {
+			var h heartbeat.Heartbeat
+
+			if err := json.Unmarshal(value, &h); err != nil {
+				return fmt.Errorf("failed to json unmarshal heartbeat data: %s", err)
+			}
+
+			heartbeats = append(heartbeats, h)
+
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return heartbeats, nil
+}
+
 // Queue is a db client to temporarily store heartbeats in bolt db, in case heartbeat
 // sending to wakatime api is not possible. Transaction handling is left to the user
 // via the passed in transaction.
@@ -1,6 +1,7 @@
 package offline_test
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -81,7 +82,7 @@ func TestWithQueue(t *testing.T) {
 	opt, err := offline.WithQueue(f.Name())
 	require.NoError(t, err)
 
-	handle := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+	handle := opt(func(_ context.Context, hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
 		assert.Len(t, hh, 2)
 		assert.Contains(t, hh, testHeartbeats()[0])
 		assert.Contains(t, hh, testHeartbeats()[1])
@@ -99,7 +100,7 @@ func TestWithQueue(t *testing.T) {
 	})
 
 	// run
-	results, err := handle([]heartbeat.Heartbeat{
+	results, err := handle(context.Background(), []heartbeat.Heartbeat{
 		testHeartbeats()[0],
 		testHeartbeats()[1],
 	})
@@ -154,7 +155,7 @@ func TestWithQueue_ApiError(t *testing.T) {
 	opt, err := offline.WithQueue(f.Name())
 	require.NoError(t, err)
 
-	handle := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+	handle := opt(func(_ context.Context, hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
 		assert.Equal(t, hh, []heartbeat.Heartbeat{
 			testHeartbeats()[0],
 			testHeartbeats()[1],
@@ -164,7 +165,7 @@ func TestWithQueue_ApiError(t *testing.T) {
 	})
 
 	// run
-	_, err = handle([]heartbeat.Heartbeat{
+	_, err = handle(context.Background(), []heartbeat.Heartbeat{
 		testHeartbeats()[0],
 		testHeartbeats()[1],
 	})
@@ -207,6 +208,39 @@ func TestWithQueue_ApiError(t *testing.T) {
 	assert.JSONEq(t, string(dataPy), stored[1].Heartbeat)
 }
 
+func TestWithQueue_ContextCanceled(t *testing.T) {
+	// setup
+	f, err := os.CreateTemp(t.TempDir(), "")
+	require.NoError(t, err)
+
+	defer f.Close()
+
+	opt, err := offline.WithQueue(f.Name())
+	require.NoError(t, err)
+
+	handle := opt(func(ctx context.Context, _ []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		return nil, ctx.Err()
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// run
+	_, err = handle(ctx, []heartbeat.Heartbeat{
+		testHeartbeats()[0],
+		testHeartbeats()[1],
+	})
+	require.Error(t, err)
+
+	assert.True(t, errors.Is(err, context.Canceled))
+
+	// check
+	stored, err := offline.ReadHeartbeats(f.Name())
+	require.NoError(t, err)
+
+	assert.Len(t, stored, 2)
+}
+
 func TestWithQueue_InvalidResults(t *testing.T) {
 	// setup
 	f, err := os.CreateTemp(t.TempDir(), "")
@@ -217,7 +251,7 @@ func TestWithQueue_InvalidResults(t *testing.T) {
 	opt, err := offline.WithQueue(f.Name())
 	require.NoError(t, err)
 
-	handle := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+	handle := opt(func(_ context.Context, hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
 		assert.Equal(t, hh, testHeartbeats())
 
 		return []heartbeat.Result{
@@ -237,7 +271,7 @@ func TestWithQueue_InvalidResults(t *testing.T) {
 	})
 
 	// run
-	results, err := handle(testHeartbeats())
+	results, err := handle(context.Background(), testHeartbeats())
 	require.NoError(t, err)
 
 	// check
@@ -303,7 +337,7 @@ func TestWithQueue_HandleLeftovers(t *testing.T) {
 	opt, err := offline.WithQueue(f.Name())
 	require.NoError(t, err)
 
-	handle := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+	handle := opt(func(_ context.Context, hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
 		assert.Equal(t, hh, testHeartbeats())
 
 		return []heartbeat.Result{
@@ -315,7 +349,7 @@ func TestWithQueue_HandleLeftovers(t *testing.T) {
 	})
 
 	// run
-	results, err := handle(testHeartbeats())
+	results, err := handle(context.Background(), testHeartbeats())
 	require.NoError(t, err)
 
 	// check
@@ -395,7 +429,7 @@ func TestSync(t *testing.T) {
 
 	var numCalls int
 
-	err = syncFn(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+	err = syncFn(context.Background(), func(_ context.Context, hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
 		numCalls++
 
 		assert.Equal(t, []heartbeat.Heartbeat{
@@ -470,7 +504,7 @@ func TestSync_MultipleRequests(t *testing.T) {
 	var numCalls int
 
 	// run
-	err = syncFn(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+	err = syncFn(context.Background(), func(_ context.Context, hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
 		numCalls++
 
 		// first request
@@ -567,7 +601,7 @@ func TestSync_APIError(t *testing.T) {
 	var numCalls int
 
 	// run
-	err = syncFn(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+	err = syncFn(context.Background(), func(_ context.Context, hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
 		numCalls++
 
 		assert.Equal(t, []heartbeat.Heartbeat{
@@ -612,6 +646,50 @@ func TestSync_APIError(t *testing.T) {
 	assert.Eventually(t, func() bool { return numCalls == 1 }, time.Second, 50*time.Millisecond)
 }
 
+func TestSync_ContextCanceled(t *testing.T) {
+	// setup
+	f, err := os.CreateTemp(t.TempDir(), "")
+	require.NoError(t, err)
+
+	defer f.Close()
+
+	db, err := bolt.Open(f.Name(), 0600, nil)
+	require.NoError(t, err)
+
+	dataGo, err := os.ReadFile("testdata/heartbeat_go.json")
+	require.NoError(t, err)
+
+	insertHeartbeatRecords(t, db, "heartbeats", []heartbeatRecord{
+		{
+			ID:        "1592868367.219124-file-coding-wakatime-cli-heartbeat-/tmp/main.go-true",
+			Heartbeat: string(dataGo),
+		},
+	})
+
+	db.Close()
+
+	syncFn := offline.Sync(f.Name(), 1000)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// run
+	err = syncFn(ctx, func(_ context.Context, _ []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		t.Fatal("heartbeats must not be sent")
+
+		return nil, nil
+	})
+	require.Error(t, err)
+
+	assert.True(t, errors.Is(err, context.Canceled))
+
+	// check
+	count, err := offline.CountHeartbeats(f.Name())
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, count)
+}
+
 func TestSync_InvalidResults(t *testing.T) {
 	// setup
 	f, err := os.CreateTemp(t.TempDir(), "")
@@ -653,7 +731,7 @@ func TestSync_InvalidResults(t *testing.T) {
 	var numCalls int
 
 	// run
-	err = syncFn(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+	err = syncFn(context.Background(), func(_ context.Context, hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
 		numCalls++
 
 		// first request
@@ -760,7 +838,7 @@ func TestSync_SyncLimit(t *testing.T) {
 	var numCalls int
 
 	// run
-	err = syncFn(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+	err = syncFn(context.Background(), func(_ context.Context, hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
 		numCalls++
 
 		assert.Len(t, hh, 1)
@@ -1008,6 +1086,111 @@ func TestQueue_Count(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestStats(t *testing.T) {
+	// setup
+	f, err := os.CreateTemp(t.TempDir(), "")
+	require.NoError(t, err)
+
+	defer f.Close()
+
+	db, err := bolt.Open(f.Name(), 0600, nil)
+	require.NoError(t, err)
+
+	dataGo, err := os.ReadFile("testdata/heartbeat_go.json")
+	require.NoError(t, err)
+
+	dataPy, err := os.ReadFile("testdata/heartbeat_py.json")
+	require.NoError(t, err)
+
+	insertHeartbeatRecords(t, db, "heartbeats", []heartbeatRecord{
+		{
+			ID:        "1592868367.219124-file-coding-wakatime-cli-heartbeat-/tmp/main.go-true",
+			Heartbeat: string(dataGo),
+		},
+		{
+			ID:        "1592868386.079084-file-debugging-wakatime-summary-/tmp/main.py-false",
+			Heartbeat: string(dataPy),
+		},
+	})
+
+	err = db.Close()
+	require.NoError(t, err)
+
+	// run
+	stats, err := offline.Stats(f.Name())
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, stats.Count)
+	assert.Greater(t, stats.FileSize, int64(0))
+	assert.Equal(t, 1592868367.219124, stats.Oldest)
+	assert.Equal(t, 1592868386.079084, stats.Newest)
+}
+
+func TestStats_NoFile(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "missing.bdb")
+
+	stats, err := offline.Stats(fp)
+	require.NoError(t, err)
+
+	assert.Equal(t, offline.QueueStats{}, stats)
+	assert.NoFileExists(t, fp)
+}
+
+func TestReadHeartbeats(t *testing.T) {
+	// setup
+	f, err := os.CreateTemp(t.TempDir(), "")
+	require.NoError(t, err)
+
+	defer f.Close()
+
+	db, err := bolt.Open(f.Name(), 0600, nil)
+	require.NoError(t, err)
+
+	dataGo, err := os.ReadFile("testdata/heartbeat_go.json")
+	require.NoError(t, err)
+
+	dataPy, err := os.ReadFile("testdata/heartbeat_py.json")
+	require.NoError(t, err)
+
+	insertHeartbeatRecords(t, db, "heartbeats", []heartbeatRecord{
+		{
+			ID:        "1592868367.219124-file-coding-wakatime-cli-heartbeat-/tmp/main.go-true",
+			Heartbeat: string(dataGo),
+		},
+		{
+			ID:        "1592868386.079084-file-debugging-wakatime-summary-/tmp/main.py-false",
+			Heartbeat: string(dataPy),
+		},
+	})
+
+	err = db.Close()
+	require.NoError(t, err)
+
+	// run
+	heartbeats, err := offline.ReadHeartbeats(f.Name())
+	require.NoError(t, err)
+
+	require.Len(t, heartbeats, 2)
+	assert.Equal(t, "/tmp/main.go", heartbeats[0].Entity)
+	assert.Equal(t, "/tmp/main.py", heartbeats[1].Entity)
+
+	// heartbeats are kept
+	count, err := offline.CountHeartbeats(f.Name())
+	require.NoError(t, err)
+
+	assert.Equal(t, 2, count)
+}
+
+func TestReadHeartbeats_NoFile(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "missing.bdb")
+
+	heartbeats, err := offline.ReadHeartbeats(fp)
+	require.NoError(t, err)
+
+	assert.Empty(t, heartbeats)
+	assert.NoFileExists(t, fp)
+}
+
 func initDB(t *testing.T) (*bolt.DB, func()) {
 	// create tmp file
 	f, err := os.CreateTemp(t.TempDir(), "")
@@ -0,0 +1,127 @@
+package project
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/wakatime/wakatime-cli/pkg/log"
+)
+
+// nolint:gochecknoglobals
+var bzrNicknameRegex = regexp.MustCompile(`^nickname\s*=\s*(.+)$`)
+
+// Bazaar contains bazaar data.
+type Bazaar struct {
+	// Filepath contains the entity path.
+	Filepath string
+}
+
+// Detect gets information about the bazaar project for a given file.
+// Branches of a shared repository are named after the repository folder.
+func (b Bazaar) Detect(_ context.Context) (Result, bool, error) {
+	log.Debugln("execute bazaar project detection")
+
+	fp := b.Filepath
+
+	// Take only the directory
+	if fileExists(fp) {
+		fp = filepath.Dir(fp)
+	}
+
+	bzrDirectory, ok := FindFileOrDirectory(fp, ".bzr")
+	if !ok {
+		return Result{}, false, nil
+	}
+
+	root := filepath.Dir(bzrDirectory)
+	project := filepath.Base(root)
+
+	// standalone branches have their own repository
+	if !fileExists(filepath.Join(bzrDirectory, "repository")) {
+		if sharedRepo, ok := FindFileOrDirectory(filepath.Dir(root), ".bzr"); ok &&
+			fileExists(filepath.Join(sharedRepo, "repository")) {
+			project = filepath.Base(filepath.Dir(sharedRepo))
+		}
+	}
+
+	branch, err := findBzrBranch(bzrDirectory)
+	if err != nil {
+		log.Errorf(
+			"error finding for branch name from %q: %s",
+			bzrDirectory,
+			err,
+		)
+	}
+
+	return Result{
+		Project: project,
+		Branch:  firstNonEmptyString(branch, filepath.Base(root)),
+		Folder:  root,
+	}, true, nil
+}
+
+// findBzrBranch returns the nickname of a branch, or the folder name of the
+// branch a checkout references.
+func findBzrBranch(bzrDirectory string) (string, error) {
+	conf := filepath.Join(bzrDirectory, "branch", "branch.conf")
+	if fileExists(conf) {
+		var nickname string
+
+		err := scanLines(conf, func(line string) bool {
+			if matches := bzrNicknameRegex.FindStringSubmatch(line); matches != nil {
+				nickname = strings.Trim(strings.TrimSpace(matches[1]), `"`)
+				return false
+			}
+
+			return true
+		})
+		if err != nil {
+			return "", Err(fmt.Sprintf("failed to read %q: %s", conf, err))
+		}
+
+		if nickname != "" {
+			return nickname, nil
+		}
+	}
+
+	location := filepath.Join(bzrDirectory, "branch", "location")
+	if !fileExists(location) {
+		return "", nil
+	}
+
+	lines, err := readFile(location, 1)
+	if err != nil {
+		return "", Err(fmt.Sprintf("failed while opening file %q: %s", location, err))
+	}
+
+	if len(lines) == 0 {
+		return "", nil
+	}
+
+	return bzrLocationName(strings.TrimSpace(lines[0])), nil
+}
+
+// bzrLocationName returns the last path element of a branch location url,
+// like "file:///srv/bzr/project/trunk/".
+func bzrLocationName(location string) string {
+	if u, err := url.Parse(location); err == nil && u.Scheme != "" && len(u.Scheme) > 1 {
+		location = u.Path
+	}
+
+	name := path.Base(strings.TrimRight(filepath.ToSlash(location), "/"))
+	if name == "." || name == "/" {
+		return ""
+	}
+
+	return name
+}
+
+// String returns its name.
+func (Bazaar) String() string {
+	return "bzr-detector"
+}
@@ -0,0 +1,108 @@
+package project_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/project"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBazaar_Detect(t *testing.T) {
+	fp := setupTestBazaar(t, "wakatime-cli")
+
+	err := os.MkdirAll(filepath.Join(fp, "wakatime-cli/.bzr/repository"), os.FileMode(int(0700)))
+	require.NoError(t, err)
+
+	copyFile(t, "testdata/bzr/branch.conf", filepath.Join(fp, "wakatime-cli/.bzr/branch/branch.conf"))
+
+	b := project.Bazaar{
+		Filepath: filepath.Join(fp, "wakatime-cli/src/pkg/file.go"),
+	}
+
+	result, detected, err := b.Detect(context.Background())
+	require.NoError(t, err)
+
+	assert.True(t, detected)
+	assert.Equal(t, project.Result{
+		Project: "wakatime-cli",
+		Branch:  "feature/bzr",
+		Folder:  filepath.Join(fp, "wakatime-cli"),
+	}, result)
+}
+
+func TestBazaar_Detect_NoNickname(t *testing.T) {
+	fp := setupTestBazaar(t, "wakatime-cli")
+
+	b := project.Bazaar{
+		Filepath: filepath.Join(fp, "wakatime-cli/src/pkg/file.go"),
+	}
+
+	result, detected, err := b.Detect(context.Background())
+	require.NoError(t, err)
+
+	assert.True(t, detected)
+	assert.Equal(t, project.Result{
+		Project: "wakatime-cli",
+		Branch:  "wakatime-cli",
+		Folder:  filepath.Join(fp, "wakatime-cli"),
+	}, result)
+}
+
+func TestBazaar_Detect_LightweightCheckout(t *testing.T) {
+	fp := setupTestBazaar(t, "wakatime-cli")
+
+	copyFile(t, "testdata/bzr/location", filepath.Join(fp, "wakatime-cli/.bzr/branch/location"))
+
+	b := project.Bazaar{
+		Filepath: filepath.Join(fp, "wakatime-cli/src/pkg/file.go"),
+	}
+
+	result, detected, err := b.Detect(context.Background())
+	require.NoError(t, err)
+
+	assert.True(t, detected)
+	assert.Equal(t, "trunk", result.Branch)
+}
+
+func TestBazaar_Detect_SharedRepository(t *testing.T) {
+	fp := setupTestBazaar(t, "wakatime-cli/feature")
+
+	err := os.MkdirAll(filepath.Join(fp, "wakatime-cli/.bzr/repository"), os.FileMode(int(0700)))
+	require.NoError(t, err)
+
+	b := project.Bazaar{
+		Filepath: filepath.Join(fp, "wakatime-cli/feature/src/pkg/file.go"),
+	}
+
+	result, detected, err := b.Detect(context.Background())
+	require.NoError(t, err)
+
+	assert.True(t, detected)
+	assert.Equal(t, project.Result{
+		Project: "wakatime-cli",
+		Branch:  "feature",
+		Folder:  filepath.Join(fp, "wakatime-cli/feature"),
+	}, result)
+}
+
+func setupTestBazaar(t *testing.T, branchDir string) (fp string) {
+	tmpDir := t.TempDir()
+
+	err := os.MkdirAll(filepath.Join(tmpDir, branchDir, "src/pkg"), os.FileMode(int(0700)))
+	require.NoError(t, err)
+
+	err = os.MkdirAll(filepath.Join(tmpDir, branchDir, ".bzr/branch"), os.FileMode(int(0700)))
+	require.NoError(t, err)
+
+	tmpFile, err := os.Create(filepath.Join(tmpDir, branchDir, "src/pkg/file.go"))
+	require.NoError(t, err)
+
+	defer tmpFile.Close()
+
+	return tmpDir
+}
@@ -2,12 +2,17 @@ package project
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
 	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/regex"
+
+	"gopkg.in/ini.v1"
 )
 
 // File contains file data.
@@ -17,35 +22,248 @@ type File struct {
 
 // Detect get information from a .wakatime-project file about the project for
 // a given file. First line of .wakatime-project sets the project
-// name. Second line sets the current branch name.
-func (f File) Detect() (Result, bool, error) {
+// name. Second line sets the current branch name. Alternatively, the file is
+// structured as ini with [project] and [settings] sections, see FileConfig.
+func (f File) Detect(_ context.Context) (Result, bool, error) {
 	log.Debugln("execute file project detection")
 
-	fp, ok := FindFileOrDirectory(f.Filepath, WakaTimeProjectFile)
+	config, ok, err := FindFileConfig(f.Filepath)
+	if err != nil {
+		return Result{}, false, Err(fmt.Sprintf("error reading file: %s", err))
+	}
+
 	if !ok {
 		return Result{}, false, nil
 	}
 
+	result, ok := config.result()
+
+	return result, ok, nil
+}
+
+// FileConfig contains the contents of a .wakatime-project file.
+type FileConfig struct {
+	// Project is the project name. Structured files may use it as template
+	// with {project}, {branch} and {folder} placeholders.
+	Project string
+	// Branch overrides the detected branch.
+	Branch string
+	// Category replaces the default category of heartbeats.
+	Category *heartbeat.Category
+	// Folder is the folder of the file.
+	Folder string
+	// Settings are added to the configured tracking settings.
+	Settings heartbeat.ProjectSettings
+	// Structured is true, if the file is ini structured.
+	Structured bool
+}
+
+// FindFileConfig finds the .wakatime-project file of an entity and parses it.
+func FindFileConfig(entity string) (FileConfig, bool, error) {
+	fp, ok := FindFileOrDirectory(entity, WakaTimeProjectFile)
+	if !ok {
+		return FileConfig{}, false, nil
+	}
+
 	log.Debugf("wakatime project file found at: %s", fp)
 
-	lines, err := readFile(fp, 2)
+	config, err := ParseFile(fp)
 	if err != nil {
-		return Result{}, false, Err(fmt.Sprintf("error reading file: %s", err))
+		return FileConfig{}, false, err
 	}
 
-	result := Result{
-		Folder: filepath.Dir(fp),
+	config.Folder = filepath.Dir(fp)
+
+	return config, true, nil
+}
+
+// ParseFile parses a .wakatime-project file. Files starting with a [project]
+// or [settings] section header are parsed as ini:
+//
+//	[project]
+//	name = {project}-{branch}
+//	branch = main
+//
+//	[settings]
+//	category = code reviewing
+//	hide_file_names = true
+//	hide_project_folder = true
+//	exclude = /vendor/
+//	include = /vendor/wakatime/
+//
+// Otherwise, the first line is the project name and the second line the branch.
+func ParseFile(fp string) (FileConfig, error) {
+	var structured bool
+
+	// skip empty and comment lines before the first section header
+	err := scanLines(fp, func(line string) bool {
+		if line == "" || strings.HasPrefix(line, ";") || strings.HasPrefix(line, "#") {
+			return true
+		}
+
+		// project names may start with a bracket, too
+		switch strings.ToLower(strings.Join(strings.Fields(line), "")) {
+		case "[project]", "[settings]":
+			structured = true
+		}
+
+		return false
+	})
+	if err != nil {
+		return FileConfig{}, Err(err.Error())
+	}
+
+	if structured {
+		return parseStructuredFile(fp)
 	}
 
+	lines, err := readFile(fp, 2)
+	if err != nil {
+		return FileConfig{}, err
+	}
+
+	var config FileConfig
+
 	if len(lines) > 0 {
-		result.Project = strings.TrimSpace(lines[0])
+		config.Project = strings.TrimSpace(lines[0])
 	}
 
 	if len(lines) > 1 {
-		result.Branch = strings.TrimSpace(lines[1])
+		config.Branch = strings.TrimSpace(lines[1])
 	}
 
-	return result, true, nil
+	return config, nil
+}
+
+func parseStructuredFile(fp string) (FileConfig, error) {
+	file, err := ini.LoadSources(ini.LoadOptions{AllowPythonMultilineValues: true}, fp)
+	if err != nil {
+		return FileConfig{}, Err(fmt.Sprintf("failed to parse ini file %q: %s", fp, err))
+	}
+
+	project := file.Section("project")
+	settings := file.Section("settings")
+
+	config := FileConfig{
+		Project:    strings.TrimSpace(project.Key("name").String()),
+		Branch:     strings.TrimSpace(project.Key("branch").String()),
+		Structured: true,
+	}
+
+	if s := strings.TrimSpace(settings.Key("category").String()); s != "" {
+		category, err := heartbeat.ParseCategory(s)
+		if err != nil {
+			return FileConfig{}, Err(fmt.Sprintf("failed to parse category: %s", err))
+		}
+
+		config.Category = &category
+	}
+
+	for key, patterns := range map[string]*[]regex.Regex{
+		"exclude":           &config.Settings.Exclude,
+		"include":           &config.Settings.Include,
+		"hide_branch_names": &config.Settings.BranchPatterns,
+		"hide_file_names":   &config.Settings.FilePatterns,
+	} {
+		if !settings.HasKey(key) {
+			continue
+		}
+
+		*patterns, err = parseBoolOrRegexList(settings.Key(key).String())
+		if err != nil {
+			return FileConfig{}, Err(fmt.Sprintf("failed to parse %s: %s", key, err))
+		}
+	}
+
+	if settings.HasKey("hide_project_folder") {
+		hide, err := settings.Key("hide_project_folder").Bool()
+		if err != nil {
+			return FileConfig{}, Err(fmt.Sprintf("failed to parse hide_project_folder: %s", err))
+		}
+
+		config.Settings.HideProjectFolder = hide
+	}
+
+	return config, nil
+}
+
+// FormatProject formats the project name template of a structured file.
+// Returns the project name as is for two-line files. {project} defaults to
+// the folder name.
+func (c FileConfig) FormatProject(project, branch, folder string) string {
+	if !c.Structured {
+		return c.Project
+	}
+
+	return strings.NewReplacer(
+		"{project}", firstNonEmptyString(project, filepath.Base(folder)),
+		"{branch}", branch,
+		"{folder}", filepath.Base(folder),
+	).Replace(c.Project)
+}
+
+// IsTemplate returns true, if the project name of a structured file contains
+// placeholders.
+func (c FileConfig) IsTemplate() bool {
+	return c.Structured && strings.Contains(c.Project, "{")
+}
+
+// result returns the project and branch of the file. Structured files may
+// only set tracking settings.
+func (c FileConfig) result() (Result, bool) {
+	if c.Structured && c.Project == "" && c.Branch == "" {
+		return Result{}, false
+	}
+
+	return Result{
+		Project: c.Project,
+		Branch:  c.Branch,
+		Folder:  c.Folder,
+	}, true
+}
+
+// apply replaces the default category of a heartbeat and adds the tracking
+// settings to it.
+func (c FileConfig) apply(h heartbeat.Heartbeat) heartbeat.Heartbeat {
+	if c.Category != nil && h.Category == heartbeat.CodingCategory {
+		h.Category = *c.Category
+	}
+
+	settings := c.Settings
+	h.ProjectSettings = &settings
+
+	return h
+}
+
+// parseBoolOrRegexList parses "true", "false" or a newline separated list
+// of regex patterns. "false" and empty values return no patterns.
+func parseBoolOrRegexList(s string) ([]regex.Regex, error) {
+	s = strings.Trim(strings.ReplaceAll(s, "\r", "\n"), "\n\t ")
+
+	switch strings.ToLower(s) {
+	case "", "false":
+		return nil, nil
+	case "true":
+		return []regex.Regex{regex.MustCompile(".*")}, nil
+	}
+
+	var patterns []regex.Regex
+
+	for _, line := range strings.Split(s, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+
+		compiled, err := regex.Compile(line)
+		if err != nil {
+			return nil, fmt.Errorf("failed to compile regex %q: %s", line, err)
+		}
+
+		patterns = append(patterns, compiled)
+	}
+
+	return patterns, nil
 }
 
 // fileExists checks if a file or directory exist.
@@ -54,6 +272,12 @@ func fileExists(fp string) bool {
 	return err == nil || os.IsExist(err)
 }
 
+// isDir checks if a directory exists.
+func isDir(fp string) bool {
+	info, err := os.Stat(fp)
+	return err == nil && info.IsDir()
+}
+
 // readFile reads a file until max number of lines and return an array of lines.
 func readFile(fp string, max int) ([]string, error) {
 	if fp == "" {
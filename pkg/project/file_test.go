@@ -1,11 +1,14 @@
 package project_test
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
 
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
 	"github.com/wakatime/wakatime-cli/pkg/project"
+	"github.com/wakatime/wakatime-cli/pkg/regex"
 	"github.com/yookoala/realpath"
 
 	"github.com/stretchr/testify/assert"
@@ -20,7 +23,7 @@ func TestFile_Detect_FileExists(t *testing.T) {
 		Filepath: rp,
 	}
 
-	result, detected, err := f.Detect()
+	result, detected, err := f.Detect(context.Background())
 	require.NoError(t, err)
 
 	expected := project.Result{
@@ -52,7 +55,7 @@ func TestFile_Detect_ParentFolderExists(t *testing.T) {
 		Filepath: dir,
 	}
 
-	result, detected, err := f.Detect()
+	result, detected, err := f.Detect(context.Background())
 	require.NoError(t, err)
 
 	expected := project.Result{
@@ -77,7 +80,7 @@ func TestFile_Detect_NoFileFound(t *testing.T) {
 		Filepath: tmpDir,
 	}
 
-	result, detected, err := f.Detect()
+	result, detected, err := f.Detect(context.Background())
 	require.NoError(t, err)
 
 	expected := project.Result{}
@@ -96,12 +99,118 @@ func TestFile_Detect_InvalidPath(t *testing.T) {
 		Filepath: tmpFile.Name(),
 	}
 
-	_, detected, err := f.Detect()
+	_, detected, err := f.Detect(context.Background())
 	require.NoError(t, err)
 
 	assert.False(t, detected)
 }
 
+func TestFile_Detect_Structured(t *testing.T) {
+	tmpDir, err := realpath.Realpath(t.TempDir())
+	require.NoError(t, err)
+
+	copyFile(t, "testdata/.wakatime-project-structured", filepath.Join(tmpDir, ".wakatime-project"))
+
+	f := project.File{
+		Filepath: filepath.Join(tmpDir, "main.go"),
+	}
+
+	result, detected, err := f.Detect(context.Background())
+	require.NoError(t, err)
+
+	assert.True(t, detected)
+	assert.Equal(t, project.Result{
+		Branch:  "release",
+		Folder:  tmpDir,
+		Project: "{project}-{branch}",
+	}, result)
+}
+
+func TestFile_Detect_StructuredWithoutName(t *testing.T) {
+	tmpDir, err := realpath.Realpath(t.TempDir())
+	require.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(tmpDir, ".wakatime-project"), []byte("[settings]\nhide_file_names = true\n"), 0600)
+	require.NoError(t, err)
+
+	f := project.File{
+		Filepath: filepath.Join(tmpDir, "main.go"),
+	}
+
+	_, detected, err := f.Detect(context.Background())
+	require.NoError(t, err)
+
+	assert.False(t, detected)
+}
+
+func TestParseFile(t *testing.T) {
+	config, err := project.ParseFile("testdata/.wakatime-project-structured")
+	require.NoError(t, err)
+
+	assert.True(t, config.Structured)
+	assert.Equal(t, "{project}-{branch}", config.Project)
+	assert.Equal(t, "release", config.Branch)
+	assert.Equal(t, heartbeat.CodeReviewingCategory, *config.Category)
+	assert.Equal(t, heartbeat.ProjectSettings{
+		Exclude:           []regex.Regex{regex.MustCompile("/vendor/")},
+		Include:           []regex.Regex{regex.MustCompile("/vendor/wakatime/")},
+		FilePatterns:      []regex.Regex{regex.MustCompile("/secret/"), regex.MustCompile(`\.env$`)},
+		HideProjectFolder: true,
+	}, config.Settings)
+
+	assert.Equal(t, "wakatime-cli-release", config.FormatProject("wakatime-cli", "release", "/path/to/folder"))
+	assert.Equal(t, "folder-release", config.FormatProject("", "release", "/path/to/folder"))
+}
+
+func TestParseFile_TwoLines(t *testing.T) {
+	config, err := project.ParseFile("testdata/.wakatime-project")
+	require.NoError(t, err)
+
+	assert.Equal(t, project.FileConfig{
+		Project: "wakatime-cli",
+		Branch:  "master",
+	}, config)
+
+	assert.Equal(t, "wakatime-cli", config.FormatProject("other", "main", "/path/to/folder"))
+}
+
+func TestParseFile_TwoLinesWithBracket(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), ".wakatime-project")
+
+	err := os.WriteFile(fp, []byte("[wip] wakatime-cli\nmaster\n"), 0600)
+	require.NoError(t, err)
+
+	config, err := project.ParseFile(fp)
+	require.NoError(t, err)
+
+	assert.Equal(t, project.FileConfig{
+		Project: "[wip] wakatime-cli",
+		Branch:  "master",
+	}, config)
+}
+
+func TestParseFile_FalseAddsNoPatterns(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), ".wakatime-project")
+
+	err := os.WriteFile(fp, []byte("[settings]\nhide_file_names = false\nhide_branch_names =\nexclude = false\n"), 0600)
+	require.NoError(t, err)
+
+	config, err := project.ParseFile(fp)
+	require.NoError(t, err)
+
+	assert.Equal(t, heartbeat.ProjectSettings{}, config.Settings)
+}
+
+func TestParseFile_InvalidCategory(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), ".wakatime-project")
+
+	err := os.WriteFile(fp, []byte("[settings]\ncategory = sleeping\n"), 0600)
+	require.NoError(t, err)
+
+	_, err = project.ParseFile(fp)
+	require.Error(t, err)
+}
+
 func TestFindFileOrDirectory(t *testing.T) {
 	tmpDir := t.TempDir()
 
@@ -1,6 +1,7 @@
 package project
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
@@ -18,7 +19,7 @@ type FilterConfig struct {
 // the provided configurations.
 func WithFiltering(config FilterConfig) heartbeat.HandleOption {
 	return func(next heartbeat.Handle) heartbeat.Handle {
-		return func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		return func(ctx context.Context, hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
 			log.Debugln("execute project filtering")
 
 			var filtered []heartbeat.Heartbeat
@@ -39,7 +40,7 @@ func WithFiltering(config FilterConfig) heartbeat.HandleOption {
 				return []heartbeat.Result{}, nil
 			}
 
-			return next(filtered)
+			return next(ctx, filtered)
 		}
 	}
 }
@@ -0,0 +1,170 @@
+package project
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/sqlite"
+)
+
+// Fossil contains fossil data.
+type Fossil struct {
+	// Filepath contains the entity path.
+	Filepath string
+}
+
+// Detect gets information about the fossil project for a given file.
+// The branch is read from the checkout and repository databases.
+func (f Fossil) Detect(_ context.Context) (Result, bool, error) {
+	log.Debugln("execute fossil project detection")
+
+	fp := f.Filepath
+
+	// Take only the directory
+	if fileExists(fp) {
+		fp = filepath.Dir(fp)
+	}
+
+	checkoutFile, ok := findFossilCheckout(fp)
+	if !ok {
+		return Result{}, false, nil
+	}
+
+	root := filepath.Dir(checkoutFile)
+
+	branch, err := findFossilBranch(checkoutFile, root)
+	if err != nil {
+		log.Errorf(
+			"error finding for branch name from %q: %s",
+			checkoutFile,
+			err,
+		)
+	}
+
+	return Result{
+		Project: filepath.Base(root),
+		Branch:  branch,
+		Folder:  root,
+	}, true, nil
+}
+
+// findFossilCheckout finds the nearest checkout database, named .fslckout,
+// or _FOSSIL_ on windows.
+func findFossilCheckout(fp string) (string, bool) {
+	checkoutFile, ok := FindFileOrDirectory(fp, ".fslckout")
+
+	legacyFile, legacyOk := FindFileOrDirectory(fp, "_FOSSIL_")
+	if legacyOk && (!ok || len(legacyFile) > len(checkoutFile)) {
+		return legacyFile, true
+	}
+
+	return checkoutFile, ok
+}
+
+// findFossilBranch returns the branch tag of the checked out check-in.
+// Without sqlite indexes, tables are scanned, reading pages until the first
+// match. The tag table is small and its "branch" row is created with the
+// repository. tagxref grows with the history, but its rows of a check-in are
+// inserted with the check-in, so it's scanned backwards and few pages are read
+// for recent check-ins, like the usual checkout.
+func findFossilBranch(checkoutFile, root string) (string, error) {
+	vars, err := readFossilVars(checkoutFile)
+	if err != nil {
+		return "", err
+	}
+
+	rid, err := strconv.ParseInt(vars["checkout"], 10, 64)
+	if err != nil {
+		return "", Err(fmt.Sprintf("invalid checkout %q: %s", vars["checkout"], err))
+	}
+
+	repository := vars["repository"]
+	if repository == "" {
+		return "", Err("repository not found")
+	}
+
+	if !filepath.IsAbs(repository) {
+		repository = filepath.Join(root, repository)
+	}
+
+	db, err := sqlite.Open(repository)
+	if err != nil {
+		return "", Err(fmt.Sprintf("failed to open repository %q: %s", repository, err))
+	}
+
+	defer db.Close()
+
+	var tagID int64
+
+	err = db.Scan("tag", func(row sqlite.Row) bool {
+		if row.String("tagname") == "branch" {
+			tagID = row.Int("tagid")
+			return false
+		}
+
+		return true
+	})
+	if err != nil {
+		return "", Err(fmt.Sprintf("failed to read tags: %s", err))
+	}
+
+	if tagID == 0 {
+		return "", nil
+	}
+
+	var branch string
+
+	// (tagid, rid) is unique, so scanning stops at the first match
+	err = db.ScanReverse("tagxref", func(row sqlite.Row) bool {
+		if row.Int("rid") == rid && row.Int("tagid") == tagID {
+			// tagtype 0 cancels a tag
+			if row.Int("tagtype") > 0 {
+				branch = row.String("value")
+			}
+
+			return false
+		}
+
+		return true
+	})
+	if err != nil {
+		return "", Err(fmt.Sprintf("failed to read tag references: %s", err))
+	}
+
+	return branch, nil
+}
+
+// readFossilVars returns the variables of a checkout database.
+func readFossilVars(checkoutFile string) (map[string]string, error) {
+	db, err := sqlite.Open(checkoutFile)
+	if err != nil {
+		return nil, Err(fmt.Sprintf("failed to open checkout %q: %s", checkoutFile, err))
+	}
+
+	defer db.Close()
+
+	vars := make(map[string]string)
+
+	err = db.Scan("vvar", func(row sqlite.Row) bool {
+		if value, ok := row["value"].(int64); ok {
+			vars[row.String("name")] = strconv.FormatInt(value, 10)
+		} else {
+			vars[row.String("name")] = row.String("value")
+		}
+
+		return true
+	})
+	if err != nil {
+		return nil, Err(fmt.Sprintf("failed to read checkout variables: %s", err))
+	}
+
+	return vars, nil
+}
+
+// String returns its name.
+func (Fossil) String() string {
+	return "fossil-detector"
+}
@@ -0,0 +1,75 @@
+package project_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/project"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFossil_Detect(t *testing.T) {
+	tests := map[string]string{
+		"checkout":        ".fslckout",
+		"legacy checkout": "_FOSSIL_",
+	}
+
+	for name, checkoutFile := range tests {
+		t.Run(name, func(t *testing.T) {
+			fp := setupTestFossil(t, checkoutFile)
+
+			f := project.Fossil{
+				Filepath: filepath.Join(fp, "wakatime-cli/src/pkg/file.go"),
+			}
+
+			result, detected, err := f.Detect(context.Background())
+			require.NoError(t, err)
+
+			assert.True(t, detected)
+			assert.Equal(t, project.Result{
+				Project: "wakatime-cli",
+				Branch:  "feature/fossil",
+				Folder:  filepath.Join(fp, "wakatime-cli"),
+			}, result)
+		})
+	}
+}
+
+func TestFossil_Detect_MissingRepository(t *testing.T) {
+	fp := setupTestFossil(t, ".fslckout")
+
+	err := os.Remove(filepath.Join(fp, "wakatime-cli.fossil"))
+	require.NoError(t, err)
+
+	f := project.Fossil{
+		Filepath: filepath.Join(fp, "wakatime-cli/src/pkg/file.go"),
+	}
+
+	result, detected, err := f.Detect(context.Background())
+	require.NoError(t, err)
+
+	assert.True(t, detected)
+	assert.Equal(t, "wakatime-cli", result.Project)
+	assert.Empty(t, result.Branch)
+}
+
+func setupTestFossil(t *testing.T, checkoutFile string) (fp string) {
+	tmpDir := t.TempDir()
+
+	err := os.MkdirAll(filepath.Join(tmpDir, "wakatime-cli/src/pkg"), os.FileMode(int(0700)))
+	require.NoError(t, err)
+
+	tmpFile, err := os.Create(filepath.Join(tmpDir, "wakatime-cli/src/pkg/file.go"))
+	require.NoError(t, err)
+
+	defer tmpFile.Close()
+
+	copyFile(t, "testdata/fossil/fslckout", filepath.Join(tmpDir, "wakatime-cli", checkoutFile))
+	copyFile(t, "testdata/fossil/wakatime-cli.fossil", filepath.Join(tmpDir, "wakatime-cli.fossil"))
+
+	return tmpDir
+}
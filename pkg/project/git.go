@@ -1,25 +1,44 @@
 package project
 
 import (
+	"context"
 	"fmt"
 	"path/filepath"
+	"regexp"
 	"strings"
 
 	"github.com/wakatime/wakatime-cli/pkg/log"
 	"github.com/wakatime/wakatime-cli/pkg/regex"
 )
 
+// nolint:gochecknoglobals
+var (
+	gitSectionRegex = regexp.MustCompile(`^\[\s*([^\s"\]]+)(?:\s+"([^"]*)")?\s*\]`)
+	gitURLRegex     = regexp.MustCompile(`^url\s*=\s*(.+)$`)
+)
+
 // Git contains git data.
 type Git struct {
 	// Filepath contains the entity path.
 	Filepath string
 	// SubmodulePatterns will be matched against the submodule path and if matching, will skip it.
 	SubmodulePatterns []regex.Regex
+	// Remote configures naming projects after a remote url, instead of the folder name.
+	Remote GitRemote
+}
+
+// GitRemote configures naming projects after the url of a git remote.
+type GitRemote struct {
+	// Name of the remote. Empty disables naming projects after the remote.
+	Name string
+	// Template formats the project name from {host}, {owner} and {repo}.
+	// Defaults to "{repo}".
+	Template string
 }
 
 // Detect gets information about the git project for a given file.
-// It tries to return a project and branch name.
-func (g Git) Detect() (Result, bool, error) {
+// It tries to return a project and branch name, and the commit of HEAD.
+func (g Git) Detect(_ context.Context) (Result, bool, error) {
 	log.Debugln("execute git project detection")
 
 	fp := g.Filepath
@@ -36,9 +55,9 @@ func (g Git) Detect() (Result, bool, error) {
 	}
 
 	if ok {
-		project := filepath.Base(gitdirSubmodule)
+		project := g.projectName(gitdirSubmodule, filepath.Base(gitdirSubmodule))
 
-		branch, err := findGitBranch(filepath.Join(gitdirSubmodule, "HEAD"))
+		head, err := findGitHead(gitdirSubmodule, gitdirSubmodule)
 		if err != nil {
 			log.Errorf(
 				"error finding for branch name from %q: %s",
@@ -49,7 +68,8 @@ func (g Git) Detect() (Result, bool, error) {
 
 		return Result{
 			Project: project,
-			Branch:  branch,
+			Branch:  head.Branch,
+			Commit:  head.Commit,
 			Folder:  filepath.Dir(gitdirSubmodule),
 		}, true, nil
 	}
@@ -61,7 +81,7 @@ func (g Git) Detect() (Result, bool, error) {
 		gitDir := filepath.Dir(gitConfigFile)
 		projectDir := filepath.Join(gitDir, "..")
 
-		branch, err := findGitBranch(filepath.Join(gitDir, "HEAD"))
+		head, err := findGitHead(gitDir, gitDir)
 		if err != nil {
 			log.Errorf(
 				"error finding for branch name from %q: %s",
@@ -71,8 +91,9 @@ func (g Git) Detect() (Result, bool, error) {
 		}
 
 		return Result{
-			Project: filepath.Base(projectDir),
-			Branch:  branch,
+			Project: g.projectName(gitDir, filepath.Base(projectDir)),
+			Branch:  head.Branch,
+			Commit:  head.Commit,
 			Folder:  projectDir,
 		}, true, nil
 	}
@@ -97,9 +118,9 @@ func (g Git) Detect() (Result, bool, error) {
 	}
 
 	if ok {
-		project := filepath.Base(filepath.Dir(commondir))
+		project := g.projectName(commondir, filepath.Base(filepath.Dir(commondir)))
 
-		branch, err := findGitBranch(filepath.Join(gitdir, "HEAD"))
+		head, err := findGitHead(gitdir, commondir)
 		if err != nil {
 			log.Errorf(
 				"error finding for branch name from %q: %s",
@@ -110,16 +131,17 @@ func (g Git) Detect() (Result, bool, error) {
 
 		return Result{
 			Project: project,
-			Branch:  branch,
+			Branch:  head.Branch,
+			Commit:  head.Commit,
 			Folder:  filepath.Dir(commondir),
 		}, true, nil
 	}
 
 	if gitdir != "" {
 		// Otherwise it's only a plain .git file
-		project := filepath.Base(filepath.Join(gitConfigFile, ".."))
+		project := g.projectName(gitdir, filepath.Base(filepath.Join(gitConfigFile, "..")))
 
-		branch, err := findGitBranch(filepath.Join(gitdir, "HEAD"))
+		head, err := findGitHead(gitdir, gitdir)
 		if err != nil {
 			log.Errorf(
 				"error finding for branch name from %q: %s",
@@ -130,7 +152,8 @@ func (g Git) Detect() (Result, bool, error) {
 
 		return Result{
 			Project: project,
-			Branch:  branch,
+			Branch:  head.Branch,
+			Commit:  head.Commit,
 			Folder:  filepath.Join(gitdir, ".."),
 		}, true, nil
 	}
@@ -138,6 +161,132 @@ func (g Git) Detect() (Result, bool, error) {
 	return Result{}, false, nil
 }
 
+// projectName returns the project name formatted from the remote url of the
+// gitdir's config, if enabled. Otherwise returns the folder name.
+func (g Git) projectName(gitdir, folderName string) string {
+	if g.Remote.Name == "" {
+		return folderName
+	}
+
+	url, err := findRemoteURL(filepath.Join(gitdir, "config"), g.Remote.Name)
+	if err != nil {
+		log.Warnf("failed to find url of git remote %q: %s", g.Remote.Name, err)
+		return folderName
+	}
+
+	if url == "" {
+		log.Debugf("git remote %q not found in %q", g.Remote.Name, gitdir)
+		return folderName
+	}
+
+	project := formatRemoteProject(url, g.Remote.Template)
+	if project == "" {
+		log.Debugf("failed to format project name from git remote url %q", url)
+		return folderName
+	}
+
+	return project
+}
+
+// findRemoteURL returns the url of the named remote from a git config file.
+func findRemoteURL(fp, remote string) (string, error) {
+	if !fileExists(fp) {
+		return "", nil
+	}
+
+	var (
+		url     string
+		current bool
+	)
+
+	err := scanLines(fp, func(line string) bool {
+		if matches := gitSectionRegex.FindStringSubmatch(line); matches != nil {
+			current = strings.EqualFold(matches[1], "remote") && matches[2] == remote
+			return true
+		}
+
+		if !current {
+			return true
+		}
+
+		if matches := gitURLRegex.FindStringSubmatch(line); matches != nil {
+			url = strings.Trim(strings.TrimSpace(matches[1]), `"`)
+			return false
+		}
+
+		return true
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return url, nil
+}
+
+// formatRemoteProject formats a project name from a remote url like
+// "https://github.com/owner/repo.git" or "git@github.com:owner/repo.git".
+// Nested owners, like gitlab subgroups, are kept as "group/subgroup".
+func formatRemoteProject(url, template string) string {
+	if template == "" {
+		template = "{repo}"
+	}
+
+	host, urlPath := splitRemoteURL(url)
+
+	urlPath = strings.TrimSuffix(strings.Trim(urlPath, "/"), ".git")
+	if urlPath == "" {
+		return ""
+	}
+
+	owner, repo := "", urlPath
+	if i := strings.LastIndex(urlPath, "/"); i >= 0 {
+		owner, repo = urlPath[:i], urlPath[i+1:]
+	}
+
+	project := strings.NewReplacer(
+		"{host}", host,
+		"{owner}", owner,
+		"{repo}", repo,
+	).Replace(template)
+
+	return strings.Trim(strings.ReplaceAll(project, "//", "/"), "/")
+}
+
+// splitRemoteURL splits a remote url into host and path. Local paths have
+// no host.
+func splitRemoteURL(url string) (string, string) {
+	if i := strings.Index(url, "://"); i >= 0 {
+		rest := url[i+3:]
+
+		slash := strings.Index(rest, "/")
+		if slash < 0 {
+			return hostOnly(rest), ""
+		}
+
+		return hostOnly(rest[:slash]), rest[slash:]
+	}
+
+	// scp-like syntax: [user@]host:path. Single letters are windows drives.
+	if colon := strings.Index(url, ":"); colon > 1 && !strings.ContainsAny(url[:colon], `/\`) {
+		return hostOnly(url[:colon]), url[colon+1:]
+	}
+
+	return "", filepath.ToSlash(url)
+}
+
+// hostOnly strips user info and port from a host.
+func hostOnly(host string) string {
+	if i := strings.LastIndex(host, "@"); i >= 0 {
+		host = host[i+1:]
+	}
+
+	if i := strings.Index(host, ":"); i >= 0 {
+		host = host[:i]
+	}
+
+	return host
+}
+
 func findSubmodule(fp string, patterns []regex.Regex) (string, bool, error) {
 	if !shouldTakeSubmodule(fp, patterns) {
 		return "", false, nil
@@ -241,23 +390,6 @@ func resolveCommondir(fp string) (string, bool, error) {
 	return "", false, nil
 }
 
-func findGitBranch(fp string) (string, error) {
-	if !fileExists(fp) {
-		return "master", nil
-	}
-
-	lines, err := readFile(fp, 1)
-	if err != nil {
-		return "", Err(fmt.Sprintf("failed while opening file %q: %s", fp, err))
-	}
-
-	if len(lines) > 0 && strings.HasPrefix(strings.TrimSpace(lines[0]), "ref: ") {
-		return strings.TrimSpace(strings.SplitN(lines[0], "/", 3)[2]), nil
-	}
-
-	return "", nil
-}
-
 // String returns its name.
 func (g Git) String() string {
 	return "git-detector"
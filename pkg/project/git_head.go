@@ -0,0 +1,336 @@
+package project
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/wakatime/wakatime-cli/pkg/log"
+)
+
+// maxSymbolicRefDepth is the maximum number of symbolic refs followed, like git does.
+const maxSymbolicRefDepth = 5
+
+// nolint:gochecknoglobals
+var gitSHARegex = regexp.MustCompile(`^(?:[0-9a-f]{40}|[0-9a-f]{64})$`)
+
+// gitHead contains the checked out branch and commit of a git directory.
+type gitHead struct {
+	Branch string
+	Commit string
+}
+
+// findGitHead resolves the HEAD of gitdir to a branch and commit, by parsing
+// files only. Refs are read from commondir, which differs from gitdir for
+// worktrees only. During a rebase or bisect, the branch being rebased or
+// bisected is returned. Otherwise a detached HEAD is resolved to a branch or
+// tag pointing at the same commit.
+func findGitHead(gitdir, commondir string) (gitHead, error) {
+	fp := filepath.Join(gitdir, "HEAD")
+	if !fileExists(fp) {
+		return gitHead{Branch: "master"}, nil
+	}
+
+	lines, err := readFile(fp, 1)
+	if err != nil {
+		return gitHead{}, Err(fmt.Sprintf("failed while opening file %q: %s", fp, err))
+	}
+
+	if len(lines) == 0 {
+		return gitHead{}, nil
+	}
+
+	head := strings.TrimSpace(lines[0])
+
+	if strings.HasPrefix(head, "ref: ") {
+		ref := strings.TrimSpace(strings.TrimPrefix(head, "ref: "))
+
+		if fileExists(filepath.Join(gitdir, "MERGE_HEAD")) {
+			log.Debugf("git merge in progress on %q", ref)
+		}
+
+		commit, err := resolveGitRef(gitdir, commondir, ref)
+		if err != nil {
+			log.Debugf("failed to resolve git ref %q: %s", ref, err)
+		}
+
+		return gitHead{
+			Branch: shortRefName(ref),
+			Commit: commit,
+		}, nil
+	}
+
+	if !gitSHARegex.MatchString(head) {
+		return gitHead{}, nil
+	}
+
+	branch, err := findOperationBranch(gitdir)
+	if err != nil {
+		log.Debugf("failed to find branch of git operation in progress: %s", err)
+	}
+
+	if branch == "" {
+		branch, err = findRefName(commondir, head)
+		if err != nil {
+			return gitHead{Commit: head}, Err(fmt.Sprintf("failed to find ref of detached head: %s", err))
+		}
+	}
+
+	return gitHead{
+		Branch: branch,
+		Commit: head,
+	}, nil
+}
+
+// findOperationBranch returns the branch of a rebase or bisect in progress,
+// both of which detach HEAD.
+func findOperationBranch(gitdir string) (string, error) {
+	for _, dir := range []string{"rebase-merge", "rebase-apply"} {
+		fp := filepath.Join(gitdir, dir, "head-name")
+		if !fileExists(fp) {
+			continue
+		}
+
+		lines, err := readFile(fp, 1)
+		if err != nil {
+			return "", fmt.Errorf("failed while opening file %q: %s", fp, err)
+		}
+
+		// "detached HEAD", when rebasing a detached HEAD
+		if len(lines) > 0 && strings.HasPrefix(lines[0], "refs/") {
+			log.Debugf("git rebase in progress on %q", lines[0])
+
+			return shortRefName(strings.TrimSpace(lines[0])), nil
+		}
+	}
+
+	if !fileExists(filepath.Join(gitdir, "BISECT_LOG")) {
+		return "", nil
+	}
+
+	fp := filepath.Join(gitdir, "BISECT_START")
+	if !fileExists(fp) {
+		return "", nil
+	}
+
+	lines, err := readFile(fp, 1)
+	if err != nil {
+		return "", fmt.Errorf("failed while opening file %q: %s", fp, err)
+	}
+
+	// a commit, when bisecting from a detached HEAD
+	if len(lines) == 0 || gitSHARegex.MatchString(strings.TrimSpace(lines[0])) {
+		return "", nil
+	}
+
+	log.Debugf("git bisect in progress on %q", lines[0])
+
+	return shortRefName(strings.TrimSpace(lines[0])), nil
+}
+
+// resolveGitRef returns the commit of a ref, following symbolic refs. Per
+// worktree refs are read from gitdir, all others from commondir.
+func resolveGitRef(gitdir, commondir, ref string) (string, error) {
+	var packed map[string]string
+
+	for i := 0; i < maxSymbolicRefDepth; i++ {
+		dir := commondir
+		if !strings.HasPrefix(ref, "refs/") || strings.HasPrefix(ref, "refs/bisect/") {
+			dir = gitdir
+		}
+
+		value, ok, err := readLooseRef(filepath.Join(dir, filepath.FromSlash(ref)))
+		if err != nil {
+			return "", err
+		}
+
+		if !ok {
+			if packed == nil {
+				packed, _, err = readPackedRefs(commondir)
+				if err != nil {
+					return "", err
+				}
+			}
+
+			return packed[ref], nil
+		}
+
+		if !strings.HasPrefix(value, "ref: ") {
+			return value, nil
+		}
+
+		ref = strings.TrimSpace(strings.TrimPrefix(value, "ref: "))
+	}
+
+	return "", fmt.Errorf("max %d symbolic refs followed", maxSymbolicRefDepth)
+}
+
+// findRefName returns the short name of a branch, tag or remote branch
+// pointing at commit, in this order of precedence. Annotated tags match by
+// their peeled commit.
+func findRefName(commondir, commit string) (string, error) {
+	refs, peeled, err := readPackedRefs(commondir)
+	if err != nil {
+		return "", err
+	}
+
+	// loose refs are more recent than packed refs of the same name
+	err = filepath.WalkDir(filepath.Join(commondir, "refs"), func(fp string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+
+			return err
+		}
+
+		if d.IsDir() {
+			return nil
+		}
+
+		value, ok, err := readLooseRef(fp)
+		if err != nil || !ok || !gitSHARegex.MatchString(value) {
+			return err
+		}
+
+		rel, err := filepath.Rel(commondir, fp)
+		if err != nil {
+			return err
+		}
+
+		ref := filepath.ToSlash(rel)
+		refs[ref] = value
+
+		delete(peeled, ref)
+
+		return nil
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to read refs: %s", err)
+	}
+
+	var names []string
+
+	for ref, value := range refs {
+		if value == commit || peeled[ref] == commit {
+			names = append(names, ref)
+		}
+	}
+
+	sort.Slice(names, func(i, j int) bool {
+		if refPrecedence(names[i]) != refPrecedence(names[j]) {
+			return refPrecedence(names[i]) < refPrecedence(names[j])
+		}
+
+		return names[i] < names[j]
+	})
+
+	for _, name := range names {
+		if refPrecedence(name) < 3 {
+			return shortRefName(name), nil
+		}
+	}
+
+	return "", nil
+}
+
+// refPrecedence orders refs by branches, tags, remote branches and others.
+func refPrecedence(ref string) int {
+	switch {
+	case strings.HasPrefix(ref, "refs/heads/"):
+		return 0
+	case strings.HasPrefix(ref, "refs/tags/"):
+		return 1
+	case strings.HasPrefix(ref, "refs/remotes/") && !strings.HasSuffix(ref, "/HEAD"):
+		return 2
+	default:
+		return 3
+	}
+}
+
+// shortRefName strips "refs/heads/", "refs/tags/" and "refs/remotes/<remote>/"
+// from a ref name.
+func shortRefName(ref string) string {
+	switch {
+	case strings.HasPrefix(ref, "refs/heads/"):
+		return strings.TrimPrefix(ref, "refs/heads/")
+	case strings.HasPrefix(ref, "refs/tags/"):
+		return strings.TrimPrefix(ref, "refs/tags/")
+	case strings.HasPrefix(ref, "refs/remotes/"):
+		parts := strings.SplitN(ref, "/", 4)
+		if len(parts) == 4 {
+			return parts[3]
+		}
+	}
+
+	return ref
+}
+
+// readLooseRef returns the first line of a loose ref file.
+func readLooseRef(fp string) (string, bool, error) {
+	if !fileExists(fp) {
+		return "", false, nil
+	}
+
+	lines, err := readFile(fp, 1)
+	if err != nil {
+		return "", false, fmt.Errorf("failed while opening file %q: %s", fp, err)
+	}
+
+	if len(lines) == 0 {
+		return "", false, nil
+	}
+
+	return strings.TrimSpace(lines[0]), true, nil
+}
+
+// readPackedRefs returns the commits of the packed-refs file by ref name, and
+// the peeled commits of annotated tags.
+func readPackedRefs(commondir string) (map[string]string, map[string]string, error) {
+	var (
+		refs   = make(map[string]string)
+		peeled = make(map[string]string)
+		last   string
+	)
+
+	fp := filepath.Join(commondir, "packed-refs")
+
+	if _, err := os.Stat(fp); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return refs, peeled, nil
+		}
+
+		return nil, nil, fmt.Errorf("failed to stat %q: %s", fp, err)
+	}
+
+	err := scanLines(fp, func(line string) bool {
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+		case strings.HasPrefix(line, "^"):
+			if last != "" {
+				peeled[last] = strings.TrimPrefix(line, "^")
+			}
+		default:
+			fields := strings.Fields(line)
+			if len(fields) != 2 || !gitSHARegex.MatchString(fields[0]) {
+				last = ""
+				return true
+			}
+
+			refs[fields[1]] = fields[0]
+			last = fields[1]
+		}
+
+		return true
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return refs, peeled, nil
+}
@@ -1,6 +1,7 @@
 package project_test
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -24,7 +25,7 @@ func TestGit_Detect(t *testing.T) {
 		Filepath: filepath.Join(fp, "wakatime-cli/src/pkg/file.go"),
 	}
 
-	result, detected, err := g.Detect()
+	result, detected, err := g.Detect(context.Background())
 	require.NoError(t, err)
 
 	assert.True(t, detected)
@@ -43,7 +44,7 @@ func TestGit_Detect_BranchWithSlash(t *testing.T) {
 		Filepath: filepath.Join(fp, "wakatime-cli/src/pkg/file.go"),
 	}
 
-	result, detected, err := g.Detect()
+	result, detected, err := g.Detect(context.Background())
 	require.NoError(t, err)
 
 	assert.True(t, detected)
@@ -62,7 +63,7 @@ func TestGit_Detect_DetachedHead(t *testing.T) {
 		Filepath: filepath.Join(fp, "wakatime-cli/src/pkg/file.go"),
 	}
 
-	result, detected, err := g.Detect()
+	result, detected, err := g.Detect(context.Background())
 	require.NoError(t, err)
 
 	assert.True(t, detected)
@@ -70,10 +71,133 @@ func TestGit_Detect_DetachedHead(t *testing.T) {
 	assert.Equal(t, project.Result{
 		Project: "wakatime-cli",
 		Branch:  "",
+		Commit:  "f4f242d698fa07c298592a66d6546ac9b6b34d1e",
 		Folder:  result.Folder,
 	}, result)
 }
 
+func TestGit_Detect_DetachedHead_Refs(t *testing.T) {
+	tests := map[string]struct {
+		Head     string
+		Setup    func(t *testing.T, gitdir string)
+		Expected string
+	}{
+		"annotated tag": {
+			Head:     "testdata/git_basic/HEAD_DETACHED",
+			Expected: "v1.0.0",
+		},
+		"tag": {
+			Head:     "testdata/git_refs/HEAD_DETACHED_TAG",
+			Expected: "v1.0.1",
+		},
+		"remote branch": {
+			Head:     "testdata/git_refs/HEAD_DETACHED_REMOTE",
+			Expected: "ci",
+		},
+		"loose branch before tag": {
+			Head: "testdata/git_basic/HEAD_DETACHED",
+			Setup: func(t *testing.T, gitdir string) {
+				writeLooseRef(t, gitdir, "refs/heads/hotfix", "f4f242d698fa07c298592a66d6546ac9b6b34d1e")
+			},
+			Expected: "hotfix",
+		},
+		"rebase": {
+			Head: "testdata/git_basic/HEAD_DETACHED",
+			Setup: func(t *testing.T, gitdir string) {
+				err := os.Mkdir(filepath.Join(gitdir, "rebase-merge"), os.FileMode(int(0700)))
+				require.NoError(t, err)
+
+				copyFile(t, "testdata/git_refs/rebase-head-name", filepath.Join(gitdir, "rebase-merge", "head-name"))
+			},
+			Expected: "feature/rebase",
+		},
+		"bisect": {
+			Head: "testdata/git_basic/HEAD_DETACHED",
+			Setup: func(t *testing.T, gitdir string) {
+				copyFile(t, "testdata/git_refs/BISECT_LOG", filepath.Join(gitdir, "BISECT_LOG"))
+				copyFile(t, "testdata/git_refs/BISECT_START", filepath.Join(gitdir, "BISECT_START"))
+			},
+			Expected: "feature/bisect",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fp := setupTestGitBasic(t)
+			gitdir := filepath.Join(fp, "wakatime-cli/.git")
+
+			copyFile(t, test.Head, filepath.Join(gitdir, "HEAD"))
+			copyFile(t, "testdata/git_refs/packed-refs", filepath.Join(gitdir, "packed-refs"))
+
+			if test.Setup != nil {
+				test.Setup(t, gitdir)
+			}
+
+			g := project.Git{
+				Filepath: filepath.Join(fp, "wakatime-cli/src/pkg/file.go"),
+			}
+
+			result, detected, err := g.Detect(context.Background())
+			require.NoError(t, err)
+
+			assert.True(t, detected)
+			assert.Equal(t, test.Expected, result.Branch)
+			assert.NotEmpty(t, result.Commit)
+		})
+	}
+}
+
+func TestGit_Detect_Commit(t *testing.T) {
+	tests := map[string]struct {
+		Setup    func(t *testing.T, gitdir string)
+		Expected string
+	}{
+		"packed ref": {
+			Expected: "1f5e4e1e9ed0c4dd3d2dfd0ea0e9de2f9c6e1c10",
+		},
+		"loose ref": {
+			Setup: func(t *testing.T, gitdir string) {
+				writeLooseRef(t, gitdir, "refs/heads/master", "4d5b84b6a6fdaa4e5d70b9b31a0e3e3e0d6bd0a4")
+			},
+			Expected: "4d5b84b6a6fdaa4e5d70b9b31a0e3e3e0d6bd0a4",
+		},
+		"merge": {
+			Setup: func(t *testing.T, gitdir string) {
+				copyFile(t, "testdata/git_refs/MERGE_HEAD", filepath.Join(gitdir, "MERGE_HEAD"))
+			},
+			Expected: "1f5e4e1e9ed0c4dd3d2dfd0ea0e9de2f9c6e1c10",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fp := setupTestGitBasic(t)
+			gitdir := filepath.Join(fp, "wakatime-cli/.git")
+
+			copyFile(t, "testdata/git_refs/packed-refs", filepath.Join(gitdir, "packed-refs"))
+
+			if test.Setup != nil {
+				test.Setup(t, gitdir)
+			}
+
+			g := project.Git{
+				Filepath: filepath.Join(fp, "wakatime-cli/src/pkg/file.go"),
+			}
+
+			result, detected, err := g.Detect(context.Background())
+			require.NoError(t, err)
+
+			assert.True(t, detected)
+			assert.Equal(t, project.Result{
+				Project: "wakatime-cli",
+				Branch:  "master",
+				Commit:  test.Expected,
+				Folder:  result.Folder,
+			}, result)
+		})
+	}
+}
+
 func TestGit_Detect_GitConfigFile_File(t *testing.T) {
 	fp := setupTestGitFile(t)
 
@@ -101,7 +225,7 @@ func TestGit_Detect_GitConfigFile_File(t *testing.T) {
 				Filepath: test.Filepath,
 			}
 
-			result, detected, err := g.Detect()
+			result, detected, err := g.Detect(context.Background())
 			require.NoError(t, err)
 
 			assert.True(t, detected)
@@ -122,7 +246,7 @@ func TestGit_Detect_Worktree(t *testing.T) {
 		Filepath: filepath.Join(fp, "api/src/pkg/file.go"),
 	}
 
-	result, detected, err := g.Detect()
+	result, detected, err := g.Detect(context.Background())
 	require.NoError(t, err)
 
 	assert.True(t, detected)
@@ -134,6 +258,24 @@ func TestGit_Detect_Worktree(t *testing.T) {
 	}, result)
 }
 
+func TestGit_Detect_Worktree_Commit(t *testing.T) {
+	fp := setupTestGitWorktree(t)
+
+	writeLooseRef(t, filepath.Join(fp, "wakatime-cli/.git"), "refs/heads/feature/api",
+		"f4f242d698fa07c298592a66d6546ac9b6b34d1e")
+
+	g := project.Git{
+		Filepath: filepath.Join(fp, "api/src/pkg/file.go"),
+	}
+
+	result, detected, err := g.Detect(context.Background())
+	require.NoError(t, err)
+
+	assert.True(t, detected)
+	assert.Equal(t, "feature/api", result.Branch)
+	assert.Equal(t, "f4f242d698fa07c298592a66d6546ac9b6b34d1e", result.Commit)
+}
+
 func TestGit_Detect_Submodule(t *testing.T) {
 	fp := setupTestGitSubmodule(t)
 
@@ -142,7 +284,7 @@ func TestGit_Detect_Submodule(t *testing.T) {
 		SubmodulePatterns: []regex.Regex{regexp.MustCompile("not_matching")},
 	}
 
-	result, detected, err := g.Detect()
+	result, detected, err := g.Detect(context.Background())
 	require.NoError(t, err)
 
 	assert.True(t, detected)
@@ -162,7 +304,7 @@ func TestGit_Detect_SubmoduleDisabled(t *testing.T) {
 		SubmodulePatterns: []regex.Regex{regexp.MustCompile(".*billing.*")},
 	}
 
-	result, detected, err := g.Detect()
+	result, detected, err := g.Detect(context.Background())
 	require.NoError(t, err)
 
 	assert.True(t, detected)
@@ -174,6 +316,111 @@ func TestGit_Detect_SubmoduleDisabled(t *testing.T) {
 	}, result)
 }
 
+func TestGit_Detect_Remote(t *testing.T) {
+	tests := map[string]struct {
+		Remote   project.GitRemote
+		Expected string
+	}{
+		"origin": {
+			Remote:   project.GitRemote{Name: "origin"},
+			Expected: "wakatime-cli-fork",
+		},
+		"template": {
+			Remote:   project.GitRemote{Name: "origin", Template: "{owner}/{repo}"},
+			Expected: "alanhamlett/wakatime-cli-fork",
+		},
+		"other remote": {
+			Remote:   project.GitRemote{Name: "upstream", Template: "{host}/{owner}/{repo}"},
+			Expected: "github.com/wakatime/wakatime-cli",
+		},
+		"missing remote": {
+			Remote:   project.GitRemote{Name: "missing"},
+			Expected: "wakatime-cli",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			fp := setupTestGitBasic(t)
+
+			copyFile(t, "testdata/git_remote/config", filepath.Join(fp, "wakatime-cli/.git/config"))
+
+			g := project.Git{
+				Filepath: filepath.Join(fp, "wakatime-cli/src/pkg/file.go"),
+				Remote:   test.Remote,
+			}
+
+			result, detected, err := g.Detect(context.Background())
+			require.NoError(t, err)
+
+			assert.True(t, detected)
+			assert.Equal(t, test.Expected, result.Project)
+		})
+	}
+}
+
+func TestGit_Detect_Remote_Worktree(t *testing.T) {
+	fp := setupTestGitWorktree(t)
+
+	copyFile(t, "testdata/git_remote/config", filepath.Join(fp, "wakatime-cli/.git/config"))
+
+	g := project.Git{
+		Filepath: filepath.Join(fp, "api/src/pkg/file.go"),
+		Remote:   project.GitRemote{Name: "origin", Template: "{owner}/{repo}"},
+	}
+
+	result, detected, err := g.Detect(context.Background())
+	require.NoError(t, err)
+
+	assert.True(t, detected)
+	assert.Equal(t, "alanhamlett/wakatime-cli-fork", result.Project)
+	assert.Equal(t, "feature/api", result.Branch)
+}
+
+func TestGit_Detect_Remote_Submodule(t *testing.T) {
+	fp := setupTestGitSubmodule(t)
+
+	copyFile(t, "testdata/git_remote/config", filepath.Join(fp, "wakatime-cli/.git/config"))
+	copyFile(
+		t,
+		"testdata/git_remote/config_submodule",
+		filepath.Join(fp, "wakatime-cli/.git/modules/lib/billing/config"),
+	)
+
+	g := project.Git{
+		Filepath: filepath.Join(fp, "wakatime-cli/lib/billing/src/lib/lib.cpp"),
+		Remote:   project.GitRemote{Name: "origin", Template: "{host}:{owner}/{repo}"},
+	}
+
+	result, detected, err := g.Detect(context.Background())
+	require.NoError(t, err)
+
+	assert.True(t, detected)
+	assert.Equal(t, "gitlab.com:wakatime/payments/billing", result.Project)
+}
+
+func TestGit_Detect_Remote_LocalPath(t *testing.T) {
+	fp := setupTestGitBasic(t)
+
+	err := os.WriteFile(
+		filepath.Join(fp, "wakatime-cli/.git/config"),
+		[]byte("[remote \"origin\"]\n\turl = /srv/git/wakatime-cli.git\n"),
+		0600,
+	)
+	require.NoError(t, err)
+
+	g := project.Git{
+		Filepath: filepath.Join(fp, "wakatime-cli/src/pkg/file.go"),
+		Remote:   project.GitRemote{Name: "origin", Template: "{host}/{owner}/{repo}"},
+	}
+
+	result, detected, err := g.Detect(context.Background())
+	require.NoError(t, err)
+
+	assert.True(t, detected)
+	assert.Equal(t, "srv/git/wakatime-cli", result.Project)
+}
+
 func setupTestGitBasic(t *testing.T) (fp string) {
 	tmpDir := t.TempDir()
 
@@ -426,3 +673,13 @@ func setupTestGitSubmodule(t *testing.T) (fp string) {
 
 	return tmpDir
 }
+
+func writeLooseRef(t *testing.T, gitdir, ref, commit string) {
+	fp := filepath.Join(gitdir, filepath.FromSlash(ref))
+
+	err := os.MkdirAll(filepath.Dir(fp), os.FileMode(int(0700)))
+	require.NoError(t, err)
+
+	err = os.WriteFile(fp, []byte(commit+"\n"), 0600)
+	require.NoError(t, err)
+}
@@ -0,0 +1,513 @@
+package project
+
+import (
+	"bufio"
+	"compress/zlib"
+	"context"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/wakatime/wakatime-cli/pkg/log"
+)
+
+// Field numbers of the jujutsu op store and working copy protobuf messages.
+const (
+	jjCheckoutWorkspaceID     = 3
+	jjOperationViewID         = 1
+	jjViewWcCommitIDs         = 8
+	jjViewBookmarks           = 5
+	jjBookmarkName            = 1
+	jjBookmarkLocalTarget     = 2
+	jjRefTargetCommitID       = 1
+	jjRefTargetConflictLegacy = 2
+	jjRefTargetConflict       = 3
+	jjRefConflictRemoves      = 1
+	jjRefConflictAdds         = 2
+	jjRefConflictTermValue    = 1
+	jjMapEntryKey             = 1
+	jjMapEntryValue           = 2
+	jjDefaultWorkspaceName    = "default"
+)
+
+// Jujutsu contains jujutsu data.
+type Jujutsu struct {
+	// Filepath contains the entity path.
+	Filepath string
+}
+
+// Detect gets information about the jujutsu project for a given file.
+// The bookmark is read from the op store, pointing at the working copy commit
+// or its parents. This includes workspaces colocated with git, where git sees
+// a detached HEAD at the parent of the working copy commit. Colocated
+// workspaces without such a bookmark are left to git detection.
+func (j Jujutsu) Detect(_ context.Context) (Result, bool, error) {
+	log.Debugln("execute jujutsu project detection")
+
+	fp := j.Filepath
+
+	// Take only the directory
+	if fileExists(fp) {
+		fp = filepath.Dir(fp)
+	}
+
+	jjDirectory, ok := FindFileOrDirectory(fp, ".jj")
+	if !ok {
+		return Result{}, false, nil
+	}
+
+	root := filepath.Dir(jjDirectory)
+
+	branch, err := findJujutsuBookmark(jjDirectory)
+	if err != nil {
+		log.Errorf(
+			"error finding for bookmark name from %q: %s",
+			jjDirectory,
+			err,
+		)
+	}
+
+	if branch == "" && fileExists(filepath.Join(root, ".git")) {
+		log.Debugf("jujutsu workspace %q is colocated with git and has no bookmark", root)
+		return Result{}, false, nil
+	}
+
+	return Result{
+		Project: filepath.Base(root),
+		Branch:  branch,
+		Folder:  root,
+	}, true, nil
+}
+
+// findJujutsuBookmark returns the bookmark pointing at the working copy commit
+// of the workspace, or else at one of its parents.
+func findJujutsuBookmark(jjDirectory string) (string, error) {
+	repoDir, err := findJujutsuRepo(jjDirectory)
+	if err != nil {
+		return "", err
+	}
+
+	workspace, err := readJujutsuWorkspace(filepath.Join(jjDirectory, "working_copy", "checkout"))
+	if err != nil {
+		return "", err
+	}
+
+	wcCommits, bookmarks, err := readJujutsuView(repoDir)
+	if err != nil {
+		return "", err
+	}
+
+	wcCommit, ok := wcCommits[workspace]
+	if !ok {
+		return "", nil
+	}
+
+	if name := bookmarkAt(bookmarks, wcCommit); name != "" {
+		return name, nil
+	}
+
+	storeDir := filepath.Join(repoDir, "store")
+
+	gitdir, ok, err := readPathFile(filepath.Join(storeDir, "git_target"), storeDir)
+	if err != nil || !ok {
+		return "", err
+	}
+
+	parents, err := readGitCommitParents(gitdir, wcCommit)
+	if err != nil {
+		return "", err
+	}
+
+	for _, parent := range parents {
+		if name := bookmarkAt(bookmarks, parent); name != "" {
+			return name, nil
+		}
+	}
+
+	return "", nil
+}
+
+// bookmarkAt returns the first bookmark by name pointing at commit.
+func bookmarkAt(bookmarks map[string]string, commit string) string {
+	var names []string
+
+	for name, target := range bookmarks {
+		if target == commit {
+			names = append(names, name)
+		}
+	}
+
+	if len(names) == 0 {
+		return ""
+	}
+
+	sort.Strings(names)
+
+	return names[0]
+}
+
+// findJujutsuRepo returns the repo directory of a workspace. Secondary
+// workspaces have a file pointing at the repo directory of the main workspace.
+func findJujutsuRepo(jjDirectory string) (string, error) {
+	fp := filepath.Join(jjDirectory, "repo")
+
+	if isDir(fp) {
+		return fp, nil
+	}
+
+	repoDir, ok, err := readPathFile(fp, jjDirectory)
+	if err != nil {
+		return "", err
+	}
+
+	if !ok {
+		return "", Err(fmt.Sprintf("repo not found in %q", jjDirectory))
+	}
+
+	return repoDir, nil
+}
+
+// readJujutsuWorkspace returns the workspace name of the working copy.
+func readJujutsuWorkspace(fp string) (string, error) {
+	if !fileExists(fp) {
+		return jjDefaultWorkspaceName, nil
+	}
+
+	data, err := os.ReadFile(fp) // nolint:gosec
+	if err != nil {
+		return "", Err(fmt.Sprintf("failed to read file %q: %s", fp, err))
+	}
+
+	fields, err := parseProtobuf(data)
+	if err != nil {
+		return "", Err(fmt.Sprintf("failed to parse %q: %s", fp, err))
+	}
+
+	for _, f := range fields {
+		if f.Num == jjCheckoutWorkspaceID && len(f.Bytes) > 0 {
+			return string(f.Bytes), nil
+		}
+	}
+
+	return jjDefaultWorkspaceName, nil
+}
+
+// readJujutsuView returns the working copy commits by workspace and the
+// commits of local bookmarks by name, of the view of the current operation.
+// Commits are hex encoded.
+func readJujutsuView(repoDir string) (map[string]string, map[string]string, error) {
+	heads, err := os.ReadDir(filepath.Join(repoDir, "op_heads", "heads"))
+	if err != nil {
+		return nil, nil, Err(fmt.Sprintf("failed to read operation heads: %s", err))
+	}
+
+	// concurrent operations are merged by the next jj command
+	if len(heads) != 1 {
+		return nil, nil, Err(fmt.Sprintf("expected 1 operation head, found %d", len(heads)))
+	}
+
+	opStore := filepath.Join(repoDir, "op_store")
+
+	operation, err := readProtobufFile(filepath.Join(opStore, "operations", heads[0].Name()))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var viewID []byte
+
+	for _, f := range operation {
+		if f.Num == jjOperationViewID {
+			viewID = f.Bytes
+		}
+	}
+
+	if len(viewID) == 0 {
+		return nil, nil, Err("view of operation not found")
+	}
+
+	view, err := readProtobufFile(filepath.Join(opStore, "views", hex.EncodeToString(viewID)))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		wcCommits = make(map[string]string)
+		bookmarks = make(map[string]string)
+	)
+
+	for _, f := range view {
+		switch f.Num {
+		case jjViewWcCommitIDs:
+			entry, err := parseProtobuf(f.Bytes)
+			if err != nil {
+				return nil, nil, Err(fmt.Sprintf("failed to parse working copy commit: %s", err))
+			}
+
+			workspace := string(protobufBytes(entry, jjMapEntryKey))
+			wcCommits[workspace] = hex.EncodeToString(protobufBytes(entry, jjMapEntryValue))
+		case jjViewBookmarks:
+			bookmark, err := parseProtobuf(f.Bytes)
+			if err != nil {
+				return nil, nil, Err(fmt.Sprintf("failed to parse bookmark: %s", err))
+			}
+
+			name := string(protobufBytes(bookmark, jjBookmarkName))
+
+			commit, ok, err := parseJujutsuRefTarget(protobufBytes(bookmark, jjBookmarkLocalTarget))
+			if err != nil {
+				return nil, nil, Err(fmt.Sprintf("failed to parse target of bookmark %q: %s", name, err))
+			}
+
+			if !ok {
+				log.Warnf("unsupported target of jujutsu bookmark %q, which may be of a newer jj version", name)
+				continue
+			}
+
+			// conflicted and deleted bookmarks have no single commit
+			if len(commit) > 0 {
+				bookmarks[name] = hex.EncodeToString(commit)
+			}
+		}
+	}
+
+	// every repo has a working copy commit, so the view format is unknown
+	// without one
+	if len(wcCommits) == 0 {
+		return nil, nil, Err("working copy commits of view not found, which may be of a newer jj version")
+	}
+
+	return wcCommits, bookmarks, nil
+}
+
+// parseJujutsuRefTarget returns the commit of a ref target, or nil for
+// conflicted and deleted targets. Older jj versions write the commit id, newer
+// ones a conflict with a single added commit. Returns false for unknown
+// encodings.
+func parseJujutsuRefTarget(data []byte) ([]byte, bool, error) {
+	target, err := parseProtobuf(data)
+	if err != nil {
+		return nil, false, err
+	}
+
+	if len(target) == 0 {
+		return nil, true, nil
+	}
+
+	if commit := protobufBytes(target, jjRefTargetCommitID); commit != nil {
+		return commit, true, nil
+	}
+
+	if protobufBytes(target, jjRefTargetConflictLegacy) != nil {
+		return nil, true, nil
+	}
+
+	conflict := protobufBytes(target, jjRefTargetConflict)
+	if conflict == nil {
+		return nil, false, nil
+	}
+
+	terms, err := parseProtobuf(conflict)
+	if err != nil {
+		return nil, false, err
+	}
+
+	var adds [][]byte
+
+	for _, f := range terms {
+		switch f.Num {
+		case jjRefConflictRemoves:
+			return nil, true, nil
+		case jjRefConflictAdds:
+			adds = append(adds, f.Bytes)
+		}
+	}
+
+	if len(adds) != 1 {
+		return nil, true, nil
+	}
+
+	term, err := parseProtobuf(adds[0])
+	if err != nil {
+		return nil, false, err
+	}
+
+	return protobufBytes(term, jjRefConflictTermValue), true, nil
+}
+
+// readGitCommitParents returns the parents of a loose git commit object.
+// Returns nil for packed objects.
+func readGitCommitParents(gitdir, commit string) ([]string, error) {
+	if len(commit) < 3 {
+		return nil, nil
+	}
+
+	fp := filepath.Join(gitdir, "objects", commit[:2], commit[2:])
+	if !fileExists(fp) {
+		log.Debugf("git object %q is not a loose object", commit)
+		return nil, nil
+	}
+
+	f, err := os.Open(fp) // nolint:gosec
+	if err != nil {
+		return nil, Err(fmt.Sprintf("failed to open git object %q: %s", fp, err))
+	}
+
+	defer f.Close()
+
+	r, err := zlib.NewReader(f)
+	if err != nil {
+		return nil, Err(fmt.Sprintf("failed to decompress git object %q: %s", fp, err))
+	}
+
+	defer r.Close()
+
+	reader := bufio.NewReader(r)
+
+	header, err := reader.ReadString(0)
+	if err != nil || !strings.HasPrefix(header, "commit ") {
+		return nil, Err(fmt.Sprintf("git object %q is not a commit", fp))
+	}
+
+	var parents []string
+
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, Err(fmt.Sprintf("failed to read git object %q: %s", fp, err))
+		}
+
+		line = strings.TrimSuffix(line, "\n")
+
+		// headers end at the first empty line
+		if line == "" {
+			return parents, nil
+		}
+
+		if strings.HasPrefix(line, "parent ") {
+			parents = append(parents, strings.TrimPrefix(line, "parent "))
+		}
+
+		if err != nil {
+			return parents, nil
+		}
+	}
+}
+
+// readPathFile returns the path from the first line of a file. Relative paths
+// are resolved against dir.
+func readPathFile(fp, dir string) (string, bool, error) {
+	if !fileExists(fp) {
+		return "", false, nil
+	}
+
+	lines, err := readFile(fp, 1)
+	if err != nil {
+		return "", false, Err(fmt.Sprintf("failed while opening file %q: %s", fp, err))
+	}
+
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) == "" {
+		return "", false, nil
+	}
+
+	p := filepath.FromSlash(strings.TrimSpace(lines[0]))
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(dir, p)
+	}
+
+	return filepath.Clean(p), true, nil
+}
+
+// protobufField is a field of a protobuf message. Bytes is set for length
+// delimited fields only.
+type protobufField struct {
+	Num   uint64
+	Bytes []byte
+}
+
+func readProtobufFile(fp string) ([]protobufField, error) {
+	data, err := os.ReadFile(fp) // nolint:gosec
+	if err != nil {
+		return nil, Err(fmt.Sprintf("failed to read file %q: %s", fp, err))
+	}
+
+	fields, err := parseProtobuf(data)
+	if err != nil {
+		return nil, Err(fmt.Sprintf("failed to parse %q: %s", fp, err))
+	}
+
+	return fields, nil
+}
+
+// parseProtobuf decodes the fields of a protobuf message in wire format.
+func parseProtobuf(data []byte) ([]protobufField, error) {
+	var fields []protobufField
+
+	for len(data) > 0 {
+		key, n := binary.Uvarint(data)
+		if n <= 0 {
+			return nil, errors.New("invalid field key")
+		}
+
+		data = data[n:]
+		field := protobufField{Num: key >> 3}
+
+		switch key & 0x7 {
+		case 0:
+			if _, n = binary.Uvarint(data); n <= 0 {
+				return nil, errors.New("invalid varint")
+			}
+
+			data = data[n:]
+		case 1:
+			if len(data) < 8 {
+				return nil, errors.New("invalid fixed64")
+			}
+
+			data = data[8:]
+		case 2:
+			size, n := binary.Uvarint(data)
+			if n <= 0 || size > uint64(len(data)-n) {
+				return nil, errors.New("invalid length")
+			}
+
+			field.Bytes = data[n : n+int(size)]
+			data = data[n+int(size):]
+		case 5:
+			if len(data) < 4 {
+				return nil, errors.New("invalid fixed32")
+			}
+
+			data = data[4:]
+		default:
+			return nil, fmt.Errorf("unsupported wire type %d", key&0x7)
+		}
+
+		fields = append(fields, field)
+	}
+
+	return fields, nil
+}
+
+// protobufBytes returns the last length delimited field of num.
+func protobufBytes(fields []protobufField, num uint64) []byte {
+	var value []byte
+
+	for _, f := range fields {
+		if f.Num == num && f.Bytes != nil {
+			value = f.Bytes
+		}
+	}
+
+	return value
+}
+
+// String returns its name.
+func (Jujutsu) String() string {
+	return "jj-detector"
+}
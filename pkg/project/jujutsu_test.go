@@ -0,0 +1,219 @@
+package project_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/project"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJujutsu_Detect(t *testing.T) {
+	fp := setupTestJujutsu(t)
+
+	j := project.Jujutsu{
+		Filepath: filepath.Join(fp, "wakatime-cli/src/pkg/file.go"),
+	}
+
+	result, detected, err := j.Detect(context.Background())
+	require.NoError(t, err)
+
+	assert.True(t, detected)
+	assert.Equal(t, project.Result{
+		Project: "wakatime-cli",
+		Branch:  "main",
+		Folder:  filepath.Join(fp, "wakatime-cli"),
+	}, result)
+}
+
+func TestJujutsu_Detect_PackedParent(t *testing.T) {
+	fp := setupTestJujutsu(t)
+
+	err := os.RemoveAll(filepath.Join(fp, "wakatime-cli/.jj/repo/store/git/objects"))
+	require.NoError(t, err)
+
+	j := project.Jujutsu{
+		Filepath: filepath.Join(fp, "wakatime-cli/src/pkg/file.go"),
+	}
+
+	result, detected, err := j.Detect(context.Background())
+	require.NoError(t, err)
+
+	assert.True(t, detected)
+	assert.Equal(t, "wakatime-cli", result.Project)
+	assert.Empty(t, result.Branch)
+}
+
+func TestJujutsu_Detect_RefConflictTargets(t *testing.T) {
+	fp := setupTestJujutsu(t)
+
+	// newer jj versions write bookmark targets as conflicts of a single commit
+	copyFile(
+		t,
+		"testdata/jj/view_ref_conflict",
+		filepath.Join(fp, "wakatime-cli/.jj/repo/op_store/views", strings.Repeat("9e7a", 32)),
+	)
+
+	j := project.Jujutsu{
+		Filepath: filepath.Join(fp, "wakatime-cli/src/pkg/file.go"),
+	}
+
+	result, detected, err := j.Detect(context.Background())
+	require.NoError(t, err)
+
+	assert.True(t, detected)
+	assert.Equal(t, project.Result{
+		Project: "wakatime-cli",
+		Branch:  "main",
+		Folder:  filepath.Join(fp, "wakatime-cli"),
+	}, result)
+}
+
+func TestJujutsu_Detect_UnknownViewFormat(t *testing.T) {
+	fp := setupTestJujutsu(t)
+
+	// head ids only, without working copy commits
+	err := os.WriteFile(
+		filepath.Join(fp, "wakatime-cli/.jj/repo/op_store/views", strings.Repeat("9e7a", 32)),
+		[]byte("\x0a\x02\x7d\x3f"),
+		0600,
+	)
+	require.NoError(t, err)
+
+	j := project.Jujutsu{
+		Filepath: filepath.Join(fp, "wakatime-cli/src/pkg/file.go"),
+	}
+
+	result, detected, err := j.Detect(context.Background())
+	require.NoError(t, err)
+
+	assert.True(t, detected)
+	assert.Equal(t, "wakatime-cli", result.Project)
+	assert.Empty(t, result.Branch)
+}
+
+func TestJujutsu_Detect_SecondaryWorkspace(t *testing.T) {
+	fp := setupTestJujutsu(t)
+
+	err := os.MkdirAll(filepath.Join(fp, "wakatime-cli-feature/.jj/working_copy"), os.FileMode(int(0700)))
+	require.NoError(t, err)
+
+	err = os.WriteFile(
+		filepath.Join(fp, "wakatime-cli-feature/.jj/repo"),
+		[]byte("../../wakatime-cli/.jj/repo"),
+		0600,
+	)
+	require.NoError(t, err)
+
+	copyFile(t, "testdata/jj/checkout_secondary", filepath.Join(fp, "wakatime-cli-feature/.jj/working_copy/checkout"))
+
+	j := project.Jujutsu{
+		Filepath: filepath.Join(fp, "wakatime-cli-feature/main.go"),
+	}
+
+	result, detected, err := j.Detect(context.Background())
+	require.NoError(t, err)
+
+	assert.True(t, detected)
+	assert.Equal(t, project.Result{
+		Project: "wakatime-cli-feature",
+		Branch:  "feature/jj",
+		Folder:  filepath.Join(fp, "wakatime-cli-feature"),
+	}, result)
+}
+
+func TestJujutsu_Detect_Colocated(t *testing.T) {
+	fp := setupTestColocatedJujutsu(t)
+
+	j := project.Jujutsu{
+		Filepath: filepath.Join(fp, "wakatime-cli/src/pkg/file.go"),
+	}
+
+	result, detected, err := j.Detect(context.Background())
+	require.NoError(t, err)
+
+	assert.True(t, detected)
+	assert.Equal(t, project.Result{
+		Project: "wakatime-cli",
+		Branch:  "main",
+		Folder:  filepath.Join(fp, "wakatime-cli"),
+	}, result)
+}
+
+func TestJujutsu_Detect_ColocatedWithoutBookmark(t *testing.T) {
+	fp := setupTestColocatedJujutsu(t)
+
+	err := os.RemoveAll(filepath.Join(fp, "wakatime-cli/.git/objects"))
+	require.NoError(t, err)
+
+	j := project.Jujutsu{
+		Filepath: filepath.Join(fp, "wakatime-cli/src/pkg/file.go"),
+	}
+
+	_, detected, err := j.Detect(context.Background())
+	require.NoError(t, err)
+
+	assert.False(t, detected)
+}
+
+// setupTestColocatedJujutsu moves the git store of the jujutsu repo to a .git
+// folder in the workspace, like `jj git init --colocate`.
+func setupTestColocatedJujutsu(t *testing.T) (fp string) {
+	fp = setupTestJujutsu(t)
+
+	err := os.Rename(filepath.Join(fp, "wakatime-cli/.jj/repo/store/git"), filepath.Join(fp, "wakatime-cli/.git"))
+	require.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(fp, "wakatime-cli/.jj/repo/store/git_target"), []byte("../../../.git"), 0600)
+	require.NoError(t, err)
+
+	return fp
+}
+
+func setupTestJujutsu(t *testing.T) (fp string) {
+	tmpDir := t.TempDir()
+
+	const commit = "7d3f9e1c2b4a5968778695a4b3c2d1e0f9a8b7c6"
+
+	var (
+		opID   = strings.Repeat("5b1c", 32)
+		viewID = strings.Repeat("9e7a", 32)
+	)
+
+	repoDir := filepath.Join(tmpDir, "wakatime-cli/.jj/repo")
+
+	for _, dir := range []string{
+		filepath.Join(tmpDir, "wakatime-cli/src/pkg"),
+		filepath.Join(tmpDir, "wakatime-cli/.jj/working_copy"),
+		filepath.Join(repoDir, "op_heads/heads"),
+		filepath.Join(repoDir, "op_store/operations"),
+		filepath.Join(repoDir, "op_store/views"),
+		filepath.Join(repoDir, "store/git/objects", commit[:2]),
+	} {
+		err := os.MkdirAll(dir, os.FileMode(int(0700)))
+		require.NoError(t, err)
+	}
+
+	tmpFile, err := os.Create(filepath.Join(tmpDir, "wakatime-cli/src/pkg/file.go"))
+	require.NoError(t, err)
+
+	defer tmpFile.Close()
+
+	tmpFile, err = os.Create(filepath.Join(repoDir, "op_heads/heads", opID))
+	require.NoError(t, err)
+
+	defer tmpFile.Close()
+
+	copyFile(t, "testdata/jj/checkout", filepath.Join(tmpDir, "wakatime-cli/.jj/working_copy/checkout"))
+	copyFile(t, "testdata/jj/operation", filepath.Join(repoDir, "op_store/operations", opID))
+	copyFile(t, "testdata/jj/view", filepath.Join(repoDir, "op_store/views", viewID))
+	copyFile(t, "testdata/jj/git_target", filepath.Join(repoDir, "store/git_target"))
+	copyFile(t, "testdata/jj/commit", filepath.Join(repoDir, "store/git/objects", commit[:2], commit[2:]))
+
+	return tmpDir
+}
@@ -0,0 +1,232 @@
+package project
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/wakatime/wakatime-cli/pkg/log"
+)
+
+// nolint:gochecknoglobals
+var (
+	goModuleRegex    = regexp.MustCompile(`^module\s+"?([^"\s]+)"?`)
+	goMajorRegex     = regexp.MustCompile(`^v\d+$`)
+	tomlSectionRegex = regexp.MustCompile(`^\[\s*([^\]]+?)\s*\]`)
+	tomlNameRegex    = regexp.MustCompile(`^name\s*=\s*["']([^"']+)["']`)
+)
+
+// DefaultManifests returns the file names of manifests, searched for the
+// sub-projects of a monorepo by default.
+func DefaultManifests() []string {
+	return []string{"go.mod", "package.json", "Cargo.toml", "pyproject.toml", "pom.xml", "build.gradle"}
+}
+
+// Manifest contains manifest data.
+type Manifest struct {
+	// Filepath contains the entity path.
+	Filepath string
+	// Root is the repository root folder. Manifests are searched below it.
+	Root string
+	// Manifests are the file names of manifests. Defaults to DefaultManifests().
+	Manifests []string
+}
+
+// Detect finds the nearest manifest file between the entity and the
+// repository root, excluding the root itself. The project name is taken
+// from the module or package name of the manifest, or else from its folder.
+func (m Manifest) Detect(_ context.Context) (Result, bool, error) {
+	log.Debugln("execute manifest project detection")
+
+	manifests := m.Manifests
+	if len(manifests) == 0 {
+		manifests = DefaultManifests()
+	}
+
+	root := filepath.Clean(m.Root)
+	dir := filepath.Dir(filepath.Clean(m.Filepath))
+
+	for i := 0; i < maxRecursiveIteration; i++ {
+		// stop at repository boundaries, also of worktrees and submodules
+		if dir == root || isRootPath(dir) || fileExists(filepath.Join(dir, ".git")) {
+			return Result{}, false, nil
+		}
+
+		for _, manifest := range manifests {
+			fp := filepath.Join(dir, manifest)
+			if !fileExists(fp) {
+				continue
+			}
+
+			log.Debugf("manifest file found at: %s", fp)
+
+			project, err := manifestProjectName(fp)
+			if err != nil {
+				log.Warnf("failed to read project name from manifest %q: %s", fp, err)
+			}
+
+			return Result{
+				Project: firstNonEmptyString(project, filepath.Base(dir)),
+				Folder:  dir,
+			}, true, nil
+		}
+
+		dir = filepath.Dir(dir)
+	}
+
+	log.Warnf("max %d iterations reached without finding manifest", maxRecursiveIteration)
+
+	return Result{}, false, nil
+}
+
+// String returns its name.
+func (Manifest) String() string {
+	return "manifest-detector"
+}
+
+// manifestProjectName returns the module or package name of a manifest file.
+// Returns an empty string for unknown manifests and manifests without name.
+func manifestProjectName(fp string) (string, error) {
+	switch filepath.Base(fp) {
+	case "go.mod":
+		return goModuleName(fp)
+	case "package.json":
+		return packageJSONName(fp)
+	case "Cargo.toml":
+		return tomlName(fp, "package")
+	case "pyproject.toml":
+		return tomlName(fp, "project", "tool.poetry")
+	case "pom.xml":
+		return pomArtifactID(fp)
+	default:
+		return "", nil
+	}
+}
+
+// goModuleName returns the last element of the module path, skipping a major
+// version suffix.
+func goModuleName(fp string) (string, error) {
+	var module string
+
+	err := scanLines(fp, func(line string) bool {
+		if matches := goModuleRegex.FindStringSubmatch(line); matches != nil {
+			module = matches[1]
+			return false
+		}
+
+		return true
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if module == "" {
+		return "", nil
+	}
+
+	name := path.Base(module)
+	if goMajorRegex.MatchString(name) && path.Dir(module) != "." {
+		name = path.Base(path.Dir(module))
+	}
+
+	return name, nil
+}
+
+func packageJSONName(fp string) (string, error) {
+	data, err := os.ReadFile(fp) // nolint:gosec
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %s", err)
+	}
+
+	var pkg struct {
+		Name string `json:"name"`
+	}
+
+	if err := json.Unmarshal(data, &pkg); err != nil {
+		return "", fmt.Errorf("failed to parse json: %s", err)
+	}
+
+	return strings.TrimSpace(pkg.Name), nil
+}
+
+// tomlName returns the name key of the first of the given toml tables.
+func tomlName(fp string, tables ...string) (string, error) {
+	var (
+		names   = make(map[string]string)
+		current string
+	)
+
+	err := scanLines(fp, func(line string) bool {
+		if matches := tomlSectionRegex.FindStringSubmatch(line); matches != nil {
+			current = matches[1]
+			return true
+		}
+
+		if matches := tomlNameRegex.FindStringSubmatch(line); matches != nil {
+			if _, ok := names[current]; !ok {
+				names[current] = matches[1]
+			}
+		}
+
+		return true
+	})
+	if err != nil {
+		return "", err
+	}
+
+	for _, table := range tables {
+		if name, ok := names[table]; ok {
+			return name, nil
+		}
+	}
+
+	return "", nil
+}
+
+func pomArtifactID(fp string) (string, error) {
+	data, err := os.ReadFile(fp) // nolint:gosec
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %s", err)
+	}
+
+	var pom struct {
+		ArtifactID string `xml:"artifactId"`
+	}
+
+	if err := xml.Unmarshal(data, &pom); err != nil {
+		return "", fmt.Errorf("failed to parse xml: %s", err)
+	}
+
+	return strings.TrimSpace(pom.ArtifactID), nil
+}
+
+// scanLines calls fn with each trimmed line of a file, until fn returns false.
+func scanLines(fp string, fn func(line string) bool) error {
+	f, err := os.Open(fp) // nolint:gosec
+	if err != nil {
+		return fmt.Errorf("failed to open file: %s", err)
+	}
+
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+
+	for scanner.Scan() {
+		if !fn(strings.TrimSpace(scanner.Text())) {
+			return nil
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read file: %s", err)
+	}
+
+	return nil
+}
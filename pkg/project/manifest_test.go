@@ -0,0 +1,244 @@
+package project_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/project"
+	"github.com/wakatime/wakatime-cli/pkg/regex"
+	"github.com/wakatime/wakatime-cli/pkg/windows"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManifest_Detect(t *testing.T) {
+	tests := map[string]struct {
+		Source   string
+		Manifest string
+		Expected string
+	}{
+		"go.mod": {
+			Source:   "go.mod",
+			Manifest: "go.mod",
+			Expected: "api",
+		},
+		"package.json": {
+			Source:   "package.json",
+			Manifest: "package.json",
+			Expected: "@wakatime/web",
+		},
+		"Cargo.toml": {
+			Source:   "Cargo.toml",
+			Manifest: "Cargo.toml",
+			Expected: "wakatime-rs",
+		},
+		"pyproject.toml": {
+			Source:   "pyproject.toml",
+			Manifest: "pyproject.toml",
+			Expected: "wakatime-py",
+		},
+		"pyproject.toml poetry": {
+			Source:   "pyproject-poetry.toml",
+			Manifest: "pyproject.toml",
+			Expected: "wakatime-poetry",
+		},
+		"pom.xml": {
+			Source:   "pom.xml",
+			Manifest: "pom.xml",
+			Expected: "wakatime-java",
+		},
+		"build.gradle": {
+			Source:   "build.gradle",
+			Manifest: "build.gradle",
+			Expected: "sub",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			root := setupTestMonorepo(t)
+
+			copyFile(t, filepath.Join("testdata/manifest", test.Source), filepath.Join(root, "sub", test.Manifest))
+
+			m := project.Manifest{
+				Filepath: filepath.Join(root, "sub", "src", "main"),
+				Root:     root,
+			}
+
+			result, detected, err := m.Detect(context.Background())
+			require.NoError(t, err)
+
+			assert.True(t, detected)
+			assert.Equal(t, project.Result{
+				Project: test.Expected,
+				Folder:  filepath.Join(root, "sub"),
+			}, result)
+		})
+	}
+}
+
+func TestManifest_Detect_Configured(t *testing.T) {
+	root := setupTestMonorepo(t)
+
+	copyFile(t, "testdata/manifest/package.json", filepath.Join(root, "sub", "package.json"))
+	copyFile(t, "testdata/manifest/build.gradle", filepath.Join(root, "sub", "src", "BUILD"))
+
+	m := project.Manifest{
+		Filepath:  filepath.Join(root, "sub", "src", "main"),
+		Root:      root,
+		Manifests: []string{"BUILD"},
+	}
+
+	result, detected, err := m.Detect(context.Background())
+	require.NoError(t, err)
+
+	assert.True(t, detected)
+	assert.Equal(t, project.Result{
+		Project: "src",
+		Folder:  filepath.Join(root, "sub", "src"),
+	}, result)
+}
+
+func TestManifest_Detect_NotDetected(t *testing.T) {
+	tests := map[string]string{
+		"root manifest": "src/main",
+		"no manifest":   "sub/src/main",
+		"nested repo":   "nested/src/main",
+	}
+
+	for name, entity := range tests {
+		t.Run(name, func(t *testing.T) {
+			root := setupTestMonorepo(t)
+
+			err := os.MkdirAll(filepath.Join(root, "nested", ".git"), 0700)
+			require.NoError(t, err)
+
+			copyFile(t, "testdata/manifest/package.json", filepath.Join(root, "package.json"))
+			copyFile(t, "testdata/manifest/package.json", filepath.Join(root, "nested", "package.json"))
+
+			m := project.Manifest{
+				Filepath: filepath.Join(root, entity),
+				Root:     root,
+			}
+
+			_, detected, err := m.Detect(context.Background())
+			require.NoError(t, err)
+
+			assert.False(t, detected)
+		})
+	}
+}
+
+func TestManifest_String(t *testing.T) {
+	m := project.Manifest{}
+
+	assert.Equal(t, "manifest-detector", m.String())
+}
+
+func TestWithDetection_Monorepo(t *testing.T) {
+	fp := setupTestGitBasic(t)
+
+	err := os.MkdirAll(filepath.Join(fp, "wakatime-cli", "src", "pkg"), 0700)
+	require.NoError(t, err)
+
+	copyFile(t, "testdata/manifest/go.mod", filepath.Join(fp, "wakatime-cli", "src", "go.mod"))
+
+	entity := filepath.Join(fp, "wakatime-cli/src/pkg/file.go")
+
+	tests := map[string]struct {
+		Patterns        []project.MonorepoPattern
+		ExpectedProject string
+		ExpectedPath    string
+	}{
+		"disabled": {
+			ExpectedProject: "wakatime-cli",
+			ExpectedPath:    filepath.Join(fp, "wakatime-cli"),
+		},
+		"other repository": {
+			Patterns: []project.MonorepoPattern{
+				{Regex: regexp.MustCompile("other-repo$")},
+			},
+			ExpectedProject: "wakatime-cli",
+			ExpectedPath:    filepath.Join(fp, "wakatime-cli"),
+		},
+		"enabled": {
+			Patterns: []project.MonorepoPattern{
+				{Regex: regexp.MustCompile("wakatime-cli$")},
+			},
+			ExpectedProject: "api",
+			ExpectedPath:    filepath.Join(fp, "wakatime-cli", "src"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			if runtime.GOOS == "windows" {
+				test.ExpectedPath, err = windows.FormatFilePath(test.ExpectedPath)
+				require.NoError(t, err)
+			}
+
+			opt := project.WithDetection(project.Config{
+				MonorepoPatterns: test.Patterns,
+			})
+
+			handle := opt(func(_ context.Context, hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+				assert.Equal(t, []heartbeat.Heartbeat{
+					{
+						Branch:      heartbeat.String("master"),
+						Entity:      entity,
+						EntityType:  heartbeat.FileType,
+						Project:     heartbeat.String(test.ExpectedProject),
+						ProjectPath: test.ExpectedPath,
+					},
+				}, hh)
+
+				return nil, nil
+			})
+
+			_, err := handle(context.Background(), []heartbeat.Heartbeat{
+				{
+					Entity:     entity,
+					EntityType: heartbeat.FileType,
+				},
+			})
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestDetectMonorepo_FirstPatternWins(t *testing.T) {
+	root := setupTestMonorepo(t)
+
+	copyFile(t, "testdata/manifest/package.json", filepath.Join(root, "sub", "package.json"))
+
+	result, ok := project.DetectMonorepo(
+		context.Background(),
+		filepath.Join(root, "sub", "src", "main"),
+		root,
+		[]project.MonorepoPattern{
+			{Regex: regex.MustCompile(".*"), Manifests: []string{"go.mod"}},
+			{Regex: regex.MustCompile(".*")},
+		},
+	)
+
+	assert.False(t, ok)
+	assert.Empty(t, result)
+}
+
+func setupTestMonorepo(t *testing.T) string {
+	root := t.TempDir()
+
+	err := os.MkdirAll(filepath.Join(root, ".git"), 0700)
+	require.NoError(t, err)
+
+	err = os.MkdirAll(filepath.Join(root, "sub", "src"), 0700)
+	require.NoError(t, err)
+
+	return root
+}
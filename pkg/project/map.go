@@ -1,6 +1,7 @@
 package project
 
 import (
+	"context"
 	"path/filepath"
 
 	"github.com/wakatime/wakatime-cli/pkg/log"
@@ -18,54 +19,94 @@ type Map struct {
 // with regex patterns. Project maps go under the [projectmap] config section.
 //
 // For example:
-// 	[projectmap]
+//
+//	[projectmap]
 //	/home/user/projects/foo = new project name
 //	/home/user/projects/bar(\d+)/ = project{0}
 //
 // Will result in file '/home/user/projects/foo/src/main.c' to have
 // project name 'new project name' and file '/home/user/projects/bar42/main.c'
-// to have project name 'project42'.
-func (m Map) Detect() (Result, bool, error) {
+// to have project name 'project42'. If multiple patterns match, the one
+// with the highest priority wins, then the one with the longest match and
+// then the first one in the config file.
+func (m Map) Detect(_ context.Context) (Result, bool, error) {
 	log.Debugln("execute map project detection")
 
 	if len(m.Patterns) == 0 {
 		return Result{}, false, nil
 	}
 
-	result, ok := matchPattern(m.Filepath, m.Patterns)
+	match, ok := MatchMapPatterns(m.Filepath, m.Patterns)
 	if !ok {
 		return Result{}, false, nil
 	}
 
 	return Result{
 		Folder:  filepath.Dir(m.Filepath),
-		Project: result,
+		Project: match.Project,
 	}, true, nil
 }
 
-// matchPattern matches regex against entity's path to find project name.
-func matchPattern(fp string, patterns []MapPattern) (string, bool) {
-	for _, pattern := range patterns {
-		if pattern.Regex.MatchString(fp) {
-			matches := pattern.Regex.FindStringSubmatch(fp)
-			if len(matches) > 0 {
-				params := make([]interface{}, len(matches[1:]))
-				for i, v := range matches[1:] {
-					params[i] = v
-				}
-
-				result, err := pyfmt.Fmt(pattern.Name, params...)
-				if err != nil {
-					log.Errorf("error formatting %q: %s", pattern.Name, err)
-					continue
-				}
-
-				return result, true
-			}
+// MapMatch is a project map pattern matching a file path.
+type MapMatch struct {
+	// Index is the position of the pattern in the config file.
+	Index   int
+	Pattern MapPattern
+	// Project is the pattern's project name, formatted with the matched groups.
+	Project string
+	// Length is the length of the matched text.
+	Length int
+}
+
+// MatchMapPatterns matches patterns against a file path and returns the
+// winning match. Patterns with a higher priority win over longer matches,
+// which win over patterns occurring later.
+func MatchMapPatterns(fp string, patterns []MapPattern) (MapMatch, bool) {
+	var (
+		best  MapMatch
+		found bool
+	)
+
+	for i, pattern := range patterns {
+		matches := pattern.Regex.FindStringSubmatch(fp)
+		if len(matches) == 0 {
+			continue
+		}
+
+		params := make([]interface{}, len(matches[1:]))
+		for i, v := range matches[1:] {
+			params[i] = v
+		}
+
+		result, err := pyfmt.Fmt(pattern.Name, params...)
+		if err != nil {
+			log.Errorf("error formatting %q: %s", pattern.Name, err)
+			continue
 		}
+
+		match := MapMatch{
+			Index:   i,
+			Pattern: pattern,
+			Project: result,
+			Length:  len(matches[0]),
+		}
+
+		if !found || match.wins(best) {
+			best = match
+			found = true
+		}
+	}
+
+	return best, found
+}
+
+// wins returns true, if m takes precedence over other, which occurs earlier.
+func (m MapMatch) wins(other MapMatch) bool {
+	if m.Pattern.Priority != other.Pattern.Priority {
+		return m.Pattern.Priority > other.Pattern.Priority
 	}
 
-	return "", false
+	return m.Length > other.Length
 }
 
 // String returns its name.
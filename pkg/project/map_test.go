@@ -1,6 +1,7 @@
 package project_test
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -30,7 +31,7 @@ func TestMap_Detect(t *testing.T) {
 		},
 	}
 
-	result, detected, err := m.Detect()
+	result, detected, err := m.Detect(context.Background())
 	require.NoError(t, err)
 
 	assert.True(t, detected)
@@ -60,7 +61,7 @@ func TestMap_Detect_RegexReplace(t *testing.T) {
 		},
 	}
 
-	result, detected, err := m.Detect()
+	result, detected, err := m.Detect(context.Background())
 	require.NoError(t, err)
 
 	assert.True(t, detected)
@@ -87,7 +88,7 @@ func TestMap_Detect_NoMatch(t *testing.T) {
 		},
 	}
 
-	result, detected, err := m.Detect()
+	result, detected, err := m.Detect(context.Background())
 	require.NoError(t, err)
 
 	assert.False(t, detected)
@@ -101,12 +102,66 @@ func TestMap_Detect_ZeroPatterns(t *testing.T) {
 		Patterns: []project.MapPattern{},
 	}
 
-	_, detected, err := m.Detect()
+	_, detected, err := m.Detect(context.Background())
 	require.NoError(t, err)
 
 	assert.False(t, detected)
 }
 
+func TestMatchMapPatterns(t *testing.T) {
+	tests := map[string]struct {
+		Patterns      []project.MapPattern
+		ExpectedIndex int
+		Expected      string
+	}{
+		"first match": {
+			Patterns: []project.MapPattern{
+				{Name: "first", Regex: regexp.MustCompile("projects/foo")},
+				{Name: "second", Regex: regexp.MustCompile("projects/fo{2}")},
+			},
+			ExpectedIndex: 0,
+			Expected:      "first",
+		},
+		"longest match": {
+			Patterns: []project.MapPattern{
+				{Name: "projects", Regex: regexp.MustCompile("/home/user/projects/")},
+				{Name: "foo-{0}", Regex: regexp.MustCompile(`/home/user/projects/foo/(\w+)/`)},
+				{Name: "foo", Regex: regexp.MustCompile("/home/user/projects/foo/")},
+			},
+			ExpectedIndex: 1,
+			Expected:      "foo-bar",
+		},
+		"priority": {
+			Patterns: []project.MapPattern{
+				{Name: "foo", Regex: regexp.MustCompile("/home/user/projects/foo/")},
+				{Name: "user", Regex: regexp.MustCompile("/home/user/"), Priority: 1},
+				{Name: "all", Regex: regexp.MustCompile(".*"), Priority: -1},
+			},
+			ExpectedIndex: 1,
+			Expected:      "user",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			match, ok := project.MatchMapPatterns("/home/user/projects/foo/bar/main.go", test.Patterns)
+			require.True(t, ok)
+
+			assert.Equal(t, test.ExpectedIndex, match.Index)
+			assert.Equal(t, test.Patterns[test.ExpectedIndex], match.Pattern)
+			assert.Equal(t, test.Expected, match.Project)
+		})
+	}
+}
+
+func TestMatchMapPatterns_NoMatch(t *testing.T) {
+	_, ok := project.MatchMapPatterns("/home/user/main.go", []project.MapPattern{
+		{Name: "foo", Regex: regexp.MustCompile("projects/foo")},
+	})
+
+	assert.False(t, ok)
+}
+
 func TestMap_String(t *testing.T) {
 	m := project.Map{}
 
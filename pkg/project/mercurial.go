@@ -1,7 +1,9 @@
 package project
 
 import (
+	"context"
 	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
 
@@ -15,7 +17,10 @@ type Mercurial struct {
 }
 
 // Detect gets information about the mercurial project for a given file.
-func (m Mercurial) Detect() (Result, bool, error) {
+// The branch is the active bookmark, or else the evolve topic, or else the
+// named branch. Shares made by "hg share" are named after their source
+// repository.
+func (m Mercurial) Detect(_ context.Context) (Result, bool, error) {
 	log.Debugln("execute mercurial project detection")
 
 	var fp string
@@ -33,6 +38,15 @@ func (m Mercurial) Detect() (Result, bool, error) {
 
 	project := filepath.Base(filepath.Dir(hgDirectory))
 
+	source, ok, err := findHgSharedSource(hgDirectory)
+	if err != nil {
+		log.Warnf("failed to find source repository of mercurial share %q: %s", hgDirectory, err)
+	}
+
+	if ok {
+		project = filepath.Base(filepath.Dir(source))
+	}
+
 	branch, err := findHgBranch(hgDirectory)
 	if err != nil {
 		log.Errorf(
@@ -49,7 +63,21 @@ func (m Mercurial) Detect() (Result, bool, error) {
 	}, true, nil
 }
 
+// findHgBranch returns the active bookmark, or else the topic, or else the
+// named branch. Unreadable bookmark and topic files are skipped.
 func findHgBranch(fp string) (string, error) {
+	for _, name := range []string{"bookmarks.current", "topic"} {
+		value, err := readHgFile(filepath.Join(fp, name))
+		if err != nil {
+			log.Warnf("failed to read mercurial %s: %s", name, err)
+			continue
+		}
+
+		if value != "" {
+			return value, nil
+		}
+	}
+
 	p := filepath.Join(fp, "branch")
 	if !fileExists(p) {
 		return "default", nil
@@ -67,6 +95,39 @@ func findHgBranch(fp string) (string, error) {
 	return "default", nil
 }
 
+// findHgSharedSource returns the .hg directory of the source repository of a
+// share. Relative paths of shares made with "hg share --relative" are resolved
+// against the .hg directory of the share.
+func findHgSharedSource(hgDirectory string) (string, bool, error) {
+	sharedPath, err := readHgFile(filepath.Join(hgDirectory, "sharedpath"))
+	if err != nil || sharedPath == "" {
+		return "", false, err
+	}
+
+	sharedPath = filepath.FromSlash(sharedPath)
+	if !filepath.IsAbs(sharedPath) {
+		sharedPath = filepath.Join(hgDirectory, sharedPath)
+	}
+
+	return filepath.Clean(sharedPath), true, nil
+}
+
+// readHgFile returns the trimmed first line of a file. Returns an empty
+// string, if the file does not exist.
+func readHgFile(fp string) (string, error) {
+	if !fileExists(fp) {
+		return "", nil
+	}
+
+	// unlike readFile, reading fails for directories
+	data, err := os.ReadFile(fp) // nolint:gosec
+	if err != nil {
+		return "", Err(fmt.Sprintf("failed while reading file %q: %s", fp, err))
+	}
+
+	return strings.TrimSpace(strings.SplitN(string(data), "\n", 2)[0]), nil
+}
+
 // String returns its name.
 func (m Mercurial) String() string {
 	return "hg-detector"
@@ -1,6 +1,7 @@
 package project_test
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -18,7 +19,7 @@ func TestMercurial_Detect(t *testing.T) {
 		Filepath: filepath.Join(fp, "wakatime-cli/src/pkg/file.go"),
 	}
 
-	result, detected, err := m.Detect()
+	result, detected, err := m.Detect(context.Background())
 	require.NoError(t, err)
 
 	assert.True(t, detected)
@@ -37,7 +38,7 @@ func TestMercurial_Detect_BranchWithSlash(t *testing.T) {
 		Filepath: filepath.Join(fp, "wakatime-cli/src/pkg/file.go"),
 	}
 
-	result, detected, err := m.Detect()
+	result, detected, err := m.Detect(context.Background())
 	require.NoError(t, err)
 
 	assert.True(t, detected)
@@ -56,7 +57,7 @@ func TestMercurial_Detect_NoBranch(t *testing.T) {
 		Filepath: filepath.Join(fp, "wakatime-cli/src/pkg/file.go"),
 	}
 
-	result, detected, err := m.Detect()
+	result, detected, err := m.Detect(context.Background())
 	require.NoError(t, err)
 
 	assert.True(t, detected)
@@ -68,6 +69,109 @@ func TestMercurial_Detect_NoBranch(t *testing.T) {
 	}, result)
 }
 
+func TestMercurial_Detect_Bookmark(t *testing.T) {
+	fp := setupTestMercurial(t)
+
+	copyFile(t, "testdata/hg/bookmarks.current", filepath.Join(fp, "wakatime-cli/.hg/bookmarks.current"))
+	copyFile(t, "testdata/hg/topic", filepath.Join(fp, "wakatime-cli/.hg/topic"))
+
+	m := project.Mercurial{
+		Filepath: filepath.Join(fp, "wakatime-cli/src/pkg/file.go"),
+	}
+
+	result, detected, err := m.Detect(context.Background())
+	require.NoError(t, err)
+
+	assert.True(t, detected)
+	assert.Equal(t, "wakatime-cli", result.Project)
+	assert.Equal(t, "feature/bookmark", result.Branch)
+}
+
+func TestMercurial_Detect_Topic(t *testing.T) {
+	fp := setupTestMercurial(t)
+
+	copyFile(t, "testdata/hg/topic", filepath.Join(fp, "wakatime-cli/.hg/topic"))
+
+	m := project.Mercurial{
+		Filepath: filepath.Join(fp, "wakatime-cli/src/pkg/file.go"),
+	}
+
+	result, detected, err := m.Detect(context.Background())
+	require.NoError(t, err)
+
+	assert.True(t, detected)
+	assert.Equal(t, "wakatime-cli", result.Project)
+	assert.Equal(t, "faster-sync", result.Branch)
+}
+
+func TestMercurial_Detect_UnreadableBookmark(t *testing.T) {
+	fp := setupTestMercurial(t)
+
+	err := os.Mkdir(filepath.Join(fp, "wakatime-cli/.hg/bookmarks.current"), os.FileMode(int(0700)))
+	require.NoError(t, err)
+
+	copyFile(t, "testdata/hg/topic", filepath.Join(fp, "wakatime-cli/.hg/topic"))
+
+	m := project.Mercurial{
+		Filepath: filepath.Join(fp, "wakatime-cli/src/pkg/file.go"),
+	}
+
+	result, detected, err := m.Detect(context.Background())
+	require.NoError(t, err)
+
+	assert.True(t, detected)
+	assert.Equal(t, "wakatime-cli", result.Project)
+	assert.Equal(t, "faster-sync", result.Branch)
+}
+
+func TestMercurial_Detect_Share(t *testing.T) {
+	tests := map[string]func(t *testing.T, fp string){
+		"absolute path": func(t *testing.T, fp string) {
+			err := os.WriteFile(
+				filepath.Join(fp, "wakatime-cli-share/.hg/sharedpath"),
+				[]byte(filepath.Join(fp, "wakatime-cli/.hg")),
+				0600,
+			)
+			require.NoError(t, err)
+		},
+		"relative path": func(t *testing.T, fp string) {
+			copyFile(t, "testdata/hg/sharedpath_relative", filepath.Join(fp, "wakatime-cli-share/.hg/sharedpath"))
+		},
+	}
+
+	for name, setup := range tests {
+		t.Run(name, func(t *testing.T) {
+			fp := setupTestMercurial(t)
+
+			err := os.MkdirAll(filepath.Join(fp, "wakatime-cli-share/.hg"), os.FileMode(int(0700)))
+			require.NoError(t, err)
+
+			tmpFile, err := os.Create(filepath.Join(fp, "wakatime-cli-share/file.go"))
+			require.NoError(t, err)
+
+			defer tmpFile.Close()
+
+			setup(t, fp)
+
+			copyFile(t, "testdata/hg/bookmarks.current", filepath.Join(fp, "wakatime-cli-share/.hg/bookmarks.current"))
+
+			m := project.Mercurial{
+				Filepath: filepath.Join(fp, "wakatime-cli-share/file.go"),
+			}
+
+			result, detected, err := m.Detect(context.Background())
+			require.NoError(t, err)
+
+			assert.True(t, detected)
+			assert.Equal(t, project.Result{
+				Project: "wakatime-cli",
+				Branch:  "feature/bookmark",
+				Folder:  fp,
+			}, result)
+		})
+	}
+}
+
 func setupTestMercurial(t *testing.T) (fp string) {
 	tmpDir := t.TempDir()
 
@@ -0,0 +1,167 @@
+package project
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/wakatime/wakatime-cli/pkg/log"
+)
+
+const (
+	// defaultP4ConfigFile is the name of perforce config files, if P4CONFIG is not set.
+	defaultP4ConfigFile = ".p4config"
+	// defaultP4EnviroFile is the file in the home folder, where "p4 set" saves
+	// variables, if P4ENVIRO is not set.
+	defaultP4EnviroFile = ".p4enviro"
+)
+
+// Perforce contains perforce data.
+type Perforce struct {
+	// Filepath contains the entity path.
+	Filepath string
+}
+
+// Detect gets information about the perforce workspace for a given file,
+// from the nearest P4CONFIG file. The server is never contacted, so the
+// stream is read from a P4STREAM variable, like "P4STREAM=//game/main", which
+// p4 itself ignores. Variables are resolved like p4 does, from the P4CONFIG
+// file, the environment and the P4ENVIRO file. The project is the depot of the
+// stream, or else the client, or else the folder name.
+func (p Perforce) Detect(_ context.Context) (Result, bool, error) {
+	log.Debugln("execute perforce project detection")
+
+	name := strings.TrimSpace(os.Getenv("P4CONFIG"))
+
+	switch name {
+	case "":
+		name = defaultP4ConfigFile
+	case "noconfig":
+		return Result{}, false, nil
+	}
+
+	var fp string
+
+	// Take only the directory
+	if fileExists(p.Filepath) {
+		fp = filepath.Dir(p.Filepath)
+	}
+
+	configFile, ok := FindFileOrDirectory(fp, filepath.Base(name))
+	if !ok {
+		return Result{}, false, nil
+	}
+
+	vars, err := readP4Config(configFile)
+	if err != nil {
+		return Result{}, false, Err(fmt.Sprintf("failed to read perforce config: %s", err))
+	}
+
+	lookup := p4Lookup(vars)
+	root := filepath.Dir(configFile)
+	depot, stream := splitDepotPath(lookup("P4STREAM"))
+
+	return Result{
+		Project: firstNonEmptyString(depot, lookup("P4CLIENT"), filepath.Base(root)),
+		Branch:  stream,
+		Folder:  root,
+	}, true, nil
+}
+
+// p4Lookup returns a func looking up a perforce variable in the variables of
+// a P4CONFIG file, then the environment, then the P4ENVIRO file. The P4ENVIRO
+// file is read once, when needed.
+func p4Lookup(vars map[string]string) func(string) string {
+	var enviro map[string]string
+
+	return func(name string) string {
+		if value := vars[name]; value != "" {
+			return value
+		}
+
+		if value := strings.TrimSpace(os.Getenv(name)); value != "" {
+			return value
+		}
+
+		if enviro == nil {
+			enviro = readP4Enviro()
+		}
+
+		return enviro[name]
+	}
+}
+
+// readP4Enviro returns the variables of the P4ENVIRO file. Returns empty
+// variables, if the file cannot be read.
+func readP4Enviro() map[string]string {
+	fp := strings.TrimSpace(os.Getenv("P4ENVIRO"))
+	if fp == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			log.Debugf("failed to find home folder for P4ENVIRO file: %s", err)
+			return map[string]string{}
+		}
+
+		fp = filepath.Join(home, defaultP4EnviroFile)
+	}
+
+	if !fileExists(fp) {
+		return map[string]string{}
+	}
+
+	vars, err := readP4Config(fp)
+	if err != nil {
+		log.Warnf("failed to read perforce P4ENVIRO file %q: %s", fp, err)
+		return map[string]string{}
+	}
+
+	return vars
+}
+
+// readP4Config returns the variables of a P4CONFIG file.
+func readP4Config(fp string) (map[string]string, error) {
+	vars := make(map[string]string)
+
+	err := scanLines(fp, func(line string) bool {
+		if line == "" || strings.HasPrefix(line, "#") {
+			return true
+		}
+
+		parts := strings.SplitN(line, "=", 2)
+		if len(parts) != 2 {
+			return true
+		}
+
+		vars[strings.ToUpper(strings.TrimSpace(parts[0]))] = strings.TrimSpace(parts[1])
+
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return vars, nil
+}
+
+// splitDepotPath splits a depot path like "//game/dev/feature" into depot
+// "game" and path "dev/feature".
+func splitDepotPath(depotPath string) (string, string) {
+	depotPath = strings.Trim(strings.TrimPrefix(depotPath, "//"), "/")
+	if depotPath == "" {
+		return "", ""
+	}
+
+	parts := strings.SplitN(depotPath, "/", 2)
+	if len(parts) == 1 {
+		return parts[0], ""
+	}
+
+	return parts[0], parts[1]
+}
+
+// String returns its name.
+func (Perforce) String() string {
+	return "p4-detector"
+}
@@ -0,0 +1,149 @@
+package project_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/project"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPerforce_Detect(t *testing.T) {
+	t.Setenv("P4CONFIG", "")
+
+	fp := setupTestPerforce(t, ".p4config", "testdata/p4/p4config")
+
+	p := project.Perforce{
+		Filepath: filepath.Join(fp, "game/Source/Engine/main.cpp"),
+	}
+
+	result, detected, err := p.Detect(context.Background())
+	require.NoError(t, err)
+
+	assert.True(t, detected)
+	assert.Equal(t, project.Result{
+		Project: "alan_workstation_game",
+		Folder:  filepath.Join(fp, "game"),
+	}, result)
+}
+
+func TestPerforce_Detect_Stream(t *testing.T) {
+	t.Setenv("P4CONFIG", "")
+
+	fp := setupTestPerforce(t, ".p4config", "testdata/p4/p4config_stream")
+
+	p := project.Perforce{
+		Filepath: filepath.Join(fp, "game/Source/Engine/main.cpp"),
+	}
+
+	result, detected, err := p.Detect(context.Background())
+	require.NoError(t, err)
+
+	assert.True(t, detected)
+	assert.Equal(t, project.Result{
+		Project: "game",
+		Branch:  "dev/feature-lighting",
+		Folder:  filepath.Join(fp, "game"),
+	}, result)
+}
+
+func TestPerforce_Detect_StreamFromEnviro(t *testing.T) {
+	t.Setenv("P4CONFIG", "")
+
+	fp := setupTestPerforce(t, ".p4config", "testdata/p4/p4config")
+
+	// saved by "p4 set P4STREAM=//game/main"
+	err := os.WriteFile(filepath.Join(fp, "p4enviro"), []byte("P4STREAM=//game/main\n"), 0600)
+	require.NoError(t, err)
+
+	t.Setenv("P4ENVIRO", filepath.Join(fp, "p4enviro"))
+
+	p := project.Perforce{
+		Filepath: filepath.Join(fp, "game/Source/Engine/main.cpp"),
+	}
+
+	result, detected, err := p.Detect(context.Background())
+	require.NoError(t, err)
+
+	assert.True(t, detected)
+	assert.Equal(t, project.Result{
+		Project: "game",
+		Branch:  "main",
+		Folder:  filepath.Join(fp, "game"),
+	}, result)
+}
+
+func TestPerforce_Detect_StreamFromEnv(t *testing.T) {
+	t.Setenv("P4CONFIG", "")
+
+	fp := setupTestPerforce(t, ".p4config", "testdata/p4/p4config")
+
+	t.Setenv("P4STREAM", "//game/release/1.0")
+
+	p := project.Perforce{
+		Filepath: filepath.Join(fp, "game/Source/Engine/main.cpp"),
+	}
+
+	result, detected, err := p.Detect(context.Background())
+	require.NoError(t, err)
+
+	assert.True(t, detected)
+	assert.Equal(t, "game", result.Project)
+	assert.Equal(t, "release/1.0", result.Branch)
+}
+
+func TestPerforce_Detect_ConfigFromEnv(t *testing.T) {
+	t.Setenv("P4CONFIG", "p4.ini")
+
+	fp := setupTestPerforce(t, "p4.ini", "testdata/p4/p4config")
+
+	p := project.Perforce{
+		Filepath: filepath.Join(fp, "game/Source/Engine/main.cpp"),
+	}
+
+	result, detected, err := p.Detect(context.Background())
+	require.NoError(t, err)
+
+	assert.True(t, detected)
+	assert.Equal(t, "alan_workstation_game", result.Project)
+}
+
+func TestPerforce_Detect_NoConfig(t *testing.T) {
+	t.Setenv("P4CONFIG", "noconfig")
+
+	fp := setupTestPerforce(t, ".p4config", "testdata/p4/p4config")
+
+	p := project.Perforce{
+		Filepath: filepath.Join(fp, "game/Source/Engine/main.cpp"),
+	}
+
+	_, detected, err := p.Detect(context.Background())
+	require.NoError(t, err)
+
+	assert.False(t, detected)
+}
+
+func setupTestPerforce(t *testing.T, configFile, fixture string) (fp string) {
+	tmpDir := t.TempDir()
+
+	// ignore variables of the environment and the P4ENVIRO file
+	t.Setenv("P4CLIENT", "")
+	t.Setenv("P4STREAM", "")
+	t.Setenv("P4ENVIRO", filepath.Join(tmpDir, "p4enviro"))
+
+	err := os.MkdirAll(filepath.Join(tmpDir, "game/Source/Engine"), os.FileMode(int(0700)))
+	require.NoError(t, err)
+
+	tmpFile, err := os.Create(filepath.Join(tmpDir, "game/Source/Engine/main.cpp"))
+	require.NoError(t, err)
+
+	defer tmpFile.Close()
+
+	copyFile(t, fixture, filepath.Join(tmpDir, "game", configFile))
+
+	return tmpDir
+}
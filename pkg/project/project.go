@@ -1,6 +1,7 @@
 package project
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
 	"os"
@@ -30,7 +31,7 @@ const (
 
 // Detecter is a common interface for project.
 type Detecter interface {
-	Detect() (Result, bool, error)
+	Detect(ctx context.Context) (Result, bool, error)
 	String() string
 }
 
@@ -38,17 +39,28 @@ type Detecter interface {
 type Result struct {
 	Project string
 	Branch  string
-	Folder  string
+	// Commit is the sha of the checked out commit. Detected for git only.
+	Commit string
+	Folder string
 }
 
 // Config contains project detection configurations.
 type Config struct {
+	// DetectAppFolder detects the project of app heartbeats from their project
+	// folder, like for shell commands. Otherwise only file heartbeats are detected.
+	DetectAppFolder bool
 	// Patterns contains the overridden project name per path.
 	MapPatterns []MapPattern
+	// GitRemote configures naming git projects after a remote url.
+	GitRemote GitRemote
+	// MonorepoPatterns enables sub-project detection from manifest files per repository.
+	MonorepoPatterns []MonorepoPattern
 	// SubmodulePatterns contains the paths to validate for submodules.
 	SubmodulePatterns []regex.Regex
 	// ShouldObfuscateProject determines if the project name should be obfuscated according some rules.
 	ShouldObfuscateProject bool
+	// SendCommit adds the sha of the checked out git commit to heartbeats.
+	SendCommit bool
 }
 
 // MapPattern contains [projectmap] data.
@@ -57,49 +69,99 @@ type MapPattern struct {
 	Name string
 	// Regex is the regular expression for a specific path.
 	Regex regex.Regex
+	// Priority of the pattern, if multiple patterns match. Defaults to 0.
+	Priority int
+}
+
+// String implements fmt.Stringer interface.
+func (p MapPattern) String() string {
+	return fmt.Sprintf("%s = %s [priority=%d]", p.Regex, p.Name, p.Priority)
+}
+
+// MonorepoPattern contains [monorepo] data.
+type MonorepoPattern struct {
+	// Regex is the regular expression for a repository root folder.
+	Regex regex.Regex
+	// Manifests are the file names of manifests. Defaults to DefaultManifests().
+	Manifests []string
 }
 
 // WithDetection finds the current project and branch.
 // First looks for a .wakatime-project file. Second, uses the --project arg.
 // Third, uses the folder name from a revision control repository. Last, uses
-// the --alternate-project arg.
+// the --alternate-project arg. App heartbeats are detected from their
+// --project-folder arg, if passed and enabled by config.
 func WithDetection(config Config) heartbeat.HandleOption {
 	return func(next heartbeat.Handle) heartbeat.Handle {
-		return func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		return func(ctx context.Context, hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
 			log.Debugln("execute project detection")
 
 			for n, h := range hh {
+				entity := h.Entity
+
 				if h.EntityType != heartbeat.FileType {
-					project := firstNonEmptyString(h.ProjectOverride, h.ProjectAlternate)
-					hh[n].Project = &project
+					if !config.DetectAppFolder || h.EntityType != heartbeat.AppType || h.ProjectPathOverride == "" {
+						project := firstNonEmptyString(h.ProjectOverride, h.ProjectAlternate)
+						hh[n].Project = &project
+
+						continue
+					}
 
-					continue
+					// detecters start searching at the directory of the entity
+					entity = filepath.Join(h.ProjectPathOverride, WakaTimeProjectFile)
 				}
 
-				result := Detect(h.Entity, config.MapPatterns)
+				// parsed once for detection and tracking settings
+				fileConfig, hasFile, err := FindFileConfig(entity)
+				if err != nil {
+					log.Warnf("failed to parse %s file: %s", WakaTimeProjectFile, err)
+				}
+
+				result := detect(ctx, entity, config.MapPatterns, fileConfig, hasFile)
 
 				if result.Project == "" {
 					result.Project = h.ProjectOverride
 				}
 
-				if result.Project == "" || result.Branch == "" {
-					revControlResult := DetectWithRevControl(h.Entity, config.SubmodulePatterns)
+				isTemplate := fileConfig.IsTemplate() && result.Project == fileConfig.Project
+
+				if result.Project == "" || result.Branch == "" || isTemplate || config.SendCommit {
+					revControlResult := DetectWithRevControl(ctx, entity, config.SubmodulePatterns, config.GitRemote)
 
 					result.Branch = firstNonEmptyString(result.Branch, revControlResult.Branch)
+					result.Commit = revControlResult.Commit
 					result.Folder = firstNonEmptyString(result.Folder, revControlResult.Folder)
 
+					switch {
+					case isTemplate:
+						result.Project = fileConfig.FormatProject(revControlResult.Project, result.Branch, result.Folder)
 					// obfuscate project will only run when hide project name is set and a project has been auto-detected and
 					// any project name has been set by the user either by wakatime file or map patterns.
-					if config.ShouldObfuscateProject && revControlResult.Project != "" && result.Project == "" {
+					case config.ShouldObfuscateProject && revControlResult.Project != "" && result.Project == "":
+						log.AddRedactedNames(revControlResult.Project)
+
 						result.Project = setProjectName(h.ProjectAlternate, config.ShouldObfuscateProject, result.Folder)
-					} else {
-						result.Project = firstNonEmptyString(result.Project, revControlResult.Project)
+					case result.Project == "" && revControlResult.Project != "":
+						subResult, ok := DetectMonorepo(ctx, entity, revControlResult.Folder, config.MonorepoPatterns)
+						if ok {
+							result.Folder = subResult.Folder
+						}
+
+						result.Project = firstNonEmptyString(subResult.Project, revControlResult.Project)
 					}
 				}
 
 				hh[n].Branch = &result.Branch
 				hh[n].Project = &result.Project
 
+				if config.SendCommit && result.Commit != "" {
+					hh[n].Commit = &result.Commit
+				}
+
+				if fileConfig.Structured {
+					hh[n] = fileConfig.apply(hh[n])
+				}
+
 				if runtime.GOOS == "windows" && result.Folder != "" {
 					formatted, err := windows.FormatFilePath(result.Folder)
 					if err != nil {
@@ -112,56 +174,104 @@ func WithDetection(config Config) heartbeat.HandleOption {
 				hh[n].ProjectPath = result.Folder
 			}
 
-			return next(hh)
+			return next(ctx, hh)
 		}
 	}
 }
 
 // Detect finds the current project and branch from config plugins.
-func Detect(entity string, patterns []MapPattern) Result {
-	var configPlugins []Detecter = []Detecter{
-		File{
-			Filepath: entity,
-		},
-		Map{
-			Filepath: entity,
-			Patterns: patterns,
-		},
+func Detect(ctx context.Context, entity string, patterns []MapPattern) Result {
+	fileConfig, hasFile, err := FindFileConfig(entity)
+	if err != nil {
+		log.Errorf("unexpected error occurred at %q: %s", File{}.String(), err)
 	}
 
-	for _, p := range configPlugins {
-		result, detected, err := p.Detect()
-		if err != nil {
-			log.Errorf("unexpected error occurred at %q: %s", p.String(), err)
-			continue
-		} else if detected {
+	return detect(ctx, entity, patterns, fileConfig, hasFile)
+}
+
+// detect finds the current project and branch from an already parsed
+// .wakatime-project file, or else from map patterns.
+func detect(ctx context.Context, entity string, patterns []MapPattern, fileConfig FileConfig, hasFile bool) Result {
+	if hasFile {
+		if result, ok := fileConfig.result(); ok {
 			return result
 		}
 	}
 
+	p := Map{
+		Filepath: entity,
+		Patterns: patterns,
+	}
+
+	result, detected, err := p.Detect(ctx)
+	if err != nil {
+		log.Errorf("unexpected error occurred at %q: %s", p.String(), err)
+		return Result{}
+	}
+
+	if detected {
+		return result
+	}
+
 	return Result{}
 }
 
+// FindFolder finds the project folder of an entity, from a .wakatime-project
+// file or else from rev control. Git submodules matching submodulePatterns
+// are not detected as separate projects.
+func FindFolder(ctx context.Context, entity string, submodulePatterns []regex.Regex) (string, bool) {
+	if fp, ok := FindFileOrDirectory(entity, WakaTimeProjectFile); ok {
+		return filepath.Dir(fp), true
+	}
+
+	result := DetectWithRevControl(ctx, entity, submodulePatterns, GitRemote{})
+	if result.Folder == "" {
+		return "", false
+	}
+
+	return result.Folder, true
+}
+
 // DetectWithRevControl finds the current project and branch from rev control.
-func DetectWithRevControl(entity string, submodulePatterns []regex.Regex) Result {
+// Detecters calling external binaries are aborted, once ctx is done.
+func DetectWithRevControl(
+	ctx context.Context,
+	entity string,
+	submodulePatterns []regex.Regex,
+	gitRemote GitRemote,
+) Result {
 	var revControlPlugins []Detecter = []Detecter{
+		// colocated jujutsu workspaces are git repositories with a detached HEAD
+		Jujutsu{
+			Filepath: entity,
+		},
 		Git{
 			Filepath:          entity,
 			SubmodulePatterns: submodulePatterns,
+			Remote:            gitRemote,
 		},
 		Mercurial{
 			Filepath: entity,
 		},
+		Fossil{
+			Filepath: entity,
+		},
+		Bazaar{
+			Filepath: entity,
+		},
 		Subversion{
 			Filepath: entity,
 		},
 		Tfvc{
 			Filepath: entity,
 		},
+		Perforce{
+			Filepath: entity,
+		},
 	}
 
 	for _, p := range revControlPlugins {
-		result, detected, err := p.Detect()
+		result, detected, err := p.Detect(ctx)
 		if err != nil {
 			log.Errorf("unexpected error occurred at %q: %s", p.String(), err)
 			continue
@@ -171,6 +281,7 @@ func DetectWithRevControl(entity string, submodulePatterns []regex.Regex) Result
 			result := Result{
 				Project: result.Project,
 				Branch:  result.Branch,
+				Commit:  result.Commit,
 				Folder:  result.Folder,
 			}
 
@@ -181,6 +292,32 @@ func DetectWithRevControl(entity string, submodulePatterns []regex.Regex) Result
 	return Result{}
 }
 
+// DetectMonorepo finds the sub-project of a file from manifest files, if the
+// repository folder matches a monorepo pattern.
+func DetectMonorepo(ctx context.Context, entity, folder string, patterns []MonorepoPattern) (Result, bool) {
+	for _, pattern := range patterns {
+		if !pattern.Regex.MatchString(folder) {
+			continue
+		}
+
+		m := Manifest{
+			Filepath:  entity,
+			Root:      folder,
+			Manifests: pattern.Manifests,
+		}
+
+		result, detected, err := m.Detect(ctx)
+		if err != nil {
+			log.Errorf("unexpected error occurred at %q: %s", m.String(), err)
+			return Result{}, false
+		}
+
+		return result, detected
+	}
+
+	return Result{}, false
+}
+
 func setProjectName(alternate string, shouldObfuscateProject bool, folder string) string {
 	if !shouldObfuscateProject {
 		return alternate
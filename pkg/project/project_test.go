@@ -1,6 +1,7 @@
 package project_test
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"runtime"
@@ -8,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/log"
 	"github.com/wakatime/wakatime-cli/pkg/project"
 	"github.com/wakatime/wakatime-cli/pkg/regex"
 	"github.com/wakatime/wakatime-cli/pkg/windows"
@@ -68,7 +70,7 @@ func TestWithDetection_EntityNotFile(t *testing.T) {
 		t.Run(name, func(t *testing.T) {
 			opt := project.WithDetection(project.Config{})
 
-			handle := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+			handle := opt(func(_ context.Context, hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
 				assert.Equal(t, []heartbeat.Heartbeat{
 					test.Expected,
 				}, hh)
@@ -76,12 +78,85 @@ func TestWithDetection_EntityNotFile(t *testing.T) {
 				return nil, nil
 			})
 
-			_, err := handle(test.Heartbeats)
+			_, err := handle(context.Background(), test.Heartbeats)
 			require.NoError(t, err)
 		})
 	}
 }
 
+func TestWithDetection_AppProjectFolder(t *testing.T) {
+	fp := setupTestGitBasic(t)
+
+	projectPath := filepath.Join(fp, "wakatime-cli")
+
+	if runtime.GOOS == "windows" {
+		var err error
+
+		projectPath, err = windows.FormatFilePath(projectPath)
+		require.NoError(t, err)
+	}
+
+	opt := project.WithDetection(project.Config{
+		DetectAppFolder: true,
+	})
+
+	handle := opt(func(_ context.Context, hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		assert.Equal(t, []heartbeat.Heartbeat{
+			{
+				Branch:              heartbeat.String("master"),
+				Entity:              "kubectl",
+				EntityType:          heartbeat.AppType,
+				Project:             heartbeat.String("wakatime-cli"),
+				ProjectPath:         projectPath,
+				ProjectPathOverride: projectPath,
+			},
+		}, hh)
+
+		return nil, nil
+	})
+
+	_, err := handle(context.Background(), []heartbeat.Heartbeat{
+		{
+			Entity:              "kubectl",
+			EntityType:          heartbeat.AppType,
+			ProjectPathOverride: projectPath,
+		},
+	})
+	require.NoError(t, err)
+}
+
+func TestWithDetection_AppProjectFolder_Disabled(t *testing.T) {
+	fp := setupTestGitBasic(t)
+
+	projectPath := filepath.Join(fp, "wakatime-cli")
+
+	opt := project.WithDetection(project.Config{})
+
+	handle := opt(func(_ context.Context, hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		assert.Equal(t, []heartbeat.Heartbeat{
+			{
+				Entity:              "vscode",
+				EntityType:          heartbeat.AppType,
+				Project:             heartbeat.String("billing"),
+				ProjectOverride:     "billing",
+				ProjectPathOverride: projectPath,
+			},
+		}, hh)
+
+		return nil, nil
+	})
+
+	_, err := handle(context.Background(), []heartbeat.Heartbeat{
+		{
+			Entity:              "vscode",
+			EntityType:          heartbeat.AppType,
+			ProjectOverride:     "billing",
+			ProjectPathOverride: projectPath,
+		},
+	})
+	require.NoError(t, err)
+}
+
 func TestWithDetection_OverrideTakesPrecedence(t *testing.T) {
 	fp := setupTestGitBasic(t)
 
@@ -100,7 +175,7 @@ func TestWithDetection_OverrideTakesPrecedence(t *testing.T) {
 
 	opt := project.WithDetection(project.Config{})
 
-	handle := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+	handle := opt(func(_ context.Context, hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
 		assert.Equal(t, []heartbeat.Heartbeat{
 			{
 				Entity:          entity,
@@ -115,7 +190,7 @@ func TestWithDetection_OverrideTakesPrecedence(t *testing.T) {
 		return nil, nil
 	})
 
-	_, err := handle([]heartbeat.Heartbeat{
+	_, err := handle(context.Background(), []heartbeat.Heartbeat{
 		{
 			EntityType:      heartbeat.FileType,
 			Entity:          entity,
@@ -141,12 +216,16 @@ func TestWithDetection_ObfuscateProject(t *testing.T) {
 		require.NoError(t, err)
 	}
 
+	log.SetRedaction(log.Redaction{})
+	defer log.SetRedaction(log.Redaction{})
+
 	opt := project.WithDetection(project.Config{
 		ShouldObfuscateProject: true,
 	})
 
-	handle := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+	handle := opt(func(_ context.Context, hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
 		assert.NotEmpty(t, hh[0].Project)
+		assert.Equal(t, "project HIDDEN", log.Redact("project wakatime-cli"))
 		assert.Equal(t, []heartbeat.Heartbeat{
 			{
 				Entity:      entity,
@@ -160,7 +239,7 @@ func TestWithDetection_ObfuscateProject(t *testing.T) {
 		return nil, nil
 	})
 
-	_, err := handle([]heartbeat.Heartbeat{
+	_, err := handle(context.Background(), []heartbeat.Heartbeat{
 		{
 			EntityType: heartbeat.FileType,
 			Entity:     entity,
@@ -171,6 +250,41 @@ func TestWithDetection_ObfuscateProject(t *testing.T) {
 	assert.FileExists(t, filepath.Join(fp, "wakatime-cli/.wakatime-project"))
 }
 
+func TestWithDetection_SendCommit(t *testing.T) {
+	fp := setupTestGitBasic(t)
+
+	copyFile(t, "testdata/git_basic/HEAD_DETACHED", filepath.Join(fp, "wakatime-cli/.git/HEAD"))
+	copyFile(t, "testdata/git_refs/packed-refs", filepath.Join(fp, "wakatime-cli/.git/packed-refs"))
+
+	entity := filepath.Join(fp, "wakatime-cli/src/pkg/file.go")
+
+	for _, sendCommit := range []bool{true, false} {
+		opt := project.WithDetection(project.Config{
+			SendCommit: sendCommit,
+		})
+
+		handle := opt(func(_ context.Context, hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+			assert.Equal(t, heartbeat.String("v1.0.0"), hh[0].Branch)
+
+			if sendCommit {
+				assert.Equal(t, heartbeat.String("f4f242d698fa07c298592a66d6546ac9b6b34d1e"), hh[0].Commit)
+			} else {
+				assert.Nil(t, hh[0].Commit)
+			}
+
+			return nil, nil
+		})
+
+		_, err := handle(context.Background(), []heartbeat.Heartbeat{
+			{
+				EntityType: heartbeat.FileType,
+				Entity:     entity,
+			},
+		})
+		require.NoError(t, err)
+	}
+}
+
 func TestWithDetection_WakatimeProjectTakesPrecedence(t *testing.T) {
 	fp := setupTestGitBasic(t)
 
@@ -197,7 +311,7 @@ func TestWithDetection_WakatimeProjectTakesPrecedence(t *testing.T) {
 		ShouldObfuscateProject: true,
 	})
 
-	handle := opt(func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+	handle := opt(func(_ context.Context, hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
 		assert.NotEmpty(t, hh[0].Project)
 		assert.Equal(t, []heartbeat.Heartbeat{
 			{
@@ -212,7 +326,7 @@ func TestWithDetection_WakatimeProjectTakesPrecedence(t *testing.T) {
 		return nil, nil
 	})
 
-	_, err := handle([]heartbeat.Heartbeat{
+	_, err := handle(context.Background(), []heartbeat.Heartbeat{
 		{
 			EntityType: heartbeat.FileType,
 			Entity:     entity,
@@ -221,8 +335,62 @@ func TestWithDetection_WakatimeProjectTakesPrecedence(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestWithDetection_StructuredProjectFile(t *testing.T) {
+	fp := setupTestGitBasic(t)
+
+	entity := filepath.Join(fp, "wakatime-cli/src/pkg/file.go")
+	projectPath := filepath.Join(fp, "wakatime-cli")
+
+	if runtime.GOOS == "windows" {
+		var err error
+
+		entity, err = windows.FormatFilePath(entity)
+		require.NoError(t, err)
+
+		projectPath, err = windows.FormatFilePath(projectPath)
+		require.NoError(t, err)
+	}
+
+	copyFile(
+		t,
+		"testdata/.wakatime-project-structured",
+		filepath.Join(fp, "wakatime-cli", ".wakatime-project"),
+	)
+
+	opt := project.WithDetection(project.Config{})
+
+	handle := opt(func(_ context.Context, hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		require.Len(t, hh, 2)
+
+		assert.Equal(t, "wakatime-cli-release", *hh[0].Project)
+		assert.Equal(t, "release", *hh[0].Branch)
+		assert.Equal(t, projectPath, hh[0].ProjectPath)
+		assert.Equal(t, heartbeat.CodeReviewingCategory, hh[0].Category)
+		require.NotNil(t, hh[0].ProjectSettings)
+		assert.True(t, hh[0].ProjectSettings.HideProjectFolder)
+
+		// explicitly passed categories are kept
+		assert.Equal(t, heartbeat.DebuggingCategory, hh[1].Category)
+
+		return nil, nil
+	})
+
+	_, err := handle(context.Background(), []heartbeat.Heartbeat{
+		{
+			EntityType: heartbeat.FileType,
+			Entity:     entity,
+		},
+		{
+			Category:   heartbeat.DebuggingCategory,
+			EntityType: heartbeat.FileType,
+			Entity:     entity,
+		},
+	})
+	require.NoError(t, err)
+}
+
 func TestDetect_FileDetected(t *testing.T) {
-	result := project.Detect("testdata/entity.any", []project.MapPattern{})
+	result := project.Detect(context.Background(), "testdata/entity.any", []project.MapPattern{})
 
 	assert.Equal(t, "master", result.Branch)
 	assert.Contains(t, result.Folder, "testdata")
@@ -248,7 +416,7 @@ func TestDetect_MapDetected(t *testing.T) {
 		},
 	}
 
-	result := project.Detect(tmpFile.Name(), patterns)
+	result := project.Detect(context.Background(), tmpFile.Name(), patterns)
 
 	assert.Empty(t, result.Branch)
 	assert.Contains(t, result.Folder, filepath.Dir(tmpFile.Name()))
@@ -259,8 +427,10 @@ func TestDetectWithRevControl_GitDetected(t *testing.T) {
 	fp := setupTestGitBasic(t)
 
 	result := project.DetectWithRevControl(
+		context.Background(),
 		filepath.Join(fp, "wakatime-cli/src/pkg/file.go"),
 		[]regex.Regex{},
+		project.GitRemote{},
 	)
 
 	assert.Contains(t, result.Folder, filepath.Join(fp, "wakatime-cli"))
@@ -271,13 +441,51 @@ func TestDetectWithRevControl_GitDetected(t *testing.T) {
 	}, result)
 }
 
+func TestDetectWithRevControl_ColocatedJujutsuWithoutBookmarkDetectedAsGit(t *testing.T) {
+	fp := setupTestGitBasic(t)
+
+	err := os.Mkdir(filepath.Join(fp, "wakatime-cli/.jj"), os.FileMode(int(0700)))
+	require.NoError(t, err)
+
+	copyFile(t, "testdata/git_basic/HEAD_DETACHED", filepath.Join(fp, "wakatime-cli/.git/HEAD"))
+	writeLooseRef(t, filepath.Join(fp, "wakatime-cli/.git"), "refs/heads/bookmark",
+		"f4f242d698fa07c298592a66d6546ac9b6b34d1e")
+
+	result := project.DetectWithRevControl(
+		context.Background(),
+		filepath.Join(fp, "wakatime-cli/src/pkg/file.go"),
+		[]regex.Regex{},
+		project.GitRemote{},
+	)
+
+	assert.Equal(t, "wakatime-cli", result.Project)
+	assert.Equal(t, "bookmark", result.Branch)
+}
+
+func TestDetectWithRevControl_FossilDetected(t *testing.T) {
+	fp := setupTestFossil(t, ".fslckout")
+
+	result := project.DetectWithRevControl(
+		context.Background(),
+		filepath.Join(fp, "wakatime-cli/src/pkg/file.go"),
+		[]regex.Regex{},
+		project.GitRemote{},
+	)
+
+	assert.Equal(t, project.Result{
+		Project: "wakatime-cli",
+		Folder:  filepath.Join(fp, "wakatime-cli"),
+		Branch:  "feature/fossil",
+	}, result)
+}
+
 func TestDetect_NoProjectDetected(t *testing.T) {
 	tmpFile, err := os.CreateTemp(t.TempDir(), "wakatime")
 	require.NoError(t, err)
 
 	defer tmpFile.Close()
 
-	result := project.Detect(tmpFile.Name(), []project.MapPattern{})
+	result := project.Detect(context.Background(), tmpFile.Name(), []project.MapPattern{})
 
 	assert.Empty(t, result.Branch)
 	assert.Empty(t, result.Folder)
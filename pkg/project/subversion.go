@@ -1,6 +1,8 @@
 package project
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os/exec"
 	"path/filepath"
@@ -8,6 +10,7 @@ import (
 	"strings"
 
 	"github.com/wakatime/wakatime-cli/pkg/log"
+	"github.com/wakatime/wakatime-cli/pkg/sqlite"
 )
 
 // Subversion contains svn data.
@@ -17,15 +20,11 @@ type Subversion struct {
 }
 
 // Detect gets information about the svn project for a given file.
-func (s Subversion) Detect() (Result, bool, error) {
+// The working copy database is read directly. The svn binary is only called,
+// if the database cannot be read, like for unsupported working copy formats.
+func (s Subversion) Detect(ctx context.Context) (Result, bool, error) {
 	log.Debugln("execute subversion project detection")
 
-	binary, ok := findSvnBinary()
-	if !ok {
-		log.Debugln("svn binary not found")
-		return Result{}, false, nil
-	}
-
 	var fp string
 
 	// Take only the directory
@@ -39,13 +38,24 @@ func (s Subversion) Detect() (Result, bool, error) {
 		return Result{}, false, nil
 	}
 
-	info, ok, err := svnInfo(filepath.Join(svnConfigFile, "..", ".."), binary)
+	info, err := readSvnInfo(svnConfigFile)
 	if err != nil {
-		return Result{}, false, Err(fmt.Errorf("failed to get svn info: %w", err).Error())
-	}
+		log.Debugf("failed to read svn working copy %q: %s", svnConfigFile, err)
 
-	if !ok {
-		return Result{}, false, nil
+		binary, ok := findSvnBinary(ctx)
+		if !ok {
+			log.Debugln("svn binary not found")
+			return Result{}, false, nil
+		}
+
+		info, ok, err = svnInfo(ctx, filepath.Join(svnConfigFile, "..", ".."), binary)
+		if err != nil {
+			return Result{}, false, Err(fmt.Errorf("failed to get svn info: %w", err).Error())
+		}
+
+		if !ok {
+			return Result{}, false, nil
+		}
 	}
 
 	return Result{
@@ -55,12 +65,75 @@ func (s Subversion) Detect() (Result, bool, error) {
 	}, true, nil
 }
 
-func svnInfo(fp string, binary string) (map[string]string, bool, error) {
-	if runtime.GOOS == "darwin" && !hasXcodeTools() {
+// readSvnInfo reads the repository root and url of the working copy root
+// from its wc.db file, like "svn info" prints them.
+func readSvnInfo(fp string) (map[string]string, error) {
+	db, err := sqlite.Open(fp)
+	if err != nil {
+		return nil, err
+	}
+
+	defer db.Close()
+
+	var (
+		reposID   int64
+		reposPath string
+		found     bool
+	)
+
+	// the base node of the working copy root has op depth 0
+	err = db.Scan("NODES", func(row sqlite.Row) bool {
+		if row.String("local_relpath") != "" || row.Int("op_depth") != 0 {
+			return true
+		}
+
+		reposID, reposPath, found = row.Int("repos_id"), row.String("repos_path"), true
+
+		return false
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read nodes: %s", err)
+	}
+
+	if !found {
+		return nil, errors.New("working copy root not found")
+	}
+
+	var root string
+
+	err = db.Scan("REPOSITORY", func(row sqlite.Row) bool {
+		if row.Int("id") == reposID {
+			root = row.String("root")
+			return false
+		}
+
+		return true
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read repositories: %s", err)
+	}
+
+	if root == "" {
+		return nil, fmt.Errorf("repository %d not found", reposID)
+	}
+
+	url := root
+	if reposPath != "" {
+		url = strings.TrimSuffix(root, "/") + "/" + reposPath
+	}
+
+	return map[string]string{
+		"Repository Root": root,
+		"URL":             url,
+	}, nil
+}
+
+func svnInfo(ctx context.Context, fp string, binary string) (map[string]string, bool, error) {
+	if runtime.GOOS == "darwin" && !hasXcodeTools(ctx) {
 		return nil, false, nil
 	}
 
-	cmd := exec.Command(binary, "info", fp)
+	cmd := exec.CommandContext(ctx, binary, "info", fp)
 	out, err := cmd.Output()
 
 	if err != nil {
@@ -79,7 +152,7 @@ func svnInfo(fp string, binary string) (map[string]string, bool, error) {
 	return result, true, nil
 }
 
-func findSvnBinary() (string, bool) {
+func findSvnBinary(ctx context.Context) (string, bool) {
 	locations := []string{
 		"svn",
 		"/usr/bin/svn",
@@ -87,7 +160,7 @@ func findSvnBinary() (string, bool) {
 	}
 
 	for _, loc := range locations {
-		cmd := exec.Command(loc, "--version")
+		cmd := exec.CommandContext(ctx, loc, "--version")
 
 		err := cmd.Run()
 		if err != nil {
@@ -101,8 +174,8 @@ func findSvnBinary() (string, bool) {
 	return "", false
 }
 
-func hasXcodeTools() bool {
-	cmd := exec.Command("/usr/bin/xcode-select", "-p")
+func hasXcodeTools(ctx context.Context) bool {
+	cmd := exec.CommandContext(ctx, "/usr/bin/xcode-select", "-p")
 
 	return cmd.Run() == nil
 }
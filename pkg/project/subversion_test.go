@@ -1,9 +1,11 @@
 package project_test
 
 import (
+	"context"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
 	"testing"
 
 	"github.com/wakatime/wakatime-cli/pkg/project"
@@ -13,15 +15,13 @@ import (
 )
 
 func TestSubversion_Detect(t *testing.T) {
-	skipIfBinaryNotFound(t)
-
 	fp := setupTestSvn(t)
 
 	s := project.Subversion{
 		Filepath: filepath.Join(fp, "wakatime-cli", "src", "pkg", "file.go"),
 	}
 
-	result, detected, err := s.Detect()
+	result, detected, err := s.Detect(context.Background())
 	require.NoError(t, err)
 
 	assert.True(t, detected)
@@ -33,15 +33,13 @@ func TestSubversion_Detect(t *testing.T) {
 }
 
 func TestSubversion_Detect_Branch(t *testing.T) {
-	skipIfBinaryNotFound(t)
-
 	fp := setupTestSvnBranch(t)
 
 	s := project.Subversion{
 		Filepath: filepath.Join(fp, "wakatime-cli/src/pkg/file.go"),
 	}
 
-	result, detected, err := s.Detect()
+	result, detected, err := s.Detect(context.Background())
 	require.NoError(t, err)
 
 	assert.True(t, detected)
@@ -52,6 +50,74 @@ func TestSubversion_Detect_Branch(t *testing.T) {
 	}, result)
 }
 
+func TestSubversion_Detect_BinaryFallback(t *testing.T) {
+	fp := setupTestSvn(t)
+
+	// unsupported working copy format
+	err := os.WriteFile(filepath.Join(fp, "wakatime-cli/.svn/wc.db"), []byte("not a database"), 0600)
+	require.NoError(t, err)
+
+	s := project.Subversion{
+		Filepath: filepath.Join(fp, "wakatime-cli/src/pkg/file.go"),
+	}
+
+	_, found := findSvnBinary()
+	if !found {
+		_, detected, err := s.Detect(context.Background())
+		require.NoError(t, err)
+
+		assert.False(t, detected)
+
+		return
+	}
+
+	// svn fails to read the working copy, too
+	_, _, err = s.Detect(context.Background())
+	require.Error(t, err)
+}
+
+func TestSubversion_Detect_CorruptWorkingCopy(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Skipping because of the fake svn binary being a shell script.")
+	}
+
+	tmpDir := t.TempDir()
+
+	err := os.MkdirAll(filepath.Join(tmpDir, "wakatime-cli/src/pkg"), os.FileMode(int(0700)))
+	require.NoError(t, err)
+
+	err = os.WriteFile(filepath.Join(tmpDir, "wakatime-cli/src/pkg/file.go"), nil, 0600)
+	require.NoError(t, err)
+
+	// truncated database with pages of the NODES table missing
+	copyDir(t, "testdata/svn_corrupt", filepath.Join(tmpDir, "wakatime-cli/.svn"))
+
+	binDir := t.TempDir()
+
+	err = os.WriteFile(filepath.Join(binDir, "svn"), []byte("#!/bin/sh\n"+
+		"if [ \"$1\" = \"info\" ]; then\n"+
+		"  echo \"Repository Root: file:///srv/svn/wakatime-cli\"\n"+
+		"  echo \"URL: file:///srv/svn/wakatime-cli/branches/fallback\"\n"+
+		"fi\n"), 0700) // nolint:gosec
+	require.NoError(t, err)
+
+	t.Setenv("PATH", binDir)
+
+	s := project.Subversion{
+		Filepath: filepath.Join(tmpDir, "wakatime-cli/src/pkg/file.go"),
+	}
+
+	result, detected, err := s.Detect(context.Background())
+	require.NoError(t, err)
+
+	assert.True(t, detected)
+	assert.Equal(t, project.Result{
+		Project: "wakatime-cli",
+		Branch:  "fallback",
+		Folder:  "file:///srv/svn/wakatime-cli",
+	}, result)
+}
+
 func setupTestSvn(t *testing.T) (fp string) {
 	tmpDir := t.TempDir()
 
@@ -149,10 +215,3 @@ func findSvnBinary() (string, bool) {
 
 	return "", false
 }
-
-func skipIfBinaryNotFound(t *testing.T) {
-	_, found := findSvnBinary()
-	if !found {
-		t.Skip("Skipping because svn binary is not installed in this machine.")
-	}
-}
@@ -0,0 +1,3 @@
+module github.com/wakatime/monorepo/services/api/v2
+
+go 1.17
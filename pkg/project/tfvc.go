@@ -1,6 +1,7 @@
 package project
 
 import (
+	"context"
 	"path/filepath"
 	"runtime"
 
@@ -14,7 +15,7 @@ type Tfvc struct {
 }
 
 // Detect gets information about the tfvc project for a given file.
-func (t Tfvc) Detect() (Result, bool, error) {
+func (t Tfvc) Detect(_ context.Context) (Result, bool, error) {
 	log.Debugln("execute tfvc project detection")
 
 	var fp string
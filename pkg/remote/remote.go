@@ -2,6 +2,7 @@ package remote
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"io"
 	"net"
@@ -45,7 +46,7 @@ type Client struct {
 // download to a temporary directory.
 func WithDetection() heartbeat.HandleOption {
 	return func(next heartbeat.Handle) heartbeat.Handle {
-		return func(hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+		return func(ctx context.Context, hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
 			log.Debugln("execute remote file detection")
 
 			var (
@@ -62,12 +63,17 @@ func WithDetection() heartbeat.HandleOption {
 					continue
 				}
 
+				if ctx.Err() != nil {
+					log.Debugln("skip remote file detection, as context is done")
+					break
+				}
+
 				if tmpDir == "" {
 					tmpDir, err = os.MkdirTemp(os.TempDir(), "")
 					if err != nil {
 						log.Errorf("failed to create temporary directory: %s", err)
 
-						return next(hh)
+						return next(ctx, hh)
 					}
 				}
 
@@ -85,7 +91,7 @@ func WithDetection() heartbeat.HandleOption {
 					continue
 				}
 
-				err = c.DownloadFile(tmpFile.Name())
+				err = c.DownloadFile(ctx, tmpFile.Name())
 				if err != nil {
 					log.Errorf("failed to download file to temporary folder: %s", err)
 
@@ -97,7 +103,7 @@ func WithDetection() heartbeat.HandleOption {
 				hh[n].EntityRaw = h.Entity
 			}
 
-			return next(hh)
+			return next(ctx, hh)
 		}
 	}
 }
@@ -128,9 +134,10 @@ func NewClient(address string) (Client, error) {
 	}, nil
 }
 
-// DownloadFile downloads a remote file and copy to a local file.
-func (c Client) DownloadFile(localFile string) error {
-	conn, sc, err := c.Connect()
+// DownloadFile downloads a remote file and copy to a local file. The download
+// is aborted, once ctx is done.
+func (c Client) DownloadFile(ctx context.Context, localFile string) error {
+	conn, sc, err := c.Connect(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to connect to sftp host: %s", err)
 	}
@@ -138,6 +145,17 @@ func (c Client) DownloadFile(localFile string) error {
 	defer conn.Close()
 	defer sc.Close()
 
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		select {
+		case <-ctx.Done():
+			_ = conn.Close()
+		case <-done:
+		}
+	}()
+
 	srcFile, err := sc.OpenFile(c.Path, os.O_RDONLY)
 	if err != nil {
 		return fmt.Errorf("failed to open remote file: %s", err)
@@ -160,8 +178,8 @@ func (c Client) DownloadFile(localFile string) error {
 	return nil
 }
 
-// Connect connects to sftp host.
-func (c Client) Connect() (*ssh.Client, *sftp.Client, error) {
+// Connect connects to sftp host. Connecting is aborted, once ctx is done.
+func (c Client) Connect(ctx context.Context) (*ssh.Client, *sftp.Client, error) {
 	hostKeys, err := getHostKeys(c.Host)
 	if err != nil {
 		log.Errorf("failed to get host keys: %s", err)
@@ -197,7 +215,7 @@ func (c Client) Connect() (*ssh.Client, *sftp.Client, error) {
 		config.HostKeyCallback = ssh.InsecureIgnoreHostKey() // nolint:gosec
 
 		// Connect to server
-		conn, err = dial(addr, &config)
+		conn, err = dial(ctx, addr, &config)
 		if err != nil {
 			return nil, nil, fmt.Errorf("failed to connect to '%s': %s", addr, err)
 		}
@@ -208,7 +226,7 @@ func (c Client) Connect() (*ssh.Client, *sftp.Client, error) {
 			config.HostKeyCallback = ssh.FixedHostKey(hostKey)
 
 			// Connect to server
-			conn, err = dial(addr, &config)
+			conn, err = dial(ctx, addr, &config)
 			if err != nil {
 				log.Warnf("failed to connect to '%s': %s", addr, err)
 
@@ -264,11 +282,27 @@ func getHostKeys(host string) ([]ssh.PublicKey, error) {
 	return hostKeys, nil
 }
 
-func dial(addr string, config *ssh.ClientConfig) (*ssh.Client, error) {
-	conn, err := ssh.Dial("tcp", addr, config)
+func dial(ctx context.Context, addr string, config *ssh.ClientConfig) (*ssh.Client, error) {
+	d := net.Dialer{Timeout: config.Timeout}
+
+	netConn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to dial to '%s': %s", addr, err)
+	}
+
+	// limit the ssh handshake by the deadline of ctx
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = netConn.SetDeadline(deadline)
+	}
+
+	c, chans, reqs, err := ssh.NewClientConn(netConn, addr, config)
 	if err != nil {
+		_ = netConn.Close()
+
 		return nil, fmt.Errorf("failed to dial to '%s': %s", addr, err)
 	}
 
-	return conn, nil
+	_ = netConn.SetDeadline(time.Time{})
+
+	return ssh.NewClient(c, chans, reqs), nil
 }
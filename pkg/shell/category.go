@@ -0,0 +1,141 @@
+package shell
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+)
+
+// Rule maps commands to a category. Command contains the leading words of a
+// command line, like "go test".
+type Rule struct {
+	Category heartbeat.Category
+	Command  string
+}
+
+// wrappers are commands running the command passed as argument.
+// nolint:gochecknoglobals
+var wrappers = map[string]bool{
+	"builtin": true,
+	"command": true,
+	"env":     true,
+	"exec":    true,
+	"nice":    true,
+	"nohup":   true,
+	"sudo":    true,
+	"time":    true,
+}
+
+// DefaultRules returns the built-in command to category rules.
+func DefaultRules() []Rule {
+	var rules []Rule
+
+	add := func(category heartbeat.Category, commands ...string) {
+		for _, command := range commands {
+			rules = append(rules, Rule{Category: category, Command: command})
+		}
+	}
+
+	add(heartbeat.BuildingCategory,
+		"bazel build", "cargo build", "cmake", "docker build", "dotnet build", "go build", "go install",
+		"gradle", "make", "mvn", "ninja", "npm run build", "tsc", "yarn build",
+	)
+	add(heartbeat.CodeReviewingCategory,
+		"gh pr", "git diff", "git log", "git show", "tig",
+	)
+	add(heartbeat.DebuggingCategory,
+		"dlv", "gdb", "lldb", "node inspect", "pdb",
+	)
+	add(heartbeat.RunningTestsCategory,
+		"bazel test", "cargo test", "dotnet test", "go test", "gradle test", "jest", "make test", "mix test",
+		"mvn test", "npm test", "phpunit", "pytest", "rspec", "tox", "yarn test",
+	)
+
+	return rules
+}
+
+// Category infers the category of a command line from rules. Rules with more
+// words take precedence, so "make test" wins over "make". Returns false, if no
+// rule matches.
+func Category(commandLine string, rules []Rule) (heartbeat.Category, bool) {
+	words := commandWords(commandLine)
+
+	var (
+		category heartbeat.Category
+		matched  int
+	)
+
+	for _, rule := range rules {
+		ruleWords := strings.Fields(rule.Command)
+		if len(ruleWords) == 0 || len(ruleWords) <= matched || len(ruleWords) > len(words) {
+			continue
+		}
+
+		if !hasPrefix(words, ruleWords) {
+			continue
+		}
+
+		category = rule.Category
+		matched = len(ruleWords)
+	}
+
+	return category, matched > 0
+}
+
+// Program returns the name of the program run by a command line, like
+// "kubectl" for "sudo /usr/bin/kubectl get pods". Returns an empty string,
+// if the command line does not run a program.
+func Program(commandLine string) string {
+	words := commandWords(commandLine)
+	if len(words) == 0 {
+		return ""
+	}
+
+	return words[0]
+}
+
+// commandWords splits the first command of a command line into words. Leading
+// environment variable assignments and wrapper commands, like sudo, are
+// skipped. The program is reduced to its base name.
+func commandWords(commandLine string) []string {
+	for _, sep := range []string{"&&", "||", "|", ";"} {
+		if i := strings.Index(commandLine, sep); i >= 0 {
+			commandLine = commandLine[:i]
+		}
+	}
+
+	var (
+		words   = strings.Fields(commandLine)
+		wrapped bool
+	)
+
+	for len(words) > 0 {
+		word := words[0]
+
+		switch {
+		case wrappers[filepath.Base(word)]:
+			wrapped = true
+		case strings.Contains(word, "="):
+		case wrapped && strings.HasPrefix(word, "-"):
+		default:
+			words[0] = filepath.Base(word)
+
+			return words
+		}
+
+		words = words[1:]
+	}
+
+	return nil
+}
+
+func hasPrefix(words, prefix []string) bool {
+	for i, w := range prefix {
+		if !strings.EqualFold(words[i], w) {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,92 @@
+package shell_test
+
+import (
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/shell"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCategory(t *testing.T) {
+	tests := map[string]struct {
+		CommandLine string
+		Expected    heartbeat.Category
+		Matched     bool
+	}{
+		"go test": {
+			CommandLine: "go test ./...",
+			Expected:    heartbeat.RunningTestsCategory,
+			Matched:     true,
+		},
+		"go build": {
+			CommandLine: "go build -o wakatime-cli .",
+			Expected:    heartbeat.BuildingCategory,
+			Matched:     true,
+		},
+		"make": {
+			CommandLine: "make",
+			Expected:    heartbeat.BuildingCategory,
+			Matched:     true,
+		},
+		"longest rule wins": {
+			CommandLine: "make test",
+			Expected:    heartbeat.RunningTestsCategory,
+			Matched:     true,
+		},
+		"dlv": {
+			CommandLine: "dlv debug ./cmd",
+			Expected:    heartbeat.DebuggingCategory,
+			Matched:     true,
+		},
+		"env and wrapper": {
+			CommandLine: "GOFLAGS=-mod=mod sudo -E /usr/local/go/bin/go test",
+			Expected:    heartbeat.RunningTestsCategory,
+			Matched:     true,
+		},
+		"first command of pipeline": {
+			CommandLine: "git diff | less",
+			Expected:    heartbeat.CodeReviewingCategory,
+			Matched:     true,
+		},
+		"no match": {
+			CommandLine: "kubectl get pods",
+		},
+		"rule longer than command": {
+			CommandLine: "go",
+		},
+		"empty": {},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			category, ok := shell.Category(test.CommandLine, shell.DefaultRules())
+
+			assert.Equal(t, test.Matched, ok)
+			assert.Equal(t, test.Expected, category)
+		})
+	}
+}
+
+func TestProgram(t *testing.T) {
+	tests := map[string]struct {
+		CommandLine string
+		Expected    string
+	}{
+		"simple":      {CommandLine: "kubectl get pods", Expected: "kubectl"},
+		"path":        {CommandLine: "/usr/bin/kubectl get pods", Expected: "kubectl"},
+		"env":         {CommandLine: "KUBECONFIG=~/.kube/dev kubectl get pods", Expected: "kubectl"},
+		"sudo":        {CommandLine: "sudo -E systemctl restart nginx", Expected: "systemctl"},
+		"time":        {CommandLine: "time make", Expected: "make"},
+		"and":         {CommandLine: "cd src && make", Expected: "cd"},
+		"only env":    {CommandLine: "FOO=bar", Expected: ""},
+		"whitespaces": {CommandLine: "  \t ", Expected: ""},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.Expected, shell.Program(test.CommandLine))
+		})
+	}
+}
@@ -0,0 +1,70 @@
+package shell
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/wakatime/wakatime-cli/pkg/version"
+)
+
+// Shells are the shells hooks are available for.
+// nolint:gochecknoglobals
+var Shells = []string{"bash", "fish", "zsh"}
+
+const bashHook = `# WakaTime shell integration. Add to ~/.bashrc:
+#   eval "$(wakatime-cli --shell-hook bash)"
+__wakatime_last_command=""
+__wakatime_hook() {
+  local cmd
+  cmd="$(HISTTIMEFORMAT= builtin history 1 | sed -e 's/^ *[0-9]* *//')"
+  if [ -z "$cmd" ] || [ "$cmd" = "$__wakatime_last_command" ]; then
+    return
+  fi
+  __wakatime_last_command="$cmd"
+  (%[1]s --shell-command "$cmd" --project-folder "$PWD" \
+    --plugin "bash/${BASH_VERSION} bash-wakatime/%[2]s" >/dev/null 2>&1 &)
+}
+case ";${PROMPT_COMMAND};" in
+  *";__wakatime_hook;"*) ;;
+  *) PROMPT_COMMAND="__wakatime_hook${PROMPT_COMMAND:+;${PROMPT_COMMAND}}" ;;
+esac
+`
+
+const fishHook = `# WakaTime shell integration. Add to ~/.config/fish/config.fish:
+#   wakatime-cli --shell-hook fish | source
+function __wakatime_preexec --on-event fish_preexec
+    %[1]s --shell-command "$argv" --project-folder "$PWD" \
+        --plugin "fish/$FISH_VERSION fish-wakatime/%[2]s" >/dev/null 2>&1 &
+    disown 2>/dev/null
+end
+`
+
+const zshHook = `# WakaTime shell integration. Add to ~/.zshrc:
+#   eval "$(wakatime-cli --shell-hook zsh)"
+__wakatime_preexec() {
+  %[1]s --shell-command "$1" --project-folder "$PWD" \
+    --plugin "zsh/${ZSH_VERSION} zsh-wakatime/%[2]s" >/dev/null 2>&1 &!
+}
+autoload -Uz add-zsh-hook
+add-zsh-hook preexec __wakatime_preexec
+`
+
+// Hook returns the hook snippet for shell, calling the wakatime-cli
+// executable in the background for each command run.
+func Hook(shell, executable string) (string, error) {
+	switch shell {
+	case "bash":
+		return fmt.Sprintf(bashHook, quote(executable, `'\''`), version.Version), nil
+	case "fish":
+		return fmt.Sprintf(fishHook, quote(executable, `\'`), version.Version), nil
+	case "zsh":
+		return fmt.Sprintf(zshHook, quote(executable, `'\''`), version.Version), nil
+	default:
+		return "", fmt.Errorf("unsupported shell %q. must be one of %s", shell, strings.Join(Shells, ", "))
+	}
+}
+
+// quote single quotes s, replacing single quotes inside of s with escaped.
+func quote(s, escaped string) string {
+	return "'" + strings.ReplaceAll(s, "'", escaped) + "'"
+}
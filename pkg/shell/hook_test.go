@@ -0,0 +1,55 @@
+package shell_test
+
+import (
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/shell"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHook(t *testing.T) {
+	tests := map[string]struct {
+		Shell    string
+		Expected []string
+	}{
+		"bash": {
+			Shell: "bash",
+			Expected: []string{
+				`'/opt/it'\''s/wakatime-cli' --shell-command "$cmd"`,
+				"PROMPT_COMMAND=",
+			},
+		},
+		"fish": {
+			Shell: "fish",
+			Expected: []string{
+				`'/opt/it\'s/wakatime-cli' --shell-command "$argv"`,
+				"--on-event fish_preexec",
+			},
+		},
+		"zsh": {
+			Shell: "zsh",
+			Expected: []string{
+				`'/opt/it'\''s/wakatime-cli' --shell-command "$1"`,
+				"add-zsh-hook preexec __wakatime_preexec",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			hook, err := shell.Hook(test.Shell, "/opt/it's/wakatime-cli")
+			require.NoError(t, err)
+
+			for _, expected := range test.Expected {
+				assert.Contains(t, hook, expected)
+			}
+		})
+	}
+}
+
+func TestHook_UnsupportedShell(t *testing.T) {
+	_, err := shell.Hook("tcsh", "/usr/bin/wakatime-cli")
+	assert.EqualError(t, err, `unsupported shell "tcsh". must be one of bash, fish, zsh`)
+}
@@ -0,0 +1,587 @@
+package sqlite
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"regexp"
+	"strings"
+)
+
+const (
+	headerSize = 100
+	// maxDepth limits the depth of b-trees of corrupt files.
+	maxDepth = 64
+
+	pageTypeInteriorTable = 0x05
+	pageTypeLeafTable     = 0x0d
+)
+
+// nolint:gochecknoglobals
+var (
+	magic            = []byte("SQLite format 3\x00")
+	rowidAliasRegex  = regexp.MustCompile(`(?i)^\S+\s+INTEGER\s+PRIMARY\s+KEY\b`)
+	tableConstraints = map[string]bool{"CHECK": true, "CONSTRAINT": true, "FOREIGN": true, "PRIMARY": true, "UNIQUE": true}
+)
+
+// ErrTableNotFound is returned, if a table does not exist.
+var ErrTableNotFound = errors.New("table not found")
+
+// DB is a read-only sqlite database file. Only the main database file is
+// read, so changes not yet checkpointed from a write-ahead log are missing.
+type DB struct {
+	file      *os.File
+	pageSize  int
+	usable    int
+	pageCount int
+}
+
+// Row is a row of a table by column name. Values are nil, int64, float64,
+// string or []byte.
+type Row map[string]interface{}
+
+// String returns the text value of a column. Returns an empty string for
+// other types.
+func (r Row) String(column string) string {
+	switch value := r[column].(type) {
+	case string:
+		return value
+	case []byte:
+		return string(value)
+	default:
+		return ""
+	}
+}
+
+// Int returns the integer value of a column. Returns 0 for other types.
+func (r Row) Int(column string) int64 {
+	value, _ := r[column].(int64)
+	return value
+}
+
+// Open opens a sqlite database file for reading.
+func Open(fp string) (*DB, error) {
+	f, err := os.Open(fp) // nolint:gosec
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %s", err)
+	}
+
+	db, err := newDB(f)
+	if err != nil {
+		_ = f.Close()
+
+		return nil, err
+	}
+
+	return db, nil
+}
+
+func newDB(f *os.File) (*DB, error) {
+	header := make([]byte, headerSize)
+	if _, err := io.ReadFull(f, header); err != nil {
+		return nil, fmt.Errorf("failed to read header: %s", err)
+	}
+
+	if !bytes.Equal(header[:len(magic)], magic) {
+		return nil, errors.New("not a sqlite database")
+	}
+
+	pageSize := int(binary.BigEndian.Uint16(header[16:18]))
+	if pageSize == 1 {
+		pageSize = 65536
+	}
+
+	if pageSize < 512 || pageSize&(pageSize-1) != 0 {
+		return nil, fmt.Errorf("invalid page size %d", pageSize)
+	}
+
+	if encoding := binary.BigEndian.Uint32(header[56:60]); encoding > 1 {
+		return nil, fmt.Errorf("unsupported text encoding %d", encoding)
+	}
+
+	stat, err := f.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat file: %s", err)
+	}
+
+	return &DB{
+		file:      f,
+		pageSize:  pageSize,
+		usable:    pageSize - int(header[20]),
+		pageCount: int(stat.Size() / int64(pageSize)),
+	}, nil
+}
+
+// Close closes the database file.
+func (db *DB) Close() error {
+	return db.file.Close()
+}
+
+// Scan calls fn with each row of a table in rowid order, until fn returns
+// false. Tables without rowid are not supported.
+func (db *DB) Scan(table string, fn func(Row) bool) error {
+	return db.scan(table, false, fn)
+}
+
+// ScanReverse is like Scan, but in reverse rowid order. Recently inserted rows
+// are read first.
+func (db *DB) ScanReverse(table string, fn func(Row) bool) error {
+	return db.scan(table, true, fn)
+}
+
+func (db *DB) scan(table string, reverse bool, fn func(Row) bool) error {
+	var (
+		rootPage int
+		columns  []column
+		found    bool
+	)
+
+	schema := &tableWalk{
+		db:      db,
+		visited: make(map[int]bool),
+		fn: func(_ int64, values []interface{}) (bool, error) {
+			if len(values) < 5 || values[0] != "table" || !strings.EqualFold(fmt.Sprint(values[1]), table) {
+				return true, nil
+			}
+
+			page, _ := values[3].(int64)
+			sql, _ := values[4].(string)
+
+			if page == 0 {
+				return false, fmt.Errorf("table %q without rowid is not supported", table)
+			}
+
+			rootPage, columns, found = int(page), parseColumns(sql), true
+
+			return false, nil
+		},
+	}
+
+	if _, err := schema.walk(1, 0); err != nil {
+		return fmt.Errorf("failed to read schema: %s", err)
+	}
+
+	if !found {
+		return fmt.Errorf("%w: %s", ErrTableNotFound, table)
+	}
+
+	rows := &tableWalk{
+		db:      db,
+		reverse: reverse,
+		visited: make(map[int]bool),
+		fn: func(rowid int64, values []interface{}) (bool, error) {
+			row := make(Row, len(columns))
+
+			for i, c := range columns {
+				var value interface{}
+				if i < len(values) {
+					value = values[i]
+				}
+
+				if c.RowidAlias && value == nil {
+					value = rowid
+				}
+
+				row[c.Name] = value
+			}
+
+			return fn(row), nil
+		},
+	}
+
+	_, err := rows.walk(rootPage, 0)
+
+	return err
+}
+
+// tableWalk calls fn with the rowid and record values of each cell of a table
+// b-tree, until fn returns false. Pages referenced more than once, like cycles
+// of corrupt files, are an error.
+type tableWalk struct {
+	db      *DB
+	reverse bool
+	visited map[int]bool
+	fn      func(rowid int64, values []interface{}) (bool, error)
+}
+
+// walk walks the b-tree at page.
+func (w *tableWalk) walk(page int, depth int) (bool, error) {
+	if depth > maxDepth {
+		return false, errors.New("max b-tree depth exceeded")
+	}
+
+	if w.visited[page] {
+		return false, fmt.Errorf("page %d referenced more than once", page)
+	}
+
+	w.visited[page] = true
+
+	data, err := w.db.readPage(page)
+	if err != nil {
+		return false, err
+	}
+
+	offset := 0
+	if page == 1 {
+		offset = headerSize
+	}
+
+	pageType := data[offset]
+	cellCount := int(binary.BigEndian.Uint16(data[offset+3 : offset+5]))
+
+	headerLen := 8
+	if pageType == pageTypeInteriorTable {
+		headerLen = 12
+	} else if pageType != pageTypeLeafTable {
+		return false, fmt.Errorf("unexpected page type %#x at page %d", pageType, page)
+	}
+
+	if offset+headerLen+cellCount*2 > len(data) {
+		return false, fmt.Errorf("invalid cell count at page %d", page)
+	}
+
+	// the right-most child holds the highest rowids
+	if pageType == pageTypeInteriorTable && w.reverse {
+		right := int(binary.BigEndian.Uint32(data[offset+8 : offset+12]))
+
+		next, err := w.walk(right, depth+1)
+		if err != nil || !next {
+			return next, err
+		}
+	}
+
+	for j := 0; j < cellCount; j++ {
+		i := j
+		if w.reverse {
+			i = cellCount - 1 - j
+		}
+
+		ptr := offset + headerLen + i*2
+		cell := int(binary.BigEndian.Uint16(data[ptr : ptr+2]))
+
+		if cell+4 > len(data) {
+			return false, fmt.Errorf("invalid cell pointer at page %d", page)
+		}
+
+		if pageType == pageTypeInteriorTable {
+			child := int(binary.BigEndian.Uint32(data[cell : cell+4]))
+
+			next, err := w.walk(child, depth+1)
+			if err != nil || !next {
+				return next, err
+			}
+
+			continue
+		}
+
+		rowid, values, err := w.db.readCell(data, cell)
+		if err != nil {
+			return false, fmt.Errorf("failed to read cell %d at page %d: %s", i, page, err)
+		}
+
+		next, err := w.fn(rowid, values)
+		if err != nil || !next {
+			return next, err
+		}
+	}
+
+	if pageType == pageTypeInteriorTable && !w.reverse {
+		right := int(binary.BigEndian.Uint32(data[offset+8 : offset+12]))
+		return w.walk(right, depth+1)
+	}
+
+	return true, nil
+}
+
+// readCell reads the rowid and record of a table leaf cell, including its
+// overflow pages.
+func (db *DB) readCell(data []byte, cell int) (int64, []interface{}, error) {
+	payloadSize, n := varint(data[cell:])
+	if n == 0 || payloadSize > uint64(db.pageCount)*uint64(db.pageSize) {
+		return 0, nil, errors.New("invalid payload size")
+	}
+
+	cell += n
+
+	rowid, n := varint(data[cell:])
+	if n == 0 {
+		return 0, nil, errors.New("invalid rowid")
+	}
+
+	cell += n
+
+	size := int(payloadSize)
+
+	local := db.localPayloadSize(size)
+	if cell+local > len(data) {
+		return 0, nil, errors.New("invalid payload")
+	}
+
+	payload := make([]byte, 0, size)
+	payload = append(payload, data[cell:cell+local]...)
+
+	if local < size {
+		if cell+local+4 > len(data) {
+			return 0, nil, errors.New("invalid overflow page")
+		}
+
+		overflow := int(binary.BigEndian.Uint32(data[cell+local : cell+local+4]))
+
+		for i := 0; len(payload) < size; i++ {
+			if overflow == 0 || i > db.pageCount {
+				return 0, nil, errors.New("invalid overflow page")
+			}
+
+			page, err := db.readPage(overflow)
+			if err != nil {
+				return 0, nil, err
+			}
+
+			chunk := page[4:db.usable]
+			if remaining := size - len(payload); len(chunk) > remaining {
+				chunk = chunk[:remaining]
+			}
+
+			payload = append(payload, chunk...)
+			overflow = int(binary.BigEndian.Uint32(page[:4]))
+		}
+	}
+
+	values, err := parseRecord(payload)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	return int64(rowid), values, nil
+}
+
+// localPayloadSize returns the number of payload bytes stored on a table leaf
+// page, as described at https://www.sqlite.org/fileformat2.html#cellformat.
+func (db *DB) localPayloadSize(size int) int {
+	maxLocal := db.usable - 35
+	if size <= maxLocal {
+		return size
+	}
+
+	minLocal := ((db.usable-12)*32)/255 - 23
+
+	local := minLocal + (size-minLocal)%(db.usable-4)
+	if local > maxLocal {
+		return minLocal
+	}
+
+	return local
+}
+
+func (db *DB) readPage(page int) ([]byte, error) {
+	if page < 1 || page > db.pageCount {
+		return nil, fmt.Errorf("invalid page number %d", page)
+	}
+
+	data := make([]byte, db.pageSize)
+	if _, err := db.file.ReadAt(data, int64(page-1)*int64(db.pageSize)); err != nil {
+		return nil, fmt.Errorf("failed to read page %d: %s", page, err)
+	}
+
+	return data, nil
+}
+
+// parseRecord decodes the values of a record.
+func parseRecord(payload []byte) ([]interface{}, error) {
+	headerLen, n := varint(payload)
+	if n == 0 || headerLen > uint64(len(payload)) || headerLen < uint64(n) {
+		return nil, errors.New("invalid record header")
+	}
+
+	var (
+		types []uint64
+		pos   = n
+	)
+
+	for pos < int(headerLen) {
+		serialType, n := varint(payload[pos:headerLen])
+		if n == 0 {
+			return nil, errors.New("invalid record header")
+		}
+
+		types = append(types, serialType)
+		pos += n
+	}
+
+	values := make([]interface{}, 0, len(types))
+	body := payload[headerLen:]
+
+	for _, serialType := range types {
+		if serialType == 10 || serialType == 11 {
+			return nil, fmt.Errorf("invalid serial type %d", serialType)
+		}
+
+		size := serialTypeSize(serialType)
+		if size > uint64(len(body)) {
+			return nil, errors.New("invalid record body")
+		}
+
+		values = append(values, decodeValue(serialType, body[:size]))
+		body = body[size:]
+	}
+
+	return values, nil
+}
+
+// serialTypeSize returns the size of a value in the record body. Sizes are
+// not converted to int, as corrupt headers can declare any size.
+func serialTypeSize(serialType uint64) uint64 {
+	switch serialType {
+	case 0, 8, 9:
+		return 0
+	case 1, 2, 3, 4:
+		return serialType
+	case 5:
+		return 6
+	case 6, 7:
+		return 8
+	default:
+		if serialType < 12 {
+			return 0
+		}
+
+		return (serialType - 12) / 2
+	}
+}
+
+func decodeValue(serialType uint64, data []byte) interface{} {
+	switch {
+	case serialType == 0:
+		return nil
+	case serialType == 7:
+		return math.Float64frombits(binary.BigEndian.Uint64(data))
+	case serialType == 8:
+		return int64(0)
+	case serialType == 9:
+		return int64(1)
+	case serialType <= 6:
+		// big-endian two's complement integer of 1 to 8 bytes
+		var value int64
+		if len(data) > 0 && data[0]&0x80 != 0 {
+			value = -1
+		}
+
+		for _, b := range data {
+			value = value<<8 | int64(b)
+		}
+
+		return value
+	case serialType >= 12 && serialType%2 == 0:
+		return append([]byte(nil), data...)
+	case serialType >= 13:
+		return string(data)
+	default:
+		return nil
+	}
+}
+
+// varint decodes a sqlite varint, returning the value and the number of bytes
+// read. Returns 0 bytes read for truncated data.
+func varint(data []byte) (uint64, int) {
+	var value uint64
+
+	for i := 0; i < 9; i++ {
+		if i >= len(data) {
+			return 0, 0
+		}
+
+		if i == 8 {
+			return value<<8 | uint64(data[i]), 9
+		}
+
+		value = value<<7 | uint64(data[i]&0x7f)
+
+		if data[i]&0x80 == 0 {
+			return value, i + 1
+		}
+	}
+
+	return value, 9
+}
+
+type column struct {
+	Name       string
+	RowidAlias bool
+}
+
+// parseColumns returns the columns of a CREATE TABLE statement.
+func parseColumns(sql string) []column {
+	start, end := strings.Index(sql, "("), strings.LastIndex(sql, ")")
+	if start < 0 || end <= start {
+		return nil
+	}
+
+	var columns []column
+
+	for _, def := range splitDefinitions(sql[start+1 : end]) {
+		fields := strings.Fields(def)
+		if len(fields) == 0 {
+			continue
+		}
+
+		// constraints like "UNIQUE(a, b)" have no space before the parenthesis
+		if keyword := strings.SplitN(fields[0], "(", 2)[0]; tableConstraints[strings.ToUpper(keyword)] {
+			continue
+		}
+
+		columns = append(columns, column{
+			Name:       unquoteIdentifier(fields[0]),
+			RowidAlias: rowidAliasRegex.MatchString(def),
+		})
+	}
+
+	return columns
+}
+
+// splitDefinitions splits column definitions at commas outside of parentheses
+// and quotes.
+func splitDefinitions(s string) []string {
+	var (
+		defs  []string
+		depth int
+		quote rune
+		start int
+	)
+
+	for i, r := range s {
+		switch {
+		case quote != 0:
+			if r == quote || (quote == '[' && r == ']') {
+				quote = 0
+			}
+		case r == '"' || r == '\'' || r == '`' || r == '[':
+			quote = r
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case r == ',' && depth == 0:
+			defs = append(defs, strings.TrimSpace(s[start:i]))
+			start = i + 1
+		}
+	}
+
+	return append(defs, strings.TrimSpace(s[start:]))
+}
+
+func unquoteIdentifier(s string) string {
+	if len(s) >= 2 {
+		switch s[0] {
+		case '"', '`', '\'':
+			return strings.Trim(s, s[:1])
+		case '[':
+			return strings.TrimSuffix(strings.TrimPrefix(s, "["), "]")
+		}
+	}
+
+	return s
+}
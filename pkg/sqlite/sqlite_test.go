@@ -0,0 +1,227 @@
+package sqlite_test
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/sqlite"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDB_Scan(t *testing.T) {
+	db, err := sqlite.Open("testdata/test.db")
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	var rows []sqlite.Row
+
+	err = db.Scan("files", func(row sqlite.Row) bool {
+		rows = append(rows, row)
+		return true
+	})
+	require.NoError(t, err)
+
+	require.Len(t, rows, 200)
+
+	assert.Equal(t, sqlite.Row{
+		"id":    int64(1),
+		"name":  "file001.go",
+		"size":  int64(1000),
+		"ratio": 0.25,
+		"data":  []byte{1, 1, 1},
+		"note":  nil,
+	}, rows[0])
+
+	assert.Equal(t, int64(-70000000000), rows[2].Int("size"))
+	assert.Equal(t, "even", rows[1].String("note"))
+
+	// stored in overflow pages
+	assert.Equal(t, strings.Repeat("x", 5000), rows[99].String("note"))
+
+	for i, row := range rows {
+		assert.Equal(t, int64(i+1), row.Int("id"))
+	}
+}
+
+func TestDB_Scan_Stop(t *testing.T) {
+	db, err := sqlite.Open("testdata/test.db")
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	var names []string
+
+	err = db.Scan("files", func(row sqlite.Row) bool {
+		names = append(names, row.String("name"))
+		return len(names) < 3
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"file001.go", "file002.go", "file003.go"}, names)
+}
+
+func TestDB_ScanReverse(t *testing.T) {
+	db, err := sqlite.Open("testdata/test.db")
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	var ids []int64
+
+	err = db.ScanReverse("files", func(row sqlite.Row) bool {
+		ids = append(ids, row.Int("id"))
+		return true
+	})
+	require.NoError(t, err)
+
+	require.Len(t, ids, 200)
+
+	for i, id := range ids {
+		assert.Equal(t, int64(200-i), id)
+	}
+}
+
+func TestDB_Scan_AddedColumn(t *testing.T) {
+	db, err := sqlite.Open("testdata/test.db")
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	var rows []sqlite.Row
+
+	err = db.Scan("nopk", func(row sqlite.Row) bool {
+		rows = append(rows, row)
+		return true
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []sqlite.Row{
+		{"name": "a", "value": int64(0), "extra": nil},
+		{"name": "b", "value": int64(1), "extra": nil},
+		{"name": "c", "value": int64(123456), "extra": nil},
+	}, rows)
+}
+
+func TestDB_Scan_Empty(t *testing.T) {
+	db, err := sqlite.Open("testdata/test.db")
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	err = db.Scan("empty", func(row sqlite.Row) bool {
+		assert.Fail(t, "unexpected row")
+		return true
+	})
+	require.NoError(t, err)
+}
+
+func TestDB_Scan_TableNotFound(t *testing.T) {
+	db, err := sqlite.Open("testdata/test.db")
+	require.NoError(t, err)
+
+	defer db.Close()
+
+	err = db.Scan("missing", func(row sqlite.Row) bool {
+		return true
+	})
+	require.Error(t, err)
+
+	assert.True(t, errors.Is(err, sqlite.ErrTableNotFound))
+}
+
+func TestOpen_NotSqlite(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "test.db")
+
+	err := os.WriteFile(fp, []byte(strings.Repeat("not a database\n", 10)), 0600)
+	require.NoError(t, err)
+
+	_, err = sqlite.Open(fp)
+	require.Error(t, err)
+
+	assert.Equal(t, "not a sqlite database", err.Error())
+}
+
+func TestDB_Scan_Corrupt(t *testing.T) {
+	tests := map[string]struct {
+		PageType byte
+		Cell     []byte
+		Right    uint32
+		Error    string
+	}{
+		"record header length overflow": {
+			PageType: 0x0d,
+			Cell:     []byte{0x0c, 0x01, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x00, 0x00, 0x00},
+			Error:    "invalid record header",
+		},
+		"oversized serial type": {
+			PageType: 0x0d,
+			Cell:     []byte{0x0b, 0x01, 0x0a, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x00},
+			Error:    "invalid record body",
+		},
+		"reserved serial type": {
+			PageType: 0x0d,
+			Cell:     []byte{0x03, 0x01, 0x02, 0x0a, 0x00},
+			Error:    "invalid serial type 10",
+		},
+		"cyclic b-tree": {
+			PageType: 0x05,
+			Cell:     []byte{0x00, 0x00, 0x00, 0x01, 0x01},
+			Right:    1,
+			Error:    "page 1 referenced more than once",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, err := sqlite.Open(writeDB(t, test.PageType, test.Cell, test.Right))
+			require.NoError(t, err)
+
+			defer db.Close()
+
+			err = db.Scan("files", func(sqlite.Row) bool {
+				return true
+			})
+			require.Error(t, err)
+
+			assert.Contains(t, err.Error(), test.Error)
+		})
+	}
+}
+
+// writeDB writes a database file of a single 512 byte b-tree page with one
+// cell.
+func writeDB(t *testing.T, pageType byte, cell []byte, right uint32) string {
+	data := make([]byte, 512)
+	copy(data, "SQLite format 3\x00")
+	binary.BigEndian.PutUint16(data[16:18], 512)
+	binary.BigEndian.PutUint32(data[56:60], 1)
+
+	cellOffset := len(data) - len(cell)
+	copy(data[cellOffset:], cell)
+
+	header := data[100:]
+	header[0] = pageType
+	binary.BigEndian.PutUint16(header[3:5], 1)
+
+	pointer := 8
+	if pageType == 0x05 {
+		binary.BigEndian.PutUint32(header[8:12], right)
+		pointer = 12
+	}
+
+	binary.BigEndian.PutUint16(header[pointer:pointer+2], uint16(cellOffset))
+
+	fp := filepath.Join(t.TempDir(), "corrupt.db")
+
+	err := os.WriteFile(fp, data, 0600)
+	require.NoError(t, err)
+
+	return fp
+}
@@ -0,0 +1,173 @@
+package trace
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/version"
+)
+
+// spanKindInternal is the OTLP span kind for internal operations.
+const spanKindInternal = 1
+
+type (
+	otlpTraces struct {
+		ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+	}
+
+	otlpResourceSpans struct {
+		Resource   otlpResource     `json:"resource"`
+		ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+	}
+
+	otlpResource struct {
+		Attributes []otlpAttribute `json:"attributes"`
+	}
+
+	otlpScopeSpans struct {
+		Scope otlpScope  `json:"scope"`
+		Spans []otlpSpan `json:"spans"`
+	}
+
+	otlpScope struct {
+		Name    string `json:"name"`
+		Version string `json:"version"`
+	}
+
+	otlpSpan struct {
+		TraceID           string          `json:"traceId"`
+		SpanID            string          `json:"spanId"`
+		ParentSpanID      string          `json:"parentSpanId,omitempty"`
+		Name              string          `json:"name"`
+		Kind              int             `json:"kind"`
+		StartTimeUnixNano string          `json:"startTimeUnixNano"`
+		EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+		Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	}
+
+	otlpAttribute struct {
+		Key   string    `json:"key"`
+		Value otlpValue `json:"value"`
+	}
+
+	otlpValue struct {
+		StringValue string `json:"stringValue"`
+	}
+)
+
+// MarshalOTLP encodes all finished spans in OTLP/JSON format.
+func (t *Tracer) MarshalOTLP() ([]byte, error) {
+	var spans []otlpSpan
+
+	for _, s := range t.Spans() {
+		spans = append(spans, otlpSpan{
+			TraceID:           s.TraceID,
+			SpanID:            s.SpanID,
+			ParentSpanID:      s.ParentSpanID,
+			Name:              s.Name,
+			Kind:              spanKindInternal,
+			StartTimeUnixNano: strconv.FormatInt(s.Start.UnixNano(), 10),
+			EndTimeUnixNano:   strconv.FormatInt(s.End.UnixNano(), 10),
+			Attributes:        otlpAttributes(s.Attributes),
+		})
+	}
+
+	data, err := json.Marshal(otlpTraces{
+		ResourceSpans: []otlpResourceSpans{
+			{
+				Resource: otlpResource{
+					Attributes: otlpAttributes(map[string]string{
+						"service.name":    "wakatime-cli",
+						"service.version": version.Version,
+					}),
+				},
+				ScopeSpans: []otlpScopeSpans{
+					{
+						Scope: otlpScope{
+							Name:    "wakatime-cli",
+							Version: version.Version,
+						},
+						Spans: spans,
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to json marshal spans: %s", err)
+	}
+
+	return data, nil
+}
+
+// ExportFile appends all finished spans as a single OTLP/JSON line to the
+// file at filepath.
+func (t *Tracer) ExportFile(filepath string) error {
+	data, err := t.MarshalOTLP()
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(filepath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to open trace file: %s", err)
+	}
+
+	defer f.Close()
+
+	if _, err := f.Write(append(data, '\n')); err != nil {
+		return fmt.Errorf("failed to write trace file: %s", err)
+	}
+
+	return nil
+}
+
+// ExportHTTP sends all finished spans in OTLP/JSON format to a collector
+// endpoint, like http://localhost:4318/v1/traces.
+func (t *Tracer) ExportHTTP(endpoint string, timeout time.Duration) error {
+	data, err := t.MarshalOTLP()
+	if err != nil {
+		return err
+	}
+
+	client := &http.Client{Timeout: timeout}
+
+	resp, err := client.Post(endpoint, "application/json", bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed making request to %q: %s", endpoint, err)
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("invalid response status from %q: %d", endpoint, resp.StatusCode)
+	}
+
+	return nil
+}
+
+func otlpAttributes(attributes map[string]string) []otlpAttribute {
+	keys := make([]string, 0, len(attributes))
+	for k := range attributes {
+		keys = append(keys, k)
+	}
+
+	sort.Strings(keys)
+
+	var result []otlpAttribute
+
+	for _, k := range keys {
+		result = append(result, otlpAttribute{
+			Key:   k,
+			Value: otlpValue{StringValue: attributes[k]},
+		})
+	}
+
+	return result
+}
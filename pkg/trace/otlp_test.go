@@ -0,0 +1,137 @@
+package trace_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/trace"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracer_MarshalOTLP(t *testing.T) {
+	tracer := trace.NewTracer()
+
+	endParent := tracer.Start("parent", nil)
+	endChild := tracer.Start("child", map[string]string{"b": "2", "a": "1"})
+	endChild()
+	endParent()
+
+	data, err := tracer.MarshalOTLP()
+	require.NoError(t, err)
+
+	var traces struct {
+		ResourceSpans []struct {
+			ScopeSpans []struct {
+				Spans []struct {
+					TraceID      string `json:"traceId"`
+					SpanID       string `json:"spanId"`
+					ParentSpanID string `json:"parentSpanId"`
+					Name         string `json:"name"`
+					Kind         int    `json:"kind"`
+					Attributes   []struct {
+						Key   string `json:"key"`
+						Value struct {
+							StringValue string `json:"stringValue"`
+						} `json:"value"`
+					} `json:"attributes"`
+				} `json:"spans"`
+			} `json:"scopeSpans"`
+		} `json:"resourceSpans"`
+	}
+
+	err = json.Unmarshal(data, &traces)
+	require.NoError(t, err)
+
+	require.Len(t, traces.ResourceSpans, 1)
+	require.Len(t, traces.ResourceSpans[0].ScopeSpans, 1)
+
+	spans := traces.ResourceSpans[0].ScopeSpans[0].Spans
+	require.Len(t, spans, 2)
+
+	assert.Equal(t, "parent", spans[0].Name)
+	assert.Equal(t, 1, spans[0].Kind)
+	assert.Empty(t, spans[0].ParentSpanID)
+
+	assert.Equal(t, "child", spans[1].Name)
+	assert.Equal(t, spans[0].SpanID, spans[1].ParentSpanID)
+	assert.Equal(t, spans[0].TraceID, spans[1].TraceID)
+	require.Len(t, spans[1].Attributes, 2)
+	assert.Equal(t, "a", spans[1].Attributes[0].Key)
+	assert.Equal(t, "1", spans[1].Attributes[0].Value.StringValue)
+	assert.Equal(t, "b", spans[1].Attributes[1].Key)
+}
+
+func TestTracer_ExportFile(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "trace.jsonl")
+
+	for i := 0; i < 2; i++ {
+		tracer := trace.NewTracer()
+
+		end := tracer.Start("span", nil)
+		end()
+
+		err := tracer.ExportFile(fp)
+		require.NoError(t, err)
+	}
+
+	data, err := os.ReadFile(fp)
+	require.NoError(t, err)
+
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	require.Len(t, lines, 2)
+
+	for _, line := range lines {
+		assert.True(t, json.Valid([]byte(line)))
+	}
+}
+
+func TestTracer_ExportHTTP(t *testing.T) {
+	var numCalls int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodPost, req.Method)
+		assert.Equal(t, "/v1/traces", req.URL.Path)
+		assert.Equal(t, []string{"application/json"}, req.Header["Content-Type"])
+
+		body, err := io.ReadAll(req.Body)
+		require.NoError(t, err)
+
+		assert.Contains(t, string(body), `"name":"span"`)
+
+		numCalls++
+	}))
+	defer server.Close()
+
+	tracer := trace.NewTracer()
+
+	end := tracer.Start("span", nil)
+	end()
+
+	err := tracer.ExportHTTP(server.URL+"/v1/traces", time.Second)
+	require.NoError(t, err)
+
+	assert.Equal(t, 1, numCalls)
+}
+
+func TestTracer_ExportHTTP_InvalidStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer server.Close()
+
+	tracer := trace.NewTracer()
+
+	err := tracer.ExportHTTP(server.URL, time.Second)
+	require.Error(t, err)
+
+	assert.Contains(t, err.Error(), "invalid response status")
+}
@@ -0,0 +1,173 @@
+package trace
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/log"
+)
+
+// Span represents a single timed operation of a trace.
+type Span struct {
+	Attributes   map[string]string
+	End          time.Time
+	Name         string
+	ParentSpanID string
+	SpanID       string
+	Start        time.Time
+	TraceID      string
+}
+
+// Duration returns the duration of the span.
+func (s Span) Duration() time.Duration {
+	return s.End.Sub(s.Start)
+}
+
+// Tracer records spans of a single wakatime-cli invocation. Started spans
+// become children of the currently active span.
+type Tracer struct {
+	mu      sync.Mutex
+	now     func() time.Time
+	spans   []*Span
+	active  []*Span
+	traceID string
+}
+
+// NewTracer creates a new Tracer with a random trace id.
+func NewTracer() *Tracer {
+	return &Tracer{
+		now:     time.Now,
+		traceID: randomID(16),
+	}
+}
+
+// Start starts a new span as child of the currently active span. The
+// returned function ends the span.
+func (t *Tracer) Start(name string, attributes map[string]string) func() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	span := &Span{
+		Attributes: attributes,
+		Name:       name,
+		SpanID:     randomID(8),
+		Start:      t.now(),
+		TraceID:    t.traceID,
+	}
+
+	if len(t.active) > 0 {
+		span.ParentSpanID = t.active[len(t.active)-1].SpanID
+	}
+
+	t.spans = append(t.spans, span)
+	t.active = append(t.active, span)
+
+	return func() {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+
+		span.End = t.now()
+
+		for i := len(t.active) - 1; i >= 0; i-- {
+			if t.active[i] == span {
+				t.active = append(t.active[:i], t.active[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// Spans returns all finished spans in order of their start.
+func (t *Tracer) Spans() []Span {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var spans []Span
+
+	for _, s := range t.spans {
+		if s.End.IsZero() {
+			continue
+		}
+
+		spans = append(spans, *s)
+	}
+
+	return spans
+}
+
+// Summary renders a table of all finished spans with their total duration
+// and self duration, which excludes the duration of child spans.
+func (t *Tracer) Summary() string {
+	spans := t.Spans()
+
+	children := make(map[string]time.Duration)
+	depths := make(map[string]int)
+
+	for _, s := range spans {
+		if s.ParentSpanID != "" {
+			children[s.ParentSpanID] += s.Duration()
+			depths[s.SpanID] = depths[s.ParentSpanID] + 1
+		}
+	}
+
+	var builder strings.Builder
+
+	w := tabwriter.NewWriter(&builder, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(w, "span\ttotal\tself")
+
+	for _, s := range spans {
+		fmt.Fprintf(
+			w,
+			"%s%s\t%s\t%s\n",
+			strings.Repeat("  ", depths[s.SpanID]),
+			s.Name,
+			s.Duration().Round(time.Microsecond),
+			(s.Duration() - children[s.SpanID]).Round(time.Microsecond),
+		)
+	}
+
+	_ = w.Flush()
+
+	return strings.TrimSuffix(builder.String(), "\n")
+}
+
+// WithSpan wraps a heartbeat handle option in a span with the passed in name.
+// As handle options call the next option, the span includes the duration of
+// all following options. Will return the handle option unchanged, if tracer
+// is nil.
+func WithSpan(t *Tracer, name string, opt heartbeat.HandleOption) heartbeat.HandleOption {
+	if t == nil {
+		return opt
+	}
+
+	return func(next heartbeat.Handle) heartbeat.Handle {
+		handle := opt(next)
+
+		return func(ctx context.Context, hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+			end := t.Start(name, map[string]string{
+				"heartbeats": fmt.Sprint(len(hh)),
+			})
+			defer end()
+
+			return handle(ctx, hh)
+		}
+	}
+}
+
+func randomID(n int) string {
+	b := make([]byte, n)
+
+	if _, err := rand.Read(b); err != nil {
+		log.Debugf("failed to generate random span id: %s", err)
+	}
+
+	return hex.EncodeToString(b)
+}
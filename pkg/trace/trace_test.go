@@ -0,0 +1,119 @@
+package trace_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/wakatime/wakatime-cli/pkg/heartbeat"
+	"github.com/wakatime/wakatime-cli/pkg/trace"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTracer_Start(t *testing.T) {
+	tracer := trace.NewTracer()
+
+	endParent := tracer.Start("parent", nil)
+	endChild := tracer.Start("child", map[string]string{"key": "value"})
+	endChild()
+	endSibling := tracer.Start("sibling", nil)
+	endSibling()
+	endParent()
+
+	spans := tracer.Spans()
+	require.Len(t, spans, 3)
+
+	assert.Equal(t, "parent", spans[0].Name)
+	assert.Empty(t, spans[0].ParentSpanID)
+	assert.Len(t, spans[0].TraceID, 32)
+	assert.Len(t, spans[0].SpanID, 16)
+
+	assert.Equal(t, "child", spans[1].Name)
+	assert.Equal(t, spans[0].SpanID, spans[1].ParentSpanID)
+	assert.Equal(t, map[string]string{"key": "value"}, spans[1].Attributes)
+
+	assert.Equal(t, "sibling", spans[2].Name)
+	assert.Equal(t, spans[0].SpanID, spans[2].ParentSpanID)
+
+	for _, s := range spans {
+		assert.Equal(t, spans[0].TraceID, s.TraceID)
+		assert.False(t, s.End.Before(s.Start))
+	}
+}
+
+func TestTracer_Spans_Unfinished(t *testing.T) {
+	tracer := trace.NewTracer()
+
+	_ = tracer.Start("unfinished", nil)
+
+	end := tracer.Start("finished", nil)
+	end()
+
+	spans := tracer.Spans()
+	require.Len(t, spans, 1)
+
+	assert.Equal(t, "finished", spans[0].Name)
+}
+
+func TestTracer_Summary(t *testing.T) {
+	tracer := trace.NewTracer()
+
+	endParent := tracer.Start("parent", nil)
+	endChild := tracer.Start("child", nil)
+	endChild()
+	endParent()
+
+	lines := strings.Split(tracer.Summary(), "\n")
+	require.Len(t, lines, 3)
+
+	assert.Regexp(t, `^span\s+total\s+self$`, lines[0])
+	assert.Regexp(t, `^parent\s+\S+\s+\S+$`, lines[1])
+	assert.Regexp(t, `^  child\s+\S+\s+\S+$`, lines[2])
+}
+
+func TestWithSpan(t *testing.T) {
+	tracer := trace.NewTracer()
+
+	opt := func(next heartbeat.Handle) heartbeat.Handle {
+		return func(_ context.Context, hh []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+			return next(context.Background(), hh)
+		}
+	}
+
+	handle := heartbeat.NewHandle(&mockSender{}, trace.WithSpan(tracer, "stage", opt))
+
+	_, err := handle(context.Background(), []heartbeat.Heartbeat{{}, {}})
+	require.Error(t, err)
+
+	spans := tracer.Spans()
+	require.Len(t, spans, 1)
+
+	assert.Equal(t, "stage", spans[0].Name)
+	assert.Equal(t, map[string]string{"heartbeats": "2"}, spans[0].Attributes)
+}
+
+func TestWithSpan_NilTracer(t *testing.T) {
+	var called bool
+
+	opt := func(next heartbeat.Handle) heartbeat.Handle {
+		called = true
+
+		return next
+	}
+
+	handle := heartbeat.NewHandle(&mockSender{}, trace.WithSpan(nil, "stage", opt))
+
+	_, err := handle(context.Background(), []heartbeat.Heartbeat{{}})
+	require.Error(t, err)
+
+	assert.True(t, called)
+}
+
+type mockSender struct{}
+
+func (*mockSender) SendHeartbeats(_ context.Context, _ []heartbeat.Heartbeat) ([]heartbeat.Result, error) {
+	return nil, errors.New("failed")
+}
@@ -1,11 +1,17 @@
 package vipertools
 
 import (
+	"fmt"
 	"strings"
 
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
 
+// NewFlagsFunc creates a new viper.Viper instance with all command line flags
+// bound to the returned, unparsed flag set.
+type NewFlagsFunc func() (*viper.Viper, *pflag.FlagSet)
+
 // FirstNonEmptyBool accepts multiple keys and returns the first non empty bool value
 // from viper.Viper via these keys. Non-empty meaning false value will not be accepted.
 func FirstNonEmptyBool(v *viper.Viper, keys ...string) bool {
@@ -60,3 +66,82 @@ func FirstNonEmptyString(v *viper.Viper, keys ...string) (string, bool) {
 func GetString(v *viper.Viper, key string) string {
 	return strings.Trim(v.GetString(key), `"'`)
 }
+
+// GetStringMapString gets the keys and values of a config section, stripping
+// any quotes from the values. Viper does not nest keys of sections read from
+// ini files, so these are collected by the section prefix.
+func GetStringMapString(v *viper.Viper, section string) map[string]string {
+	prefix := section + "."
+	values := make(map[string]string)
+
+	for _, key := range v.AllKeys() {
+		if !strings.HasPrefix(key, prefix) {
+			continue
+		}
+
+		values[strings.TrimPrefix(key, prefix)] = GetString(v, key)
+	}
+
+	return values
+}
+
+// Copy returns a copy of v, which can be changed without affecting v. Set
+// values are copied as such. Defaults of unchanged command line flags are
+// copied as flag defaults, so IsSet reports the same as for v.
+func Copy(v *viper.Viper) *viper.Viper {
+	c := viper.New()
+
+	for _, key := range v.AllKeys() {
+		value := v.Get(key)
+
+		switch {
+		case v.IsSet(key):
+			c.Set(key, value)
+		case value != nil:
+			_ = c.BindFlagValue(key, flagDefault{name: key, value: value})
+		}
+	}
+
+	return c
+}
+
+// flagDefault is an unchanged command line flag with a default value.
+type flagDefault struct {
+	name  string
+	value interface{}
+}
+
+func (flagDefault) HasChanged() bool { return false }
+
+func (f flagDefault) Name() string { return f.name }
+
+func (f flagDefault) ValueString() string {
+	switch value := f.value.(type) {
+	case []string:
+		return "[" + strings.Join(value, ",") + "]"
+	case map[string]string:
+		pairs := make([]string, 0, len(value))
+		for k, v := range value {
+			pairs = append(pairs, k+"="+v)
+		}
+
+		return "[" + strings.Join(pairs, ",") + "]"
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+func (f flagDefault) ValueType() string {
+	switch f.value.(type) {
+	case bool:
+		return "bool"
+	case int:
+		return "int"
+	case []string:
+		return "stringSlice"
+	case map[string]string:
+		return "stringToString"
+	default:
+		return "string"
+	}
+}
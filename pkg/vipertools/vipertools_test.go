@@ -1,10 +1,12 @@
 package vipertools_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/wakatime/wakatime-cli/pkg/vipertools"
 
+	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -117,3 +119,53 @@ func TestGetString_DoubleQuotes(t *testing.T) {
 	value := vipertools.GetString(v, "some")
 	assert.Equal(t, "value", value)
 }
+
+func TestGetStringMapString(t *testing.T) {
+	v := viper.New()
+	v.SetConfigType("ini")
+
+	err := v.ReadConfig(strings.NewReader("[settings]\ndebug = true\n[shell_categories]\nmake = \"building\"\n" +
+		"go test = running tests\n"))
+	require.NoError(t, err)
+
+	v.Set("shell_categories.terraform", "building")
+
+	values := vipertools.GetStringMapString(v, "shell_categories")
+	assert.Equal(t, map[string]string{
+		"go test":   "running tests",
+		"make":      "building",
+		"terraform": "building",
+	}, values)
+}
+
+func TestCopy(t *testing.T) {
+	flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+	flags.String("entity", "", "")
+	flags.Int("timeout", 120, "")
+	flags.Bool("write", false, "")
+	flags.StringSlice("watch-dir", []string{"/tmp"}, "")
+
+	v := viper.New()
+	err := v.BindPFlags(flags)
+	require.NoError(t, err)
+
+	err = flags.Parse([]string{"--entity", "/tmp/main.go"})
+	require.NoError(t, err)
+
+	v.Set("settings.exclude", "^/tmp/")
+
+	c := vipertools.Copy(v)
+
+	for _, key := range []string{"entity", "settings.exclude", "timeout", "watch-dir", "write"} {
+		assert.Equal(t, v.IsSet(key), c.IsSet(key), key)
+		assert.Equal(t, v.Get(key), c.Get(key), key)
+	}
+
+	assert.False(t, c.IsSet("timeout"))
+	assert.Equal(t, 120, c.GetInt("timeout"))
+	assert.Equal(t, []string{"/tmp"}, c.GetStringSlice("watch-dir"))
+
+	c.Set("settings.exclude", "^/var/")
+
+	assert.Equal(t, "^/tmp/", v.GetString("settings.exclude"))
+}
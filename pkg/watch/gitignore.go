@@ -0,0 +1,128 @@
+package watch
+
+import (
+	"bufio"
+	"io"
+	"path"
+	"strings"
+)
+
+// gitignoreFile is the name of files containing ignore patterns.
+const gitignoreFile = ".gitignore"
+
+// ignoreRule is a single pattern of a .gitignore file.
+type ignoreRule struct {
+	anchored bool
+	dirOnly  bool
+	negate   bool
+	pattern  string
+}
+
+// gitignore contains the rules of a single .gitignore file. Supports the
+// subset of gitignore syntax relevant for matching single paths: comments,
+// negation, directory only and anchored patterns, as well as "*", "?",
+// character classes and "**".
+type gitignore struct {
+	rules []ignoreRule
+}
+
+// parseGitignore parses the contents of a .gitignore file.
+func parseGitignore(r io.Reader) (gitignore, error) {
+	var rules []ignoreRule
+
+	scanner := bufio.NewScanner(r)
+
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), " \t\r")
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		var rule ignoreRule
+
+		if strings.HasPrefix(line, "!") {
+			rule.negate = true
+			line = line[1:]
+		}
+
+		// escaped leading "!" or "#"
+		if strings.HasPrefix(line, `\`) {
+			line = line[1:]
+		}
+
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+
+		if strings.Contains(line, "/") {
+			rule.anchored = true
+			line = strings.TrimLeft(line, "/")
+		}
+
+		if line == "" {
+			continue
+		}
+
+		rule.pattern = line
+		rules = append(rules, rule)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return gitignore{}, err
+	}
+
+	return gitignore{rules: rules}, nil
+}
+
+// match matches a slash separated path relative to the directory of the
+// .gitignore file. Returns whether any rule matched and if so, whether the
+// path is ignored. The last matching rule wins.
+func (g gitignore) match(rel string, isDir bool) (matched bool, ignored bool) {
+	for _, rule := range g.rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+
+		target := rel
+		if !rule.anchored {
+			target = path.Base(rel)
+		}
+
+		if matchGlob(strings.Split(rule.pattern, "/"), strings.Split(target, "/")) {
+			matched = true
+			ignored = !rule.negate
+		}
+	}
+
+	return matched, ignored
+}
+
+// matchGlob matches path segments against pattern segments. A "**" segment
+// matches zero or more path segments.
+func matchGlob(pattern, segments []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := 0; i <= len(segments); i++ {
+				if matchGlob(pattern[1:], segments[i:]) {
+					return true
+				}
+			}
+
+			return false
+		}
+
+		if len(segments) == 0 {
+			return false
+		}
+
+		ok, err := path.Match(pattern[0], segments[0])
+		if err != nil || !ok {
+			return false
+		}
+
+		pattern, segments = pattern[1:], segments[1:]
+	}
+
+	return len(segments) == 0
+}
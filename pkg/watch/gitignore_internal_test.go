@@ -0,0 +1,87 @@
+package watch
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitignore_Match(t *testing.T) {
+	g, err := parseGitignore(strings.NewReader(strings.Join([]string{
+		"# comment",
+		"*.log",
+		"!important.log",
+		"build/",
+		"/vendor",
+		"docs/**/*.pdf",
+		`\#hash`,
+		"",
+	}, "\n")))
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		Path     string
+		IsDir    bool
+		Matched  bool
+		Expected bool
+	}{
+		"unanchored file": {
+			Path:     "sub/debug.log",
+			Matched:  true,
+			Expected: true,
+		},
+		"negated": {
+			Path:     "important.log",
+			Matched:  true,
+			Expected: false,
+		},
+		"directory only": {
+			Path:     "sub/build",
+			IsDir:    true,
+			Matched:  true,
+			Expected: true,
+		},
+		"directory only file": {
+			Path: "build",
+		},
+		"anchored": {
+			Path:     "vendor",
+			IsDir:    true,
+			Matched:  true,
+			Expected: true,
+		},
+		"anchored nested": {
+			Path:  "sub/vendor",
+			IsDir: true,
+		},
+		"double star": {
+			Path:     "docs/a/b/manual.pdf",
+			Matched:  true,
+			Expected: true,
+		},
+		"double star zero segments": {
+			Path:     "docs/manual.pdf",
+			Matched:  true,
+			Expected: true,
+		},
+		"escaped": {
+			Path:     "#hash",
+			Matched:  true,
+			Expected: true,
+		},
+		"no match": {
+			Path: "main.go",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			matched, ignored := g.match(test.Path, test.IsDir)
+
+			assert.Equal(t, test.Matched, matched)
+			assert.Equal(t, test.Expected, ignored)
+		})
+	}
+}
@@ -0,0 +1,367 @@
+package watch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/log"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+const (
+	// DefaultDebounce is the default quiet period after the last file event,
+	// before modified files are handled.
+	DefaultDebounce = 2 * time.Second
+	// maxWaitFactor limits the delay of modifications, while events keep
+	// coming in, to a multiple of the debounce period.
+	maxWaitFactor = 10
+)
+
+// vcsDirs are directories of version control systems, which are never watched.
+// nolint:gochecknoglobals
+var vcsDirs = map[string]bool{
+	".bzr": true,
+	".git": true,
+	".hg":  true,
+	".jj":  true,
+	".svn": true,
+}
+
+// Config contains watcher configurations.
+type Config struct {
+	// Debounce is the quiet period after the last file event, before modified
+	// files are handled. Defaults to DefaultDebounce.
+	Debounce time.Duration
+	// Dirs are the directories watched recursively.
+	Dirs []string
+}
+
+// Event is a modification of a watched file.
+type Event struct {
+	File string
+	Time time.Time
+}
+
+// Handle processes the files modified within a burst of file events.
+type Handle func(events []Event)
+
+// Watcher recursively watches directories for file modifications. Files and
+// directories ignored by .gitignore files inside the watched directories are
+// skipped.
+type Watcher struct {
+	debounce time.Duration
+	fs       *fsnotify.Watcher
+	ignores  map[string]gitignore
+	roots    []string
+}
+
+// NewWatcher creates a new Watcher and starts watching the configured directories.
+func NewWatcher(config Config) (*Watcher, error) {
+	if len(config.Dirs) == 0 {
+		return nil, errors.New("no directories to watch")
+	}
+
+	debounce := config.Debounce
+	if debounce <= 0 {
+		debounce = DefaultDebounce
+	}
+
+	var roots []string
+
+	for _, dir := range config.Dirs {
+		abs, err := filepath.Abs(dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve directory %q: %s", dir, err)
+		}
+
+		info, err := os.Stat(abs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to access directory %q: %s", dir, err)
+		}
+
+		if !info.IsDir() {
+			return nil, fmt.Errorf("%q is not a directory", dir)
+		}
+
+		roots = append(roots, abs)
+	}
+
+	fsw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file watcher: %s", err)
+	}
+
+	w := &Watcher{
+		debounce: debounce,
+		fs:       fsw,
+		ignores:  make(map[string]gitignore),
+		roots:    roots,
+	}
+
+	for _, root := range roots {
+		w.addDir(root)
+	}
+
+	return w, nil
+}
+
+// Close stops watching.
+func (w *Watcher) Close() error {
+	return w.fs.Close()
+}
+
+// Run passes modified files to handle, once no file event occurred for the
+// debounce period, until ctx is done. Each file is passed at most once per
+// burst, with the time of its last modification. Handle is called from the
+// watching goroutine.
+func (w *Watcher) Run(ctx context.Context, handle Handle) error {
+	var (
+		first   time.Time
+		index   = make(map[string]int)
+		maxWait = maxWaitFactor * w.debounce
+		pending []Event
+		timer   = time.NewTimer(w.debounce)
+	)
+
+	stopTimer(timer)
+
+	flush := func() {
+		if len(pending) == 0 {
+			return
+		}
+
+		events := pending
+
+		pending = nil
+		index = make(map[string]int)
+
+		handle(events)
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			flush()
+
+			return nil
+		case ev, ok := <-w.fs.Events:
+			if !ok {
+				flush()
+
+				return errors.New("file watcher closed")
+			}
+
+			e, ok := w.event(ev)
+			if !ok {
+				continue
+			}
+
+			if len(pending) == 0 {
+				first = e.Time
+			}
+
+			if i, ok := index[e.File]; ok {
+				pending[i].Time = e.Time
+			} else {
+				index[e.File] = len(pending)
+				pending = append(pending, e)
+			}
+
+			wait := w.debounce
+			if remaining := maxWait - time.Since(first); remaining < wait {
+				wait = remaining
+			}
+
+			stopTimer(timer)
+			timer.Reset(wait)
+		case err, ok := <-w.fs.Errors:
+			if !ok {
+				flush()
+
+				return errors.New("file watcher closed")
+			}
+
+			log.Warnf("file watcher error: %s", err)
+		case <-timer.C:
+			flush()
+		}
+	}
+}
+
+// event converts a file event into a modification. Returns false for events
+// not modifying a watched file. Newly created directories are watched.
+func (w *Watcher) event(ev fsnotify.Event) (Event, bool) {
+	if ev.Op&(fsnotify.Remove|fsnotify.Rename) != 0 {
+		delete(w.ignores, ev.Name)
+
+		return Event{}, false
+	}
+
+	if ev.Op&(fsnotify.Create|fsnotify.Write) == 0 {
+		return Event{}, false
+	}
+
+	info, err := os.Stat(ev.Name)
+	if err != nil {
+		// removed in the meantime
+		return Event{}, false
+	}
+
+	if info.IsDir() {
+		if ev.Op&fsnotify.Create != 0 && !w.isIgnored(ev.Name, true) {
+			w.addDir(ev.Name)
+		}
+
+		return Event{}, false
+	}
+
+	if filepath.Base(ev.Name) == gitignoreFile {
+		w.loadGitignore(filepath.Dir(ev.Name))
+	}
+
+	if !info.Mode().IsRegular() || w.isIgnored(ev.Name, false) {
+		return Event{}, false
+	}
+
+	return Event{
+		File: ev.Name,
+		Time: time.Now(),
+	}, true
+}
+
+// addDir watches dir and all its subdirectories, which are not ignored.
+func (w *Watcher) addDir(dir string) {
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			log.Warnf("failed to access %q: %s", p, err)
+
+			if d != nil && d.IsDir() && p != dir {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if !d.IsDir() {
+			return nil
+		}
+
+		if p != dir && w.isIgnored(p, true) {
+			return filepath.SkipDir
+		}
+
+		w.loadGitignore(p)
+
+		if err := w.fs.Add(p); err != nil {
+			log.Warnf("failed to watch directory %q: %s", p, err)
+		}
+
+		return nil
+	})
+	if err != nil {
+		log.Warnf("failed to watch directory %q: %s", dir, err)
+	}
+}
+
+// loadGitignore reads the .gitignore file of dir, if existing.
+func (w *Watcher) loadGitignore(dir string) {
+	f, err := os.Open(filepath.Join(dir, gitignoreFile))
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Warnf("failed to open gitignore file in %q: %s", dir, err)
+		}
+
+		delete(w.ignores, dir)
+
+		return
+	}
+
+	defer f.Close()
+
+	g, err := parseGitignore(f)
+	if err != nil {
+		log.Warnf("failed to parse gitignore file in %q: %s", dir, err)
+
+		return
+	}
+
+	w.ignores[dir] = g
+}
+
+// isIgnored returns true for version control directories and paths ignored by
+// .gitignore files of the watched directories. Rules of deeper .gitignore
+// files take precedence.
+func (w *Watcher) isIgnored(fp string, isDir bool) bool {
+	if isDir && vcsDirs[filepath.Base(fp)] {
+		return true
+	}
+
+	root := w.root(fp)
+	if root == "" {
+		return false
+	}
+
+	var (
+		dirs    []string
+		ignored bool
+	)
+
+	for dir := filepath.Dir(fp); ; dir = filepath.Dir(dir) {
+		dirs = append(dirs, dir)
+
+		if dir == root || dir == filepath.Dir(dir) {
+			break
+		}
+	}
+
+	for i := len(dirs) - 1; i >= 0; i-- {
+		g, ok := w.ignores[dirs[i]]
+		if !ok {
+			continue
+		}
+
+		rel, err := filepath.Rel(dirs[i], fp)
+		if err != nil {
+			continue
+		}
+
+		if matched, ignore := g.match(filepath.ToSlash(rel), isDir); matched {
+			ignored = ignore
+		}
+	}
+
+	return ignored
+}
+
+// root returns the watched directory containing fp.
+func (w *Watcher) root(fp string) string {
+	var root string
+
+	for _, r := range w.roots {
+		if fp != r && !strings.HasPrefix(fp, r+string(filepath.Separator)) {
+			continue
+		}
+
+		if len(r) > len(root) {
+			root = r
+		}
+	}
+
+	return root
+}
+
+func stopTimer(t *time.Timer) {
+	if !t.Stop() {
+		select {
+		case <-t.C:
+		default:
+		}
+	}
+}
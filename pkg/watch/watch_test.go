@@ -0,0 +1,91 @@
+package watch_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/wakatime/wakatime-cli/pkg/watch"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatcher_Run(t *testing.T) {
+	dir := t.TempDir()
+
+	err := os.WriteFile(filepath.Join(dir, ".gitignore"), []byte("*.tmp\nignored/\n"), 0600)
+	require.NoError(t, err)
+
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "ignored"), 0700))
+
+	w, err := watch.NewWatcher(watch.Config{
+		Debounce: 100 * time.Millisecond,
+		Dirs:     []string{dir},
+	})
+	require.NoError(t, err)
+
+	defer w.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	bursts := make(chan []watch.Event, 10)
+
+	go func() {
+		_ = w.Run(ctx, func(events []watch.Event) {
+			bursts <- events
+		})
+	}()
+
+	// new subdirectories are watched as well
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "sub"), 0700))
+
+	time.Sleep(50 * time.Millisecond)
+
+	for i := 0; i < 3; i++ {
+		writeFile(t, filepath.Join(dir, "main.go"))
+	}
+
+	writeFile(t, filepath.Join(dir, "sub", "main.py"))
+	writeFile(t, filepath.Join(dir, "cache.tmp"))
+	writeFile(t, filepath.Join(dir, "ignored", "main.go"))
+
+	var events []watch.Event
+
+	select {
+	case events = <-bursts:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for file events")
+	}
+
+	var files []string
+	for _, e := range events {
+		files = append(files, e.File)
+	}
+
+	assert.Equal(t, []string{
+		filepath.Join(dir, "main.go"),
+		filepath.Join(dir, "sub", "main.py"),
+	}, files)
+}
+
+func TestNewWatcher_NotADirectory(t *testing.T) {
+	fp := filepath.Join(t.TempDir(), "main.go")
+	writeFile(t, fp)
+
+	_, err := watch.NewWatcher(watch.Config{Dirs: []string{fp}})
+	assert.Error(t, err)
+}
+
+func TestNewWatcher_NoDirectories(t *testing.T) {
+	_, err := watch.NewWatcher(watch.Config{})
+	assert.EqualError(t, err, "no directories to watch")
+}
+
+func writeFile(t *testing.T, fp string) {
+	err := os.WriteFile(fp, []byte("package main\n"), 0600)
+	require.NoError(t, err)
+}